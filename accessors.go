@@ -0,0 +1,155 @@
+package vault
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// GetString returns the string value of the key in the secret data. It
+// returns "" and false if the key is missing or its value is not a string.
+func GetString(data map[string]interface{}, key string) (string, bool) {
+	s, ok := data[key].(string)
+	return s, ok
+}
+
+// GetInt returns the integer value of the key in the secret data. Integral
+// numbers, json.Numbers and numeric strings are converted. It returns 0 and
+// false if the key is missing or its value is not an integer.
+func GetInt(data map[string]interface{}, key string) (int, bool) {
+	var f float64
+	switch v := data[key].(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		f = v
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return int(n), true
+		}
+		n, err := v.Float64()
+		if err != nil {
+			return 0, false
+		}
+		f = n
+	case string:
+		if n, err := strconv.Atoi(v); err == nil {
+			return n, true
+		}
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, false
+		}
+		f = n
+	default:
+		return 0, false
+	}
+	if f != math.Trunc(f) || math.Abs(f) >= math.Ldexp(1, strconv.IntSize-1) {
+		return 0, false
+	}
+	return int(f), true
+}
+
+// GetFloat returns the floating-point value of the key in the secret data.
+// Numbers, json.Numbers and numeric strings are converted. It returns 0 and
+// false if the key is missing or its value is not a number.
+func GetFloat(data map[string]interface{}, key string) (float64, bool) {
+	switch v := data[key].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// GetBool returns the boolean value of the key in the secret data. Strings
+// accepted by strconv.ParseBool, such as "true" and "0", are converted. It
+// returns false and false if the key is missing or its value is not a
+// boolean.
+func GetBool(data map[string]interface{}, key string) (bool, bool) {
+	switch v := data[key].(type) {
+	case bool:
+		return v, true
+	case string:
+		b, err := strconv.ParseBool(v)
+		return b, err == nil
+	default:
+		return false, false
+	}
+}
+
+// GetBytes returns the base64-decoded value of the key in the secret data,
+// which must be a string in standard base64 encoding, as written by
+// EncodeBinary. An error is returned if the key is missing or its value is
+// not valid base64.
+func GetBytes(data map[string]interface{}, key string) ([]byte, error) {
+	v, ok := data[key]
+	if !ok {
+		return nil, fmt.Errorf("vault: secret key %q not found", key)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("vault: secret key %q is a %T, not a base64 string", key, v)
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("vault: decoding secret key %q: %w", key, err)
+	}
+	return b, nil
+}
+
+// EncodeBinary returns secret data with each value of data encoded as a
+// standard base64 string, so binary values such as private keys are stored
+// without corruption.
+func EncodeBinary(data map[string][]byte) map[string]interface{} {
+	out := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		out[k] = base64.StdEncoding.EncodeToString(v)
+	}
+	return out
+}
+
+// GetPath returns the value at the dotted path in the nested secret data, such
+// as "db.password" for the "password" key of the "db" object. A segment that
+// is a non-negative integer indexes into an array, such as "hosts.0" for the
+// first of the "hosts". It returns nil and false if a segment is missing, an
+// index is out of range, or a value on the path is not an object or array.
+func GetPath(data map[string]interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return nil, false
+	}
+	var v interface{} = data
+	for _, seg := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if v, ok = node[seg]; !ok {
+				return nil, false
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
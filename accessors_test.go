@@ -0,0 +1,112 @@
+package vault_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/mwalto7/vault"
+)
+
+func TestGetters(t *testing.T) {
+	data := map[string]interface{}{
+		"name":     "web",
+		"port":     json.Number("8080"),
+		"replicas": "3",
+		"ratio":    json.Number("0.5"),
+		"whole":    float64(2),
+		"enabled":  true,
+		"debug":    "false",
+		"list":     []interface{}{"a"},
+	}
+
+	if v, ok := vault.GetString(data, "name"); !ok || v != "web" {
+		t.Errorf("GetString(name): got %q, %t, want %q, true", v, ok, "web")
+	}
+	if v, ok := vault.GetString(data, "port"); ok {
+		t.Errorf("GetString(port): got %q, true, want false", v)
+	}
+	for key, want := range map[string]int{"port": 8080, "replicas": 3, "whole": 2} {
+		if v, ok := vault.GetInt(data, key); !ok || v != want {
+			t.Errorf("GetInt(%s): got %d, %t, want %d, true", key, v, ok, want)
+		}
+	}
+	for _, key := range []string{"ratio", "name", "missing", "list"} {
+		if v, ok := vault.GetInt(data, key); ok || v != 0 {
+			t.Errorf("GetInt(%s): got %d, %t, want 0, false", key, v, ok)
+		}
+	}
+	if v, ok := vault.GetFloat(data, "ratio"); !ok || v != 0.5 {
+		t.Errorf("GetFloat(ratio): got %v, %t, want 0.5, true", v, ok)
+	}
+	if v, ok := vault.GetBool(data, "enabled"); !ok || !v {
+		t.Errorf("GetBool(enabled): got %t, %t, want true, true", v, ok)
+	}
+	if v, ok := vault.GetBool(data, "debug"); !ok || v {
+		t.Errorf("GetBool(debug): got %t, %t, want false, true", v, ok)
+	}
+	if _, ok := vault.GetBool(data, "name"); ok {
+		t.Error("GetBool(name): got true, want false")
+	}
+}
+
+func TestGetBytes(t *testing.T) {
+	key := []byte{0x00, 0xff, 0xfe, 0x80, '\n'}
+	data := vault.EncodeBinary(map[string][]byte{"key": key})
+	data["text"] = "not base64!"
+	data["port"] = json.Number("8080")
+
+	b, err := vault.GetBytes(data, "key")
+	if err != nil {
+		t.Fatalf("GetBytes(key): err: got %v, want nil", err)
+	}
+	if !bytes.Equal(b, key) {
+		t.Fatalf("GetBytes(key): got %v, want %v", b, key)
+	}
+	for _, k := range []string{"text", "port", "missing"} {
+		if _, err := vault.GetBytes(data, k); err == nil {
+			t.Errorf("GetBytes(%s): err: got nil, want error", k)
+		}
+	}
+}
+
+func TestGetPath(t *testing.T) {
+	data := map[string]interface{}{
+		"db": map[string]interface{}{
+			"password": "hunter2",
+			"replicas": []interface{}{
+				map[string]interface{}{"host": "a"},
+				map[string]interface{}{"host": "b"},
+			},
+		},
+		"hosts": []interface{}{"x", "y"},
+		"port":  json.Number("5432"),
+	}
+	tt := []struct {
+		path string
+		want interface{}
+		ok   bool
+	}{
+		{path: "db.password", want: "hunter2", ok: true},
+		{path: "db.replicas.1.host", want: "b", ok: true},
+		{path: "hosts.0", want: "x", ok: true},
+		{path: "port", want: json.Number("5432"), ok: true},
+		{path: "hosts.2"},
+		{path: "hosts.-1"},
+		{path: "hosts.first"},
+		{path: "db.missing"},
+		{path: "port.value"},
+		{path: "db.password.length"},
+		{path: ""},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.path, func(t *testing.T) {
+			v, ok := vault.GetPath(data, tc.path)
+			if ok != tc.ok || !reflect.DeepEqual(v, tc.want) {
+				t.Fatalf("got %v, %t, want %v, %t", v, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// ErrCircuitOpen is returned without making the request when the circuit
+// breaker of a client is open, because Vault failed too many consecutive
+// requests.
+var ErrCircuitOpen = errors.New("vault: circuit breaker is open")
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed is the state of a breaker that lets every request through.
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen is the state of a breaker that fails every request with
+	// ErrCircuitOpen until its cooldown has passed.
+	CircuitOpen
+
+	// CircuitHalfOpen is the state of a breaker whose cooldown has passed and
+	// that lets a single probe request through. The breaker closes if the probe
+	// succeeds and opens again if it fails.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker stops requests to Vault after consecutive failures, so that
+// clients do not add to the load of a struggling Vault server. It opens after
+// the failure threshold is reached, failing requests with ErrCircuitOpen
+// without making them, and lets a probe request through once the cooldown
+// has passed. A CircuitBreaker is safe for concurrent use and can be shared
+// by the clients of the same Vault server, such as with the
+// WithCircuitBreaker option of the secrets engine clients.
+//
+// Requests fail if Vault cannot be reached, times out, or responds with a
+// server error or 429 Too Many Requests. Other errors, such as a missing
+// secret or a denied permission, show that Vault is serving requests, so they
+// count as successes.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker returns a closed CircuitBreaker that opens after
+// threshold consecutive failed requests, and probes Vault again after the
+// cooldown. A threshold less than one is treated as one.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &CircuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// State returns the current state of the breaker, for example to export as a
+// metric.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.cooldown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// allow reports whether a request may be made, and whether it is the probe of
+// a half-open breaker.
+func (b *CircuitBreaker) allow() (probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.cooldown {
+		b.state = CircuitHalfOpen
+	}
+	switch {
+	case b.state == CircuitClosed:
+		return false, nil
+	case b.state == CircuitHalfOpen && !b.probing:
+		b.probing = true
+		return true, nil
+	default:
+		return false, ErrCircuitOpen
+	}
+}
+
+// record records the result of a request.
+func (b *CircuitBreaker) record(probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if probe {
+		b.probing = false
+	}
+	if !isCircuitFailure(err) {
+		if probe || b.state == CircuitClosed {
+			b.state = CircuitClosed
+			b.failures = 0
+		}
+		return
+	}
+	b.failures++
+	if probe || b.failures >= b.threshold {
+		b.state = CircuitOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// isCircuitFailure reports whether err shows that Vault is failing to serve
+// requests.
+func isCircuitFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var respErr *api.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode >= http.StatusInternalServerError || respErr.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// CircuitBreakerClient returns a LogicalClient that makes the requests of
+// client through the breaker, failing them with ErrCircuitOpen while the
+// breaker is open. If b is nil, client is returned unchanged.
+func CircuitBreakerClient(client LogicalClient, b *CircuitBreaker) LogicalClient {
+	if b == nil {
+		return client
+	}
+	return &circuitBreakerClient{client: client, breaker: b}
+}
+
+type circuitBreakerClient struct {
+	client  LogicalClient
+	breaker *CircuitBreaker
+}
+
+func (c *circuitBreakerClient) Read(path string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.Read(path)
+	})
+}
+
+func (c *circuitBreakerClient) ReadWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.ReadWithContext(ctx, path)
+	})
+}
+
+func (c *circuitBreakerClient) ReadWithData(path string, data map[string][]string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.ReadWithData(path, data)
+	})
+}
+
+func (c *circuitBreakerClient) ReadWithDataWithContext(ctx context.Context, path string, data map[string][]string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.ReadWithDataWithContext(ctx, path, data)
+	})
+}
+
+func (c *circuitBreakerClient) List(path string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.List(path)
+	})
+}
+
+func (c *circuitBreakerClient) ListWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.ListWithContext(ctx, path)
+	})
+}
+
+func (c *circuitBreakerClient) Write(path string, data map[string]interface{}) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.Write(path, data)
+	})
+}
+
+func (c *circuitBreakerClient) WriteWithContext(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.WriteWithContext(ctx, path, data)
+	})
+}
+
+func (c *circuitBreakerClient) JSONMergePatch(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.JSONMergePatch(ctx, path, data)
+	})
+}
+
+func (c *circuitBreakerClient) Delete(path string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.Delete(path)
+	})
+}
+
+func (c *circuitBreakerClient) DeleteWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.DeleteWithContext(ctx, path)
+	})
+}
+
+func (c *circuitBreakerClient) DeleteWithData(path string, data map[string][]string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.DeleteWithData(path, data)
+	})
+}
+
+func (c *circuitBreakerClient) DeleteWithDataWithContext(ctx context.Context, path string, data map[string][]string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.DeleteWithDataWithContext(ctx, path, data)
+	})
+}
+
+func (c *circuitBreakerClient) Unwrap(wrappingToken string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.Unwrap(wrappingToken)
+	})
+}
+
+func (c *circuitBreakerClient) UnwrapWithContext(ctx context.Context, wrappingToken string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.UnwrapWithContext(ctx, wrappingToken)
+	})
+}
+
+// do runs fn if the breaker allows it and records its result.
+func (c *circuitBreakerClient) do(fn func() (*api.Secret, error)) (*api.Secret, error) {
+	probe, err := c.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := fn()
+	c.breaker.record(probe, err)
+	return secret, err
+}
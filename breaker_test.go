@@ -0,0 +1,89 @@
+package vault_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/vault/api"
+	"github.com/mwalto7/vault"
+	"github.com/mwalto7/vault/vaultmock"
+)
+
+func TestCircuitBreakerClient(t *testing.T) {
+	unavailable := &api.ResponseError{StatusCode: http.StatusServiceUnavailable}
+	notFound := &api.ResponseError{StatusCode: http.StatusNotFound}
+	want := &api.Secret{Data: map[string]interface{}{"foo": "bar"}}
+	const cooldown = 20 * time.Millisecond
+
+	t.Run("OpensAfterThreshold", func(t *testing.T) {
+		m := vaultmock.NewLogicalClient(gomock.NewController(t))
+		gomock.InOrder(
+			m.EXPECT().Read("secret/test").Return(nil, unavailable),
+			m.EXPECT().Read("secret/test").Return(nil, notFound),
+			m.EXPECT().Read("secret/test").Return(nil, unavailable).Times(2),
+			m.EXPECT().Read("secret/test").Return(want, nil),
+		)
+		b := vault.NewCircuitBreaker(2, cooldown)
+		c := vault.CircuitBreakerClient(m, b)
+
+		// A not found error resets the consecutive failures.
+		for i := 0; i < 3; i++ {
+			if _, err := c.Read("secret/test"); err == nil {
+				t.Fatalf("read %d: err: got nil, want error", i)
+			}
+			if got := b.State(); got != vault.CircuitClosed {
+				t.Fatalf("read %d: state: got %v, want %v", i, got, vault.CircuitClosed)
+			}
+		}
+		if _, err := c.Read("secret/test"); errors.Is(err, vault.ErrCircuitOpen) {
+			t.Fatalf("threshold read: err: got %v, want Vault error", err)
+		}
+		if got := b.State(); got != vault.CircuitOpen {
+			t.Fatalf("state: got %v, want %v", got, vault.CircuitOpen)
+		}
+		if _, err := c.Read("secret/test"); !errors.Is(err, vault.ErrCircuitOpen) {
+			t.Fatalf("open: err: got %v, want %v", err, vault.ErrCircuitOpen)
+		}
+
+		time.Sleep(cooldown + 5*time.Millisecond)
+		if got := b.State(); got != vault.CircuitHalfOpen {
+			t.Fatalf("after cooldown: state: got %v, want %v", got, vault.CircuitHalfOpen)
+		}
+		secret, err := c.Read("secret/test")
+		if err != nil || secret != want {
+			t.Fatalf("probe: got %v, %v, want %v, nil", secret, err, want)
+		}
+		if got := b.State(); got != vault.CircuitClosed {
+			t.Fatalf("after probe: state: got %v, want %v", got, vault.CircuitClosed)
+		}
+	})
+
+	t.Run("FailedProbeReopens", func(t *testing.T) {
+		m := vaultmock.NewLogicalClient(gomock.NewController(t))
+		m.EXPECT().WriteWithContext(gomock.Any(), "secret/test", nil).Return(nil, context.DeadlineExceeded).Times(2)
+		b := vault.NewCircuitBreaker(1, cooldown)
+		c := vault.CircuitBreakerClient(m, b)
+
+		if _, err := c.WriteWithContext(context.Background(), "secret/test", nil); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("err: got %v, want %v", err, context.DeadlineExceeded)
+		}
+		time.Sleep(cooldown + 5*time.Millisecond)
+		if _, err := c.WriteWithContext(context.Background(), "secret/test", nil); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("probe: err: got %v, want %v", err, context.DeadlineExceeded)
+		}
+		if got := b.State(); got != vault.CircuitOpen {
+			t.Fatalf("state: got %v, want %v", got, vault.CircuitOpen)
+		}
+	})
+
+	t.Run("NilBreaker", func(t *testing.T) {
+		m := vaultmock.NewLogicalClient(gomock.NewController(t))
+		if c := vault.CircuitBreakerClient(m, nil); c != m {
+			t.Fatalf("client: got %v, want the unwrapped client", c)
+		}
+	})
+}
@@ -0,0 +1,202 @@
+package vault
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// CachingClient is a LogicalClient that caches the results of reads and lists
+// made with the wrapped client in memory for a fixed TTL. It is safe for
+// concurrent use.
+//
+// Any write or delete made through the CachingClient purges the whole cache,
+// since a write to one path can change the results of reads at related paths,
+// such as the KVv2 metadata and list endpoints. Changes made to Vault by other
+// clients are visible only once the cached entries expire.
+//
+// To cache the reads of a secrets engine client, pass the CachingClient as its
+// logical client:
+//
+//    cache := vault.NewCachingClient(client.Logical(), time.Minute)
+//    c := kv.NewClient("/secret", kv.WithLogicalClient(cache))
+type CachingClient struct {
+	client LogicalClient
+	ttl    time.Duration
+
+	mu      sync.RWMutex
+	entries map[string]cacheEntry
+	gen     uint64 // incremented by Purge
+
+	hits   uint64
+	misses uint64
+}
+
+var _ LogicalClient = (*CachingClient)(nil)
+
+type cacheEntry struct {
+	secret  *api.Secret
+	expires time.Time
+}
+
+// CacheStats reports the number of reads served from and missing from the
+// cache of a CachingClient.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// NewCachingClient creates a CachingClient that caches the reads and lists
+// made with client for the given TTL.
+func NewCachingClient(client LogicalClient, ttl time.Duration) *CachingClient {
+	return &CachingClient{client: client, ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+// Purge removes all entries from the cache.
+func (c *CachingClient) Purge() {
+	c.mu.Lock()
+	c.entries = make(map[string]cacheEntry)
+	c.gen++
+	c.mu.Unlock()
+}
+
+// Stats returns the cache hit and miss counts.
+func (c *CachingClient) Stats() CacheStats {
+	return CacheStats{Hits: atomic.LoadUint64(&c.hits), Misses: atomic.LoadUint64(&c.misses)}
+}
+
+func (c *CachingClient) Read(path string) (*api.Secret, error) {
+	return c.ReadWithContext(context.Background(), path)
+}
+
+func (c *CachingClient) ReadWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.cached("read "+path, func() (*api.Secret, error) {
+		return c.client.ReadWithContext(ctx, path)
+	})
+}
+
+func (c *CachingClient) ReadWithData(path string, data map[string][]string) (*api.Secret, error) {
+	return c.ReadWithDataWithContext(context.Background(), path, data)
+}
+
+func (c *CachingClient) ReadWithDataWithContext(ctx context.Context, path string, data map[string][]string) (*api.Secret, error) {
+	return c.cached("read "+path+"?"+url.Values(data).Encode(), func() (*api.Secret, error) {
+		return c.client.ReadWithDataWithContext(ctx, path, data)
+	})
+}
+
+func (c *CachingClient) List(path string) (*api.Secret, error) {
+	return c.ListWithContext(context.Background(), path)
+}
+
+func (c *CachingClient) ListWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.cached("list "+path, func() (*api.Secret, error) {
+		return c.client.ListWithContext(ctx, path)
+	})
+}
+
+func (c *CachingClient) Write(path string, data map[string]interface{}) (*api.Secret, error) {
+	return c.WriteWithContext(context.Background(), path, data)
+}
+
+func (c *CachingClient) WriteWithContext(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error) {
+	defer c.Purge()
+	return c.client.WriteWithContext(ctx, path, data)
+}
+
+func (c *CachingClient) JSONMergePatch(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error) {
+	defer c.Purge()
+	return c.client.JSONMergePatch(ctx, path, data)
+}
+
+func (c *CachingClient) Delete(path string) (*api.Secret, error) {
+	return c.DeleteWithContext(context.Background(), path)
+}
+
+func (c *CachingClient) DeleteWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	defer c.Purge()
+	return c.client.DeleteWithContext(ctx, path)
+}
+
+func (c *CachingClient) DeleteWithData(path string, data map[string][]string) (*api.Secret, error) {
+	return c.DeleteWithDataWithContext(context.Background(), path, data)
+}
+
+func (c *CachingClient) DeleteWithDataWithContext(ctx context.Context, path string, data map[string][]string) (*api.Secret, error) {
+	defer c.Purge()
+	return c.client.DeleteWithDataWithContext(ctx, path, data)
+}
+
+// Unwrap is not cached, since wrapping tokens can be used only once.
+func (c *CachingClient) Unwrap(wrappingToken string) (*api.Secret, error) {
+	return c.client.Unwrap(wrappingToken)
+}
+
+// UnwrapWithContext is not cached, since wrapping tokens can be used only once.
+func (c *CachingClient) UnwrapWithContext(ctx context.Context, wrappingToken string) (*api.Secret, error) {
+	return c.client.UnwrapWithContext(ctx, wrappingToken)
+}
+
+// cached returns a copy of the cached secret for the key, or calls fn and
+// caches its result if there is no unexpired entry. Errors are not cached, and
+// neither are results of fn if the cache was purged while fn ran, since they
+// may predate the write that purged it.
+func (c *CachingClient) cached(key string, fn func() (*api.Secret, error)) (*api.Secret, error) {
+	now := time.Now()
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	gen := c.gen
+	c.mu.RUnlock()
+	if ok && now.Before(entry.expires) {
+		atomic.AddUint64(&c.hits, 1)
+		return copySecret(entry.secret), nil
+	}
+	atomic.AddUint64(&c.misses, 1)
+
+	secret, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if c.gen == gen {
+		c.entries[key] = cacheEntry{secret: copySecret(secret), expires: now.Add(c.ttl)}
+	}
+	c.mu.Unlock()
+	return secret, nil
+}
+
+// copySecret returns a copy of secret whose data can be modified without
+// changing the cached secret.
+func copySecret(secret *api.Secret) *api.Secret {
+	if secret == nil {
+		return nil
+	}
+	s := *secret
+	if secret.Data != nil {
+		s.Data = copyValue(secret.Data).(map[string]interface{})
+	}
+	return &s
+}
+
+func copyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			out[k] = copyValue(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			out[i] = copyValue(val)
+		}
+		return out
+	default:
+		return v
+	}
+}
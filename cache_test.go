@@ -0,0 +1,86 @@
+package vault_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/vault/api"
+	"github.com/mwalto7/vault"
+	"github.com/mwalto7/vault/vaultmock"
+)
+
+func TestCachingClient(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().ReadWithContext(gomock.Any(), "secret/foo").DoAndReturn(
+		func(ctx context.Context, path string) (*api.Secret, error) {
+			return &api.Secret{Data: map[string]interface{}{"foo": "bar"}}, nil
+		}).Times(2)
+	m.EXPECT().WriteWithContext(gomock.Any(), "secret/foo", gomock.Any()).Return(nil, nil)
+
+	c := vault.NewCachingClient(m, time.Minute)
+	for i := 0; i < 3; i++ {
+		s, err := c.Read("secret/foo")
+		if err != nil {
+			t.Fatalf("err: got %v, want nil", err)
+		}
+		if want := map[string]interface{}{"foo": "bar"}; !reflect.DeepEqual(s.Data, want) {
+			t.Fatalf("data: got %v, want %v", s.Data, want)
+		}
+		s.Data["foo"] = "modified"
+	}
+	if want := (vault.CacheStats{Hits: 2, Misses: 1}); c.Stats() != want {
+		t.Fatalf("stats: got %+v, want %+v", c.Stats(), want)
+	}
+
+	if _, err := c.Write("secret/foo", map[string]interface{}{"foo": "baz"}); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if _, err := c.Read("secret/foo"); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if want := (vault.CacheStats{Hits: 2, Misses: 2}); c.Stats() != want {
+		t.Fatalf("stats: got %+v, want %+v", c.Stats(), want)
+	}
+}
+
+func TestCachingClient_WriteDuringRead(t *testing.T) {
+	started := make(chan struct{})
+	written := make(chan struct{})
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	gomock.InOrder(
+		m.EXPECT().ReadWithContext(gomock.Any(), "secret/foo").DoAndReturn(
+			func(ctx context.Context, path string) (*api.Secret, error) {
+				close(started)
+				<-written
+				return &api.Secret{Data: map[string]interface{}{"foo": "old"}}, nil
+			}),
+		m.EXPECT().ReadWithContext(gomock.Any(), "secret/foo").Return(&api.Secret{Data: map[string]interface{}{"foo": "new"}}, nil),
+	)
+	m.EXPECT().WriteWithContext(gomock.Any(), "secret/foo", gomock.Any()).Return(nil, nil)
+
+	c := vault.NewCachingClient(m, time.Hour)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := c.Read("secret/foo"); err != nil {
+			t.Errorf("first read: err: got %v, want nil", err)
+		}
+	}()
+	<-started
+	if _, err := c.Write("secret/foo", map[string]interface{}{"foo": "new"}); err != nil {
+		t.Fatalf("write: err: got %v, want nil", err)
+	}
+	close(written)
+	<-done
+
+	secret, err := c.Read("secret/foo")
+	if err != nil {
+		t.Fatalf("second read: err: got %v, want nil", err)
+	}
+	if got := secret.Data["foo"]; got != "new" {
+		t.Fatalf("second read: got %v, want new", got)
+	}
+}
@@ -0,0 +1,89 @@
+package vault
+
+import (
+	"context"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// ClassifyingClient returns a LogicalClient whose request errors match
+// ErrPermissionDenied, ErrSealed or ErrUnreachable with errors.Is if they are
+// one of those failures. The errors still wrap the underlying error, such as
+// an *api.ResponseError, so errors.As keeps working.
+func ClassifyingClient(client LogicalClient) LogicalClient {
+	if _, ok := client.(*classifyingClient); ok {
+		return client
+	}
+	return &classifyingClient{client: client}
+}
+
+type classifyingClient struct {
+	client LogicalClient
+}
+
+func (c *classifyingClient) Read(path string) (*api.Secret, error) {
+	return classify(c.client.Read(path))
+}
+
+func (c *classifyingClient) ReadWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return classify(c.client.ReadWithContext(ctx, path))
+}
+
+func (c *classifyingClient) ReadWithData(path string, data map[string][]string) (*api.Secret, error) {
+	return classify(c.client.ReadWithData(path, data))
+}
+
+func (c *classifyingClient) ReadWithDataWithContext(ctx context.Context, path string, data map[string][]string) (*api.Secret, error) {
+	return classify(c.client.ReadWithDataWithContext(ctx, path, data))
+}
+
+func (c *classifyingClient) List(path string) (*api.Secret, error) {
+	return classify(c.client.List(path))
+}
+
+func (c *classifyingClient) ListWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return classify(c.client.ListWithContext(ctx, path))
+}
+
+func (c *classifyingClient) Write(path string, data map[string]interface{}) (*api.Secret, error) {
+	return classify(c.client.Write(path, data))
+}
+
+func (c *classifyingClient) WriteWithContext(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error) {
+	return classify(c.client.WriteWithContext(ctx, path, data))
+}
+
+func (c *classifyingClient) JSONMergePatch(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error) {
+	return classify(c.client.JSONMergePatch(ctx, path, data))
+}
+
+func (c *classifyingClient) Delete(path string) (*api.Secret, error) {
+	return classify(c.client.Delete(path))
+}
+
+func (c *classifyingClient) DeleteWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return classify(c.client.DeleteWithContext(ctx, path))
+}
+
+func (c *classifyingClient) DeleteWithData(path string, data map[string][]string) (*api.Secret, error) {
+	return classify(c.client.DeleteWithData(path, data))
+}
+
+func (c *classifyingClient) DeleteWithDataWithContext(ctx context.Context, path string, data map[string][]string) (*api.Secret, error) {
+	return classify(c.client.DeleteWithDataWithContext(ctx, path, data))
+}
+
+func (c *classifyingClient) Unwrap(wrappingToken string) (*api.Secret, error) {
+	return classify(c.client.Unwrap(wrappingToken))
+}
+
+func (c *classifyingClient) UnwrapWithContext(ctx context.Context, wrappingToken string) (*api.Secret, error) {
+	return classify(c.client.UnwrapWithContext(ctx, wrappingToken))
+}
+
+func classify(secret *api.Secret, err error) (*api.Secret, error) {
+	if err != nil {
+		return secret, classifyError(err)
+	}
+	return secret, nil
+}
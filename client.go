@@ -1,6 +1,10 @@
 package vault
 
-import "github.com/hashicorp/vault/api"
+import (
+	"context"
+
+	"github.com/hashicorp/vault/api"
+)
 
 //go:generate mockgen -destination=vaultmock/logical_client.go -package=vaultmock -mock_names=LogicalClient=LogicalClient github.com/mwalto7/vault LogicalClient
 
@@ -9,10 +13,18 @@
 // See https://github.com/hashicorp/vault/blob/master/api/logical.go#L41.
 type LogicalClient interface {
 	Read(path string) (*api.Secret, error)
+	ReadWithContext(ctx context.Context, path string) (*api.Secret, error)
 	ReadWithData(path string, data map[string][]string) (*api.Secret, error)
+	ReadWithDataWithContext(ctx context.Context, path string, data map[string][]string) (*api.Secret, error)
 	List(path string) (*api.Secret, error)
+	ListWithContext(ctx context.Context, path string) (*api.Secret, error)
 	Write(path string, data map[string]interface{}) (*api.Secret, error)
+	WriteWithContext(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error)
+	JSONMergePatch(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error)
 	Delete(path string) (*api.Secret, error)
+	DeleteWithContext(ctx context.Context, path string) (*api.Secret, error)
 	DeleteWithData(path string, data map[string][]string) (*api.Secret, error)
+	DeleteWithDataWithContext(ctx context.Context, path string, data map[string][]string) (*api.Secret, error)
 	Unwrap(wrappingToken string) (*api.Secret, error)
+	UnwrapWithContext(ctx context.Context, wrappingToken string) (*api.Secret, error)
 }
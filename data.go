@@ -0,0 +1,205 @@
+package vault
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// DecodeOption configures how secret data is decoded.
+type DecodeOption func(*mapstructure.DecoderConfig)
+
+// WeaklyTypedInput enables weak conversions while decoding secret data, such
+// as decoding the string "true" into a bool or "8080" into an int. Vault often
+// stores every value as a string.
+func WeaklyTypedInput() DecodeOption {
+	return func(cfg *mapstructure.DecoderConfig) {
+		cfg.WeaklyTypedInput = true
+	}
+}
+
+// DecodeData decodes secret data into out, which must be a pointer to a struct
+// or map. Struct fields are matched to keys using their "mapstructure" tags.
+// Decoding errors name the fields that failed to decode, but never include
+// their values.
+func DecodeData(data map[string]interface{}, out interface{}, opts ...DecodeOption) error {
+	cfg := &mapstructure.DecoderConfig{Result: out}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	dec, err := mapstructure.NewDecoder(cfg)
+	if err != nil {
+		return fmt.Errorf("vault: decoding secret data: %w", err)
+	}
+	if err := dec.Decode(data); err != nil {
+		return fmt.Errorf("vault: decoding secret data: %w", redactDecodeError(err))
+	}
+	return nil
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// EncodeData encodes in, which must be a struct or a map with string keys,
+// into secret data. Struct fields are named using their "mapstructure" tags,
+// and support the "omitempty" and "squash" tag options as well as "-" to skip
+// a field. Nested structs are encoded into nested maps.
+func EncodeData(in interface{}) (map[string]interface{}, error) {
+	v := reflect.ValueOf(in)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, errors.New("vault: encoding secret data: input is nil")
+		}
+		v = v.Elem()
+	}
+	switch {
+	case v.Kind() == reflect.Struct:
+		data := make(map[string]interface{})
+		encodeStruct(v, data)
+		return data, nil
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		return encodeValue(v).(map[string]interface{}), nil
+	default:
+		return nil, fmt.Errorf("vault: encoding secret data: expected a struct or map, got %s", v.Type())
+	}
+}
+
+func encodeStruct(v reflect.Value, data map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		tag := field.Tag.Get("mapstructure")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if i := strings.Index(tag, ","); i >= 0 {
+			name, opts = tag[:i], tag[i+1:]
+		}
+		fv := v.Field(i)
+		if hasTagOption(opts, "squash") {
+			for fv.Kind() == reflect.Ptr && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				encodeStruct(fv, data)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if hasTagOption(opts, "omitempty") && fv.IsZero() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		data[name] = encodeValue(fv)
+	}
+}
+
+func encodeValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Type().Implements(jsonMarshalerType) || v.Type().Implements(textMarshalerType) {
+		return v.Interface()
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return encodeValue(v.Elem())
+	case reflect.Struct:
+		data := make(map[string]interface{})
+		encodeStruct(v, data)
+		return data
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return v.Interface()
+		}
+		data := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			data[iter.Key().String()] = encodeValue(iter.Value())
+		}
+		return data
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && (v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8) {
+			return v.Interface()
+		}
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = encodeValue(v.Index(i))
+		}
+		return out
+	default:
+		return v.Interface()
+	}
+}
+
+func hasTagOption(opts, option string) bool {
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// EqualData reports whether the secret data a and b are equal as Vault stores
+// them, that is, whether they encode to the same JSON. Numbers are compared by
+// value regardless of their Go type, so an int equals the json.Number read
+// back from Vault, and nil and empty data are equal.
+func EqualData(a, b map[string]interface{}) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	av, err := jsonValue(a)
+	if err != nil {
+		return false
+	}
+	bv, err := jsonValue(b)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+// jsonValue returns v encoded to and decoded from JSON, with numbers decoded
+// as json.Number.
+func jsonValue(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var out interface{}
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CloneData returns a deep copy of the secret data, copying nested maps and
+// slices, so it can be modified without changing data.
+func CloneData(data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+	return copyValue(data).(map[string]interface{})
+}
@@ -0,0 +1,138 @@
+package vault_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/mwalto7/vault"
+)
+
+func TestEncodeData(t *testing.T) {
+	type TLS struct {
+		Cert string `mapstructure:"cert"`
+		Key  string `mapstructure:"key,omitempty"`
+	}
+	type Common struct {
+		Env string `mapstructure:"env"`
+	}
+	type config struct {
+		Common   `mapstructure:",squash"`
+		Host     string            `mapstructure:"host"`
+		Port     int               `mapstructure:"port,omitempty"`
+		Password string            `mapstructure:"-"`
+		TLS      TLS               `mapstructure:"tls"`
+		Backup   *TLS              `mapstructure:"backup,omitempty"`
+		Hosts    []TLS             `mapstructure:"hosts"`
+		Labels   map[string]string `mapstructure:"labels"`
+		internal string
+	}
+	tt := []struct {
+		name string
+		in   interface{}
+		want map[string]interface{}
+		err  bool
+	}{
+		{
+			name: "Struct",
+			in: &config{
+				Common:   Common{Env: "prod"},
+				Host:     "db",
+				Password: "secret",
+				TLS:      TLS{Cert: "cert"},
+				Hosts:    []TLS{{Cert: "a", Key: "b"}},
+				Labels:   map[string]string{"team": "a"},
+				internal: "x",
+			},
+			want: map[string]interface{}{
+				"env":    "prod",
+				"host":   "db",
+				"tls":    map[string]interface{}{"cert": "cert"},
+				"hosts":  []interface{}{map[string]interface{}{"cert": "a", "key": "b"}},
+				"labels": map[string]interface{}{"team": "a"},
+			},
+		},
+		{
+			name: "Map",
+			in:   map[string]TLS{"primary": {Cert: "c"}},
+			want: map[string]interface{}{"primary": map[string]interface{}{"cert": "c"}},
+		},
+		{
+			name: "ErrNotStruct",
+			in:   []string{"foo"},
+			err:  true,
+		},
+		{
+			name: "ErrNil",
+			in:   (*config)(nil),
+			err:  true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := vault.EncodeData(tc.in)
+			if (err != nil) != tc.err {
+				t.Fatalf("err: got %v, want error %t", err, tc.err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("data: got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEqualData(t *testing.T) {
+	stored := map[string]interface{}{
+		"port":  json.Number("5432"),
+		"hosts": []interface{}{"a", "b"},
+		"tls":   map[string]interface{}{"enabled": true},
+	}
+	tt := []struct {
+		name string
+		a, b map[string]interface{}
+		want bool
+	}{
+		{
+			name: "NumberTypes",
+			a:    stored,
+			b: map[string]interface{}{
+				"port":  5432,
+				"hosts": []string{"a", "b"},
+				"tls":   map[string]bool{"enabled": true},
+			},
+			want: true,
+		},
+		{name: "NilAndEmpty", a: nil, b: map[string]interface{}{}, want: true},
+		{name: "ChangedValue", a: stored, b: map[string]interface{}{"port": 5433, "hosts": []string{"a", "b"}, "tls": map[string]bool{"enabled": true}}},
+		{name: "MissingKey", a: stored, b: map[string]interface{}{"port": 5432}},
+		{name: "Empty", a: stored, b: nil},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := vault.EqualData(tc.a, tc.b); got != tc.want {
+				t.Fatalf("EqualData: got %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCloneData(t *testing.T) {
+	data := map[string]interface{}{
+		"hosts": []interface{}{"a", "b"},
+		"tls":   map[string]interface{}{"enabled": true},
+	}
+	clone := vault.CloneData(data)
+	clone["hosts"].([]interface{})[0] = "changed"
+	clone["tls"].(map[string]interface{})["enabled"] = false
+	clone["new"] = "key"
+
+	want := map[string]interface{}{
+		"hosts": []interface{}{"a", "b"},
+		"tls":   map[string]interface{}{"enabled": true},
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Fatalf("data: got %v, want it unchanged %v", data, want)
+	}
+}
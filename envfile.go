@@ -0,0 +1,221 @@
+package vault
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// WriteEnvFile writes the secret data to w as KEY='value' lines that can be
+// sourced by a shell. The data is flattened with FlattenSecret, and each key
+// is named by uppercasing it and replacing the characters other than letters,
+// digits and underscores with underscores, so "db.password" becomes
+// DB_PASSWORD. A name starting with a digit is prefixed with an underscore.
+// Values are single-quoted, so they are never expanded. The lines
+// are sorted by name.
+//
+// An error is returned if two keys have the same name, such as "db.password"
+// and "db_password".
+func WriteEnvFile(w io.Writer, data map[string]interface{}) error {
+	vars := make(map[string]string)
+	keys := make(map[string]string)
+	for k, v := range FlattenSecret(data) {
+		name := envName(k)
+		if other, ok := keys[name]; ok {
+			return fmt.Errorf("vault: writing env file: keys %q and %q are both named %s", other, k, name)
+		}
+		keys[name] = k
+		vars[name] = v
+	}
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	bw := bufio.NewWriter(w)
+	for _, name := range names {
+		fmt.Fprintf(bw, "%s=%s\n", name, shellQuote(vars[name]))
+	}
+	return bw.Flush()
+}
+
+// envName returns the environment variable name of the flattened key, whose
+// unescaped segments are joined with underscores.
+func envName(key string) string {
+	var b strings.Builder
+	for i, c := range strings.ToUpper(strings.Join(splitKey(key), "_")) {
+		switch {
+		case c >= 'A' && c <= 'Z', c == '_':
+			b.WriteRune(c)
+		case c >= '0' && c <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(c)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// shellQuote single-quotes s, closing the quotes around each single quote.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// ParseEnvFile parses the KEY=value lines of a .env file into secret data,
+// with a string value for each variable. Blank lines, comments starting with
+// "#" and the "export" prefix are ignored. Values may be single-quoted, with
+// no escapes, or double-quoted, with backslash escapes of ", \, $ and `.
+// Quoted values may span lines, and quoted and unquoted parts of a value are
+// concatenated as in a shell. Variables are not expanded.
+func ParseEnvFile(r io.Reader) (map[string]interface{}, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &envParser{src: string(b), line: 1}
+	data := make(map[string]interface{})
+	for {
+		name, value, ok, err := p.next()
+		if err != nil {
+			return nil, fmt.Errorf("vault: parsing env file: line %d: %w", p.line, err)
+		}
+		if !ok {
+			return data, nil
+		}
+		data[name] = value
+	}
+}
+
+type envParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+// next returns the next variable in the file, or false at the end of the
+// file.
+func (p *envParser) next() (name, value string, ok bool, err error) {
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return "", "", false, nil
+		}
+		switch p.src[p.pos] {
+		case '\n':
+			p.pos++
+			p.line++
+			continue
+		case '#':
+			p.skipComment()
+			continue
+		}
+		break
+	}
+	if strings.HasPrefix(p.src[p.pos:], "export ") || strings.HasPrefix(p.src[p.pos:], "export\t") {
+		p.pos += len("export")
+		p.skipSpace()
+	}
+	start := p.pos
+	for p.pos < len(p.src) && isNameChar(p.src[p.pos], p.pos == start) {
+		p.pos++
+	}
+	name = p.src[start:p.pos]
+	if name == "" {
+		return "", "", false, fmt.Errorf("invalid variable name")
+	}
+	if p.pos >= len(p.src) || p.src[p.pos] != '=' {
+		return "", "", false, fmt.Errorf("missing = after %s", name)
+	}
+	p.pos++
+	if value, err = p.value(); err != nil {
+		return "", "", false, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) && p.src[p.pos] == '#' {
+		p.skipComment()
+	}
+	if p.pos < len(p.src) && p.src[p.pos] != '\n' {
+		return "", "", false, fmt.Errorf("unexpected %q after the value of %s", p.src[p.pos], name)
+	}
+	return name, value, true, nil
+}
+
+// value parses a value up to the first unquoted whitespace.
+func (p *envParser) value() (string, error) {
+	var b strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			return b.String(), nil
+		case '\'':
+			end := strings.IndexByte(p.src[p.pos+1:], '\'')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated single quote")
+			}
+			s := p.src[p.pos+1 : p.pos+1+end]
+			p.line += strings.Count(s, "\n")
+			b.WriteString(s)
+			p.pos += end + 2
+		case '"':
+			if err := p.doubleQuoted(&b); err != nil {
+				return "", err
+			}
+		case '\\':
+			if p.pos+1 < len(p.src) {
+				p.pos++
+				b.WriteByte(p.src[p.pos])
+			}
+			p.pos++
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return b.String(), nil
+}
+
+func (p *envParser) doubleQuoted(b *strings.Builder) error {
+	for p.pos++; p.pos < len(p.src); p.pos++ {
+		switch c := p.src[p.pos]; c {
+		case '"':
+			p.pos++
+			return nil
+		case '\\':
+			if p.pos+1 < len(p.src) && strings.IndexByte("\"\\$`", p.src[p.pos+1]) >= 0 {
+				p.pos++
+				b.WriteByte(p.src[p.pos])
+				continue
+			}
+			b.WriteByte(c)
+		case '\n':
+			p.line++
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return fmt.Errorf("unterminated double quote")
+}
+
+func (p *envParser) skipSpace() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t' || p.src[p.pos] == '\r') {
+		p.pos++
+	}
+}
+
+func (p *envParser) skipComment() {
+	for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+		p.pos++
+	}
+}
+
+func isNameChar(c byte, first bool) bool {
+	return c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (!first && c >= '0' && c <= '9')
+}
@@ -0,0 +1,110 @@
+package vault_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mwalto7/vault"
+)
+
+func TestWriteEnvFile(t *testing.T) {
+	data := map[string]interface{}{
+		"db": map[string]interface{}{
+			"password": "it's $secret",
+			"hosts":    []interface{}{"a", "b"},
+		},
+		"api-key": "k",
+		"1st":     "x",
+	}
+	var buf bytes.Buffer
+	if err := vault.WriteEnvFile(&buf, data); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	want := "API_KEY='k'\n" +
+		"DB_HOSTS_0='a'\n" +
+		"DB_HOSTS_1='b'\n" +
+		`DB_PASSWORD='it'\''s $secret'` + "\n" +
+		"_1ST='x'\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("env file: got %q, want %q", got, want)
+	}
+
+	got, err := vault.ParseEnvFile(&buf)
+	if err != nil {
+		t.Fatalf("parse: got %v, want nil", err)
+	}
+	if got["DB_PASSWORD"] != "it's $secret" {
+		t.Fatalf("round trip: got %q, want %q", got["DB_PASSWORD"], "it's $secret")
+	}
+
+	err = vault.WriteEnvFile(&buf, map[string]interface{}{"a.b": "1", "a_b": "2"})
+	if err == nil {
+		t.Fatal("colliding names: got nil, want error")
+	}
+}
+
+func TestParseEnvFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "Syntax",
+			src: "# comment\n\n" +
+				"PLAIN=value\n" +
+				"export EXPORTED=1\n" +
+				"SINGLE='a \"b\" $c'\n" +
+				`DOUBLE="a \"b\" \$c \n"` + "\n" +
+				"MIXED=a'b c'\"d\"\n" +
+				"EMPTY=\n" +
+				"COMMENTED=x # trailing\n" +
+				"MULTI='line1\r\nline2'\r\n",
+			want: map[string]interface{}{
+				"PLAIN":     "value",
+				"EXPORTED":  "1",
+				"SINGLE":    `a "b" $c`,
+				"DOUBLE":    `a "b" $c \n`,
+				"MIXED":     "ab cd",
+				"EMPTY":     "",
+				"COMMENTED": "x",
+				"MULTI":     "line1\r\nline2",
+			},
+		},
+		{
+			name:    "MissingEquals",
+			src:     "KEY value\n",
+			wantErr: true,
+		},
+		{
+			name:    "InvalidName",
+			src:     "1KEY=value\n",
+			wantErr: true,
+		},
+		{
+			name:    "UnterminatedQuote",
+			src:     "KEY='value\n",
+			wantErr: true,
+		},
+		{
+			name:    "TrailingGarbage",
+			src:     "KEY='a' b\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := vault.ParseEnvFile(strings.NewReader(tt.src))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err: got %v, want error %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("data: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
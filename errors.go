@@ -0,0 +1,133 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// ErrSecretNotFound is returned when no secret data is stored at the secret
+// path. The secrets engine clients return it wrapped in an *os.PathError.
+var ErrSecretNotFound = errors.New("vault: secret not found")
+
+// ErrEmptyData is returned by the secrets engine clients configured to reject
+// empty writes when the data of a write is nil or empty.
+var ErrEmptyData = errors.New("vault: secret data is empty")
+
+var (
+	// ErrUnreachable is returned when the Vault server could not be reached,
+	// for example because the connection was refused or timed out.
+	ErrUnreachable = errors.New("vault: server unreachable")
+
+	// ErrPermissionDenied is returned when the Vault token is invalid or is not
+	// permitted to make the request.
+	ErrPermissionDenied = errors.New("vault: permission denied")
+
+	// ErrSealed is returned when the Vault server is sealed.
+	ErrSealed = errors.New("vault: server is sealed")
+)
+
+// IsPermissionDenied reports whether err is a Vault permission denied error,
+// either matching ErrPermissionDenied or wrapping an *api.ResponseError with
+// the 403 status code.
+func IsPermissionDenied(err error) bool {
+	return errors.Is(err, ErrPermissionDenied) || hasStatus(err, http.StatusForbidden)
+}
+
+// IsNotFound reports whether err is a Vault not found error, either matching
+// ErrSecretNotFound or wrapping an *api.ResponseError with the 404 status
+// code.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrSecretNotFound) || hasStatus(err, http.StatusNotFound)
+}
+
+func hasStatus(err error, code int) bool {
+	var respErr *api.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == code
+}
+
+// BatchError is returned by operations on multiple secret paths when the
+// operation fails for some of the paths.
+type BatchError struct {
+	// The error of every failed path, keyed by path.
+	Errors map[string]error
+
+	// The sorted paths the operation succeeded for, if the operation reports
+	// them.
+	Succeeded []string
+}
+
+// Paths returns the sorted paths the operation failed for.
+func (e *BatchError) Paths() []string {
+	paths := make([]string, 0, len(e.Errors))
+	for p := range e.Errors {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (e *BatchError) Error() string {
+	paths := e.Paths()
+	msgs := make([]string, len(paths))
+	for i, p := range paths {
+		msgs[i] = fmt.Sprintf("%s: %v", p, e.Errors[p])
+	}
+	return fmt.Sprintf("vault: %d path(s) failed: %s", len(paths), strings.Join(msgs, "; "))
+}
+
+// classifiedError is an error that matches a sentinel error of this package
+// with errors.Is, while still unwrapping to the underlying error.
+type classifiedError struct {
+	kind error
+	err  error
+}
+
+func (e *classifiedError) Error() string {
+	return e.kind.Error() + ": " + e.err.Error()
+}
+
+func (e *classifiedError) Unwrap() error { return e.err }
+
+func (e *classifiedError) Is(target error) bool { return target == e.kind }
+
+// classifyError wraps err with the sentinel error of its failure, if known.
+func classifyError(err error) error {
+	var classified *classifiedError
+	if errors.As(err, &classified) {
+		return err
+	}
+	var kind error
+	var respErr *api.ResponseError
+	var urlErr *url.Error
+	var netErr net.Error
+	switch {
+	case errors.As(err, &respErr) && respErr.StatusCode == http.StatusForbidden:
+		kind = ErrPermissionDenied
+	case errors.As(err, &respErr) && respErr.StatusCode == http.StatusServiceUnavailable && isSealed(respErr):
+		kind = ErrSealed
+	case errors.As(err, &respErr):
+		return err
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &urlErr), errors.As(err, &netErr):
+		kind = ErrUnreachable
+	default:
+		return err
+	}
+	return &classifiedError{kind: kind, err: err}
+}
+
+func isSealed(respErr *api.ResponseError) bool {
+	for _, msg := range respErr.Errors {
+		if strings.Contains(msg, "Vault is sealed") {
+			return true
+		}
+	}
+	return false
+}
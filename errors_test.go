@@ -0,0 +1,78 @@
+package vault_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/vault/api"
+	"github.com/mwalto7/vault"
+	"github.com/mwalto7/vault/vaultmock"
+)
+
+func TestIsPermissionDenied(t *testing.T) {
+	forbidden := &api.ResponseError{StatusCode: http.StatusForbidden, Errors: []string{"permission denied"}}
+	tt := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "Nil"},
+		{name: "Sentinel", err: fmt.Errorf("reading: %w", vault.ErrPermissionDenied), want: true},
+		{name: "ResponseError", err: &os.PathError{Op: "Read", Path: "secret/x", Err: forbidden}, want: true},
+		{name: "NotFound", err: &api.ResponseError{StatusCode: http.StatusNotFound}},
+		{name: "Other", err: errors.New("boom")},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := vault.IsPermissionDenied(tc.err); got != tc.want {
+				t.Fatalf("IsPermissionDenied(%v): got %t, want %t", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	tt := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "Nil"},
+		{name: "Sentinel", err: &os.PathError{Op: "ReadSecret", Path: "secret/x", Err: vault.ErrSecretNotFound}, want: true},
+		{name: "ResponseError", err: &api.ResponseError{StatusCode: http.StatusNotFound}, want: true},
+		{name: "Forbidden", err: &api.ResponseError{StatusCode: http.StatusForbidden}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := vault.IsNotFound(tc.err); got != tc.want {
+				t.Fatalf("IsNotFound(%v): got %t, want %t", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestClassifyingClient(t *testing.T) {
+	forbidden := &api.ResponseError{StatusCode: http.StatusForbidden, Errors: []string{"permission denied"}}
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Write("secret/x", gomock.Any()).Return(nil, forbidden)
+	m.EXPECT().Read("secret/x").Return(nil, nil)
+
+	client := vault.ClassifyingClient(vault.ClassifyingClient(m))
+	_, err := client.Write("secret/x", nil)
+	if !errors.Is(err, vault.ErrPermissionDenied) {
+		t.Fatalf("err: got %v, want %v", err, vault.ErrPermissionDenied)
+	}
+	var respErr *api.ResponseError
+	if !errors.As(err, &respErr) || respErr != forbidden {
+		t.Fatalf("err: got %v, want to wrap %v", err, forbidden)
+	}
+	if _, err := client.Read("secret/x"); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+}
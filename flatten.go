@@ -0,0 +1,137 @@
+package vault
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FlattenSecret returns the nested secret data as flat string key-values, for
+// systems that only understand flat configuration such as .env files. Nested
+// object keys are joined with dots, such as "db.password", and array elements
+// are keyed by their index, such as "hosts.0". Dots and backslashes in keys
+// are escaped with a backslash, so the key "example.com" of the "tls" object
+// becomes "tls.example\.com".
+//
+// Values are formatted as strings, with nil as the empty string. Empty
+// objects and arrays have no values, so they are dropped. UnflattenSecret
+// reverses the flattening, except that every value is then a string:
+//
+//    env := vault.FlattenSecret(secret.Data)
+//    data := vault.UnflattenSecret(env) // nested again, for a write
+func FlattenSecret(data map[string]interface{}) map[string]string {
+	flat := make(map[string]string)
+	for k, v := range data {
+		flatten(flat, escapeKey(k), v)
+	}
+	return flat
+}
+
+func flatten(flat map[string]string, key string, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			flatten(flat, key+"."+escapeKey(k), e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			flatten(flat, key+"."+strconv.Itoa(i), e)
+		}
+	case nil:
+		flat[key] = ""
+	case string:
+		flat[key] = v
+	default:
+		flat[key] = fmt.Sprint(v)
+	}
+}
+
+// UnflattenSecret returns the nested secret data of the flat string key-values
+// produced by FlattenSecret. Keys are split at unescaped dots into nested
+// objects, and an object whose keys are exactly the indexes 0 to n-1 becomes
+// an array. If a key is both a value and the prefix of other keys, such as
+// "db" and "db.password", the nested keys are kept and the value is dropped.
+func UnflattenSecret(flat map[string]string) map[string]interface{} {
+	keys := make([]string, 0, len(flat))
+	for k := range flat {
+		keys = append(keys, k)
+	}
+	// Shorter keys sort first, so a value is replaced by the nested keys it
+	// is the prefix of, whatever the iteration order of the map.
+	sort.Strings(keys)
+	root := make(map[string]interface{})
+	for _, k := range keys {
+		segs := splitKey(k)
+		node := root
+		for _, seg := range segs[:len(segs)-1] {
+			child, ok := node[seg].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				node[seg] = child
+			}
+			node = child
+		}
+		last := segs[len(segs)-1]
+		if _, ok := node[last].(map[string]interface{}); !ok {
+			node[last] = flat[k]
+		}
+	}
+	return arrays(root).(map[string]interface{})
+}
+
+// arrays returns v with the nested objects whose keys are exactly the indexes
+// 0 to n-1 converted to arrays. The top-level object is never converted.
+func arrays(v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	for k, e := range m {
+		m[k] = toArray(arrays(e))
+	}
+	return m
+}
+
+func toArray(v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok || len(m) == 0 {
+		return v
+	}
+	a := make([]interface{}, len(m))
+	for k, e := range m {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 0 || i >= len(m) || strconv.Itoa(i) != k {
+			return v
+		}
+		a[i] = e
+	}
+	return a
+}
+
+var keyEscaper = strings.NewReplacer(`\`, `\\`, `.`, `\.`)
+
+func escapeKey(k string) string {
+	return keyEscaper.Replace(k)
+}
+
+// splitKey splits the flat key at unescaped dots and unescapes the segments.
+func splitKey(k string) []string {
+	var (
+		segs []string
+		b    strings.Builder
+	)
+	for i := 0; i < len(k); i++ {
+		switch c := k[i]; {
+		case c == '\\' && i+1 < len(k):
+			i++
+			b.WriteByte(k[i])
+		case c == '.':
+			segs = append(segs, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return append(segs, b.String())
+}
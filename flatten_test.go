@@ -0,0 +1,88 @@
+package vault_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/mwalto7/vault"
+)
+
+func TestFlattenSecret(t *testing.T) {
+	data := map[string]interface{}{
+		"db": map[string]interface{}{
+			"password": "hunter2",
+			"port":     json.Number("5432"),
+			"replicas": []interface{}{
+				map[string]interface{}{"host": "a"},
+				map[string]interface{}{"host": "b"},
+			},
+		},
+		"tls":     map[string]interface{}{"example.com": "cert", `back\slash`: "x"},
+		"enabled": true,
+		"empty":   map[string]interface{}{},
+		"none":    nil,
+	}
+	flat := map[string]string{
+		"db.password":        "hunter2",
+		"db.port":            "5432",
+		"db.replicas.0.host": "a",
+		"db.replicas.1.host": "b",
+		`tls.example\.com`:   "cert",
+		`tls.back\\slash`:    "x",
+		"enabled":            "true",
+		"none":               "",
+	}
+	if got := vault.FlattenSecret(data); !reflect.DeepEqual(got, flat) {
+		t.Fatalf("FlattenSecret: got %v, want %v", got, flat)
+	}
+
+	want := map[string]interface{}{
+		"db": map[string]interface{}{
+			"password": "hunter2",
+			"port":     "5432",
+			"replicas": []interface{}{
+				map[string]interface{}{"host": "a"},
+				map[string]interface{}{"host": "b"},
+			},
+		},
+		"tls":     map[string]interface{}{"example.com": "cert", `back\slash`: "x"},
+		"enabled": "true",
+		"none":    "",
+	}
+	if got := vault.UnflattenSecret(flat); !reflect.DeepEqual(got, want) {
+		t.Fatalf("UnflattenSecret: got %v, want %v", got, want)
+	}
+}
+
+func TestUnflattenSecret(t *testing.T) {
+	tt := []struct {
+		name string
+		flat map[string]string
+		want map[string]interface{}
+	}{
+		{
+			name: "ValueAndPrefix",
+			flat: map[string]string{"db": "x", "db.password": "hunter2"},
+			want: map[string]interface{}{"db": map[string]interface{}{"password": "hunter2"}},
+		},
+		{
+			name: "SparseIndexes",
+			flat: map[string]string{"hosts.0": "a", "hosts.2": "c"},
+			want: map[string]interface{}{"hosts": map[string]interface{}{"0": "a", "2": "c"}},
+		},
+		{
+			name: "TopLevelIndexes",
+			flat: map[string]string{"0": "a"},
+			want: map[string]interface{}{"0": "a"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := vault.UnflattenSecret(tc.flat); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
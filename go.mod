@@ -3,21 +3,16 @@ module github.com/mwalto7/vault
 go 1.15
 
 require (
-	github.com/frankban/quicktest v1.11.0 // indirect
 	github.com/golang/mock v1.4.4
-	github.com/hashicorp/go-multierror v1.1.0 // indirect
-	github.com/hashicorp/go-rootcerts v1.0.2 // indirect
 	github.com/hashicorp/hcl v1.0.1-0.20191016231534-914dc3f8dd7c // indirect
-	github.com/hashicorp/vault/api v1.0.5-0.20190730042357-746c0b111519
-	github.com/kr/text v0.2.0 // indirect
-	github.com/mitchellh/mapstructure v1.3.3
+	github.com/hashicorp/vault/api v1.5.0
+	github.com/hashicorp/yamux v0.0.0-20181012175058-2f1d1f20f75d // indirect
+	github.com/mitchellh/mapstructure v1.4.2
 	github.com/niemeyer/pretty v0.0.0-20200227124842-a10e7caefd8e // indirect
-	github.com/pierrec/lz4 v2.5.2+incompatible // indirect
-	github.com/stretchr/testify v1.6.1 // indirect
-	golang.org/x/crypto v0.0.0-20200820211705-5c72a883971a // indirect
-	golang.org/x/net v0.0.0-20200904194848-62affa334b73 // indirect
-	golang.org/x/text v0.3.3 // indirect
+	go.opentelemetry.io/otel v1.0.0
+	go.opentelemetry.io/otel/sdk v1.0.0
+	go.opentelemetry.io/otel/trace v1.0.0
+	golang.org/x/sync v0.0.0-20201020160332-67f06af15bc9
 	golang.org/x/time v0.0.0-20200630173020-3af7569d3a1e // indirect
 	gopkg.in/check.v1 v1.0.0-20200902074654-038fdea0a05b // indirect
-	gopkg.in/square/go-jose.v2 v2.5.1 // indirect
 )
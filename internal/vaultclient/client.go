@@ -0,0 +1,194 @@
+package vaultclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/mwalto7/vault"
+	"github.com/mwalto7/vault/vaultotel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Client creates the Vault client of a secrets engine or sys client on first
+// use and applies the request decorators configured by the options of that
+// client. Its exported fields are set by those options before first use and
+// must not be changed afterwards. A Client may be shared by several clients
+// with the same configuration, such as the Clients returned by ForMount, so
+// they share a single Vault client.
+type Client struct {
+	Namespace      string
+	UserAgent      string
+	RequestID      func(ctx context.Context) string
+	Timeout        time.Duration
+	StandbyRetries int
+	StandbyBackoff time.Duration
+	Breaker        *vault.CircuitBreaker
+	Login          func() (string, error)
+	Observer       vault.Observer
+	Logger         vault.Logger
+	TracerProvider trace.TracerProvider
+
+	// LogicalClient, APIClient and HTTPClient are the clients set with the
+	// WithLogicalClient, WithAPIClient and WithHTTPClient options.
+	LogicalClient vault.LogicalClient
+	APIClient     *api.Client
+	HTTPClient    *http.Client
+
+	once      sync.Once
+	err       error
+	client    vault.LogicalClient
+	apiClient *api.Client
+	renewer   *vault.TokenRenewer
+
+	mu         sync.Mutex
+	httpClient *http.Client // owned HTTP client, set once it is created
+}
+
+var (
+	errRequestIDClient = errors.New("vault: request IDs cannot be set on a Vault client of the caller, wrap its HTTP transport with vault.RequestIDTransport")
+	errRequestIDSocket = errors.New("vault: request IDs are not supported with a unix socket Vault address")
+)
+
+// Logical returns the Vault client used to make requests to the mount, wrapped
+// with the request decorators. The mount is empty for requests that are not
+// made to a secrets engine. An error creating the client is returned by every
+// call.
+func (c *Client) Logical(mount string) (vault.LogicalClient, error) {
+	c.once.Do(c.init)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.Wrap(c.client, mount), nil
+}
+
+// API returns the Vault API client requests are made with, or the client set
+// with WithAPIClient if requests are made with a client set with
+// WithLogicalClient, which may be nil.
+func (c *Client) API() (*api.Client, error) {
+	c.once.Do(c.init)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.apiClient, nil
+}
+
+// Close closes the idle connections of the HTTP client requests are made
+// with, if it is owned by the client: the client set with WithHTTPClient or
+// the default HTTP client of a Vault client created by the Client. It does not
+// create the Vault client, so Close before the first request does nothing.
+func (c *Client) Close() {
+	c.mu.Lock()
+	hc := c.httpClient
+	c.mu.Unlock()
+	if hc != nil {
+		hc.CloseIdleConnections()
+	}
+}
+
+// init creates the Vault client from the API client set with WithAPIClient or
+// the default Vault API configuration, unless one was set with
+// WithLogicalClient. It is called once, so concurrent first requests share a
+// single client; an error creating the client is returned by every request.
+func (c *Client) init() {
+	if c.RequestID != nil && (c.LogicalClient != nil || c.APIClient != nil) {
+		c.err = errRequestIDClient
+		return
+	}
+	if c.LogicalClient != nil {
+		c.client = c.LogicalClient
+		c.apiClient = c.APIClient
+		return
+	}
+	client, err := c.newAPIClient()
+	if err != nil {
+		c.err = err
+		return
+	}
+	if c.Namespace != "" {
+		client.SetNamespace(c.Namespace)
+	}
+	if c.UserAgent != "" {
+		headers := client.Headers()
+		if headers == nil {
+			headers = make(http.Header)
+		}
+		headers.Set("User-Agent", c.UserAgent)
+		client.SetHeaders(headers)
+	}
+	c.apiClient = client
+	if c.Login != nil {
+		c.renewer = vault.NewTokenRenewer(client, c.Login)
+	}
+	c.client = client.Logical()
+}
+
+// newAPIClient returns the API client set with WithAPIClient, cloned if the
+// namespace or user agent needs to be set on it, or a new client from the
+// default Vault API configuration and the HTTP client set with WithHTTPClient.
+func (c *Client) newAPIClient() (*api.Client, error) {
+	if c.APIClient == nil {
+		cfg := api.DefaultConfig()
+		if c.HTTPClient != nil {
+			cfg.HttpClient = c.HTTPClient
+		}
+		c.mu.Lock()
+		c.httpClient = cfg.HttpClient
+		c.mu.Unlock()
+		if c.RequestID != nil {
+			if err := c.setRequestIDTransport(cfg); err != nil {
+				return nil, err
+			}
+		}
+		return api.NewClient(cfg)
+	}
+	if c.Namespace == "" && c.UserAgent == "" {
+		return c.APIClient, nil
+	}
+	client, err := c.APIClient.CloneWithHeaders()
+	if err != nil {
+		return nil, err
+	}
+	client.SetToken(c.APIClient.Token())
+	return client, nil
+}
+
+// setRequestIDTransport replaces the HTTP client of cfg with a copy whose
+// transport sets the request ID of each request. The API client only needs the
+// *http.Transport of the HTTP client to dial unix sockets, which are rejected,
+// and to print curl commands, which the Client never enables.
+func (c *Client) setRequestIDTransport(cfg *api.Config) error {
+	addr := cfg.Address
+	if cfg.AgentAddress != "" {
+		addr = cfg.AgentAddress
+	}
+	if strings.HasPrefix(addr, "unix://") {
+		return errRequestIDSocket
+	}
+	hc := *cfg.HttpClient
+	hc.Transport = vault.RequestIDTransport(hc.Transport, c.RequestID)
+	cfg.HttpClient = &hc
+	return nil
+}
+
+// Wrap applies the request timeout, circuit breaker, standby retries, token
+// renewal, error classification, logger, observer and tracing of the Client to
+// client, which makes requests to the mount. The mount is logged and traced
+// with each request, as the Client may be shared by the clients of several
+// mounts.
+func (c *Client) Wrap(client vault.LogicalClient, mount string) vault.LogicalClient {
+	client = vault.CircuitBreakerClient(vault.TimeoutClient(client, c.Timeout), c.Breaker)
+	client = vault.StandbyRetryClient(client, c.StandbyRetries, c.StandbyBackoff)
+	client = vault.TokenRenewingClient(client, c.renewer)
+	client = vault.ClassifyingClient(client)
+	client = vault.LoggedClient(client, mountLogger(c.Logger, mount))
+	client = vault.ObservedClient(client, c.Observer)
+	if c.TracerProvider == nil {
+		return client
+	}
+	return vaultotel.NewClient(client, vaultotel.WithTracerProvider(c.TracerProvider), vaultotel.WithMount(mount))
+}
@@ -0,0 +1,14 @@
+// Package vaultclient implements the parts of the secrets engine and sys
+// clients that are shared between them.
+package vaultclient
+
+import "os"
+
+// MountPath returns the mount path set by the environment variable env, or the
+// default mount path def if it is not set.
+func MountPath(env, def string) string {
+	if path := os.Getenv(env); path != "" {
+		return path
+	}
+	return def
+}
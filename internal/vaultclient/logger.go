@@ -0,0 +1,38 @@
+package vaultclient
+
+import (
+	"strings"
+
+	"github.com/mwalto7/vault"
+)
+
+// mountLogger returns a Logger that logs the messages of logger with the mount
+// field set to the mount path, or logger if it is nil or the mount is empty.
+func mountLogger(logger vault.Logger, mount string) vault.Logger {
+	mount = strings.Trim(mount, "/")
+	if logger == nil || mount == "" {
+		return logger
+	}
+	return &mountFieldLogger{logger: logger, mount: mount}
+}
+
+type mountFieldLogger struct {
+	logger vault.Logger
+	mount  string
+}
+
+func (l *mountFieldLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.logger.Debug(msg, l.fields(keysAndValues)...)
+}
+
+func (l *mountFieldLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.logger.Info(msg, l.fields(keysAndValues)...)
+}
+
+func (l *mountFieldLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.logger.Error(msg, l.fields(keysAndValues)...)
+}
+
+func (l *mountFieldLogger) fields(keysAndValues []interface{}) []interface{} {
+	return append([]interface{}{"mount", l.mount}, keysAndValues...)
+}
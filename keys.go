@@ -0,0 +1,37 @@
+package vault
+
+import (
+	"sort"
+	"strings"
+)
+
+// SplitKeys splits the keys of a secret list into the folders, which end with
+// a slash, and the leaves, which are secrets. The order of the keys is kept.
+func SplitKeys(keys []string) (folders, leaves []string) {
+	for _, k := range keys {
+		if strings.HasSuffix(k, "/") {
+			folders = append(folders, k)
+		} else {
+			leaves = append(leaves, k)
+		}
+	}
+	return folders, leaves
+}
+
+// SortKeys returns the keys of a secret list without duplicates, with the
+// folders first and then the leaves, each sorted lexically, so lists of the
+// same keys are always in the same order. The keys are not modified.
+func SortKeys(keys []string) []string {
+	seen := make(map[string]bool, len(keys))
+	unique := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if !seen[k] {
+			seen[k] = true
+			unique = append(unique, k)
+		}
+	}
+	folders, leaves := SplitKeys(unique)
+	sort.Strings(folders)
+	sort.Strings(leaves)
+	return append(folders, leaves...)
+}
@@ -0,0 +1,29 @@
+package vault_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mwalto7/vault"
+)
+
+func TestSplitKeys(t *testing.T) {
+	folders, leaves := vault.SplitKeys([]string{"b", "web/", "a", "db/"})
+	if want := []string{"web/", "db/"}; !reflect.DeepEqual(folders, want) {
+		t.Fatalf("folders: got %v, want %v", folders, want)
+	}
+	if want := []string{"b", "a"}; !reflect.DeepEqual(leaves, want) {
+		t.Fatalf("leaves: got %v, want %v", leaves, want)
+	}
+}
+
+func TestSortKeys(t *testing.T) {
+	keys := []string{"web/", "b", "a", "db/", "b", "web"}
+	got := vault.SortKeys(keys)
+	if want := []string{"db/", "web/", "a", "b", "web"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if want := []string{"web/", "b", "a", "db/", "b", "web"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("keys modified: got %v, want %v", keys, want)
+	}
+}
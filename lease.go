@@ -0,0 +1,44 @@
+package vault
+
+import (
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// Lease is the lease of a secret returned by Vault. Secrets of dynamic
+// secrets engines, such as database credentials, are only valid for the lease
+// duration unless the lease is renewed. KV secrets have no lease, so the
+// lease of a KV secret is usually zero; a KVv1 secret written with a "ttl" key
+// reports the TTL as its lease duration.
+//
+// See https://www.vaultproject.io/docs/concepts/lease.
+type Lease struct {
+	// ID is the lease ID, used to renew or revoke the lease.
+	ID string
+
+	// Duration is the time the secret is valid for from when it was read.
+	Duration time.Duration
+
+	// Renewable reports whether the lease can be renewed.
+	Renewable bool
+}
+
+// LeaseOf returns the lease of the Vault response. If secret is nil, the zero
+// Lease is returned.
+func LeaseOf(secret *api.Secret) Lease {
+	if secret == nil {
+		return Lease{}
+	}
+	return Lease{
+		ID:        secret.LeaseID,
+		Duration:  time.Duration(secret.LeaseDuration) * time.Second,
+		Renewable: secret.Renewable,
+	}
+}
+
+// IsZero reports whether the lease is the zero Lease, which is the lease of a
+// secret that has none.
+func (l Lease) IsZero() bool {
+	return l == Lease{}
+}
@@ -0,0 +1,156 @@
+package vault
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// Logger is a structured logger. The keysAndValues are alternating field
+// names and values.
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// NopLogger is a Logger that discards all messages.
+type NopLogger struct{}
+
+// Debug does nothing.
+func (NopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+
+// Info does nothing.
+func (NopLogger) Info(msg string, keysAndValues ...interface{}) {}
+
+// Error does nothing.
+func (NopLogger) Error(msg string, keysAndValues ...interface{}) {}
+
+// LoggedClient returns a LogicalClient that logs each request made with
+// client to logger. Successful requests are logged at the debug level and
+// failed requests at the error level, with the "op", "path", "version" (if
+// requested) and "duration" fields. Neither the request nor the response data
+// is logged. If logger is nil, client is returned unchanged.
+func LoggedClient(client LogicalClient, logger Logger) LogicalClient {
+	if logger == nil {
+		return client
+	}
+	return &loggedClient{client: client, logger: logger}
+}
+
+type loggedClient struct {
+	client LogicalClient
+	logger Logger
+}
+
+func (c *loggedClient) Read(path string) (*api.Secret, error) {
+	return c.do("Read", path, nil, func() (*api.Secret, error) {
+		return c.client.Read(path)
+	})
+}
+
+func (c *loggedClient) ReadWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.do("Read", path, nil, func() (*api.Secret, error) {
+		return c.client.ReadWithContext(ctx, path)
+	})
+}
+
+func (c *loggedClient) ReadWithData(path string, data map[string][]string) (*api.Secret, error) {
+	return c.do("Read", path, data, func() (*api.Secret, error) {
+		return c.client.ReadWithData(path, data)
+	})
+}
+
+func (c *loggedClient) ReadWithDataWithContext(ctx context.Context, path string, data map[string][]string) (*api.Secret, error) {
+	return c.do("Read", path, data, func() (*api.Secret, error) {
+		return c.client.ReadWithDataWithContext(ctx, path, data)
+	})
+}
+
+func (c *loggedClient) List(path string) (*api.Secret, error) {
+	return c.do("List", path, nil, func() (*api.Secret, error) {
+		return c.client.List(path)
+	})
+}
+
+func (c *loggedClient) ListWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.do("List", path, nil, func() (*api.Secret, error) {
+		return c.client.ListWithContext(ctx, path)
+	})
+}
+
+func (c *loggedClient) Write(path string, data map[string]interface{}) (*api.Secret, error) {
+	return c.do("Write", path, nil, func() (*api.Secret, error) {
+		return c.client.Write(path, data)
+	})
+}
+
+func (c *loggedClient) WriteWithContext(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error) {
+	return c.do("Write", path, nil, func() (*api.Secret, error) {
+		return c.client.WriteWithContext(ctx, path, data)
+	})
+}
+
+func (c *loggedClient) JSONMergePatch(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error) {
+	return c.do("JSONMergePatch", path, nil, func() (*api.Secret, error) {
+		return c.client.JSONMergePatch(ctx, path, data)
+	})
+}
+
+func (c *loggedClient) Delete(path string) (*api.Secret, error) {
+	return c.do("Delete", path, nil, func() (*api.Secret, error) {
+		return c.client.Delete(path)
+	})
+}
+
+func (c *loggedClient) DeleteWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.do("Delete", path, nil, func() (*api.Secret, error) {
+		return c.client.DeleteWithContext(ctx, path)
+	})
+}
+
+func (c *loggedClient) DeleteWithData(path string, data map[string][]string) (*api.Secret, error) {
+	return c.do("Delete", path, data, func() (*api.Secret, error) {
+		return c.client.DeleteWithData(path, data)
+	})
+}
+
+func (c *loggedClient) DeleteWithDataWithContext(ctx context.Context, path string, data map[string][]string) (*api.Secret, error) {
+	return c.do("Delete", path, data, func() (*api.Secret, error) {
+		return c.client.DeleteWithDataWithContext(ctx, path, data)
+	})
+}
+
+func (c *loggedClient) Unwrap(wrappingToken string) (*api.Secret, error) {
+	return c.do("Unwrap", unwrapPath, nil, func() (*api.Secret, error) {
+		return c.client.Unwrap(wrappingToken)
+	})
+}
+
+func (c *loggedClient) UnwrapWithContext(ctx context.Context, wrappingToken string) (*api.Secret, error) {
+	return c.do("Unwrap", unwrapPath, nil, func() (*api.Secret, error) {
+		return c.client.UnwrapWithContext(ctx, wrappingToken)
+	})
+}
+
+// do runs fn and logs the request. Of the request parameters, only the
+// versions are logged.
+func (c *loggedClient) do(op, path string, params map[string][]string, fn func() (*api.Secret, error)) (*api.Secret, error) {
+	start := time.Now()
+	secret, err := fn()
+	fields := []interface{}{"op", op, "path", path}
+	if v, ok := params["version"]; ok {
+		fields = append(fields, "version", strings.Join(v, ","))
+	} else if v, ok := params["versions"]; ok {
+		fields = append(fields, "version", strings.Join(v, ","))
+	}
+	fields = append(fields, "duration", time.Since(start))
+	if err != nil {
+		c.logger.Error("vault request failed", append(fields, "error", err)...)
+	} else {
+		c.logger.Debug("vault request", fields...)
+	}
+	return secret, err
+}
@@ -0,0 +1,30 @@
+//go:build go1.21
+// +build go1.21
+
+package vault
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogLogger returns a Logger that writes to the slog.Logger l.
+func SlogLogger(l *slog.Logger) Logger {
+	return slogLogger{l: l}
+}
+
+type slogLogger struct {
+	l *slog.Logger
+}
+
+func (s slogLogger) Debug(msg string, keysAndValues ...interface{}) {
+	s.l.Log(context.Background(), slog.LevelDebug, msg, keysAndValues...)
+}
+
+func (s slogLogger) Info(msg string, keysAndValues ...interface{}) {
+	s.l.Log(context.Background(), slog.LevelInfo, msg, keysAndValues...)
+}
+
+func (s slogLogger) Error(msg string, keysAndValues ...interface{}) {
+	s.l.Log(context.Background(), slog.LevelError, msg, keysAndValues...)
+}
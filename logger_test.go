@@ -0,0 +1,73 @@
+package vault_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/vault/api"
+	"github.com/mwalto7/vault"
+	kv "github.com/mwalto7/vault/secrets/kv/v2"
+	"github.com/mwalto7/vault/vaultmock"
+)
+
+type recordingLogger struct {
+	mu     sync.Mutex
+	fields []interface{}
+}
+
+func (l *recordingLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.fields = append(l.fields, msg)
+	l.fields = append(l.fields, keysAndValues...)
+}
+
+func (l *recordingLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.Debug(msg, keysAndValues...)
+}
+
+func (l *recordingLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.Debug(msg, keysAndValues...)
+}
+
+func TestLoggedClient(t *testing.T) {
+	data := map[string]interface{}{"username": "admin-user", "password": "hunter2"}
+	version := &api.Secret{Data: map[string]interface{}{"version": json.Number("1")}}
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Write("/secret/data/test", gomock.Any()).Return(version, nil)
+	m.EXPECT().JSONMergePatch(gomock.Any(), "/secret/data/test", gomock.Any()).Return(version, nil)
+	m.EXPECT().Write("/secret/data/a", gomock.Any()).Return(version, nil)
+	m.EXPECT().Write("/secret/data/b", gomock.Any()).Return(nil, errors.New("permission denied"))
+
+	logger := &recordingLogger{}
+	c := kv.NewClient("", kv.WithLogicalClient(vault.LoggedClient(m, logger)))
+	if _, err := c.WriteSecretLatest("test", data); err != nil {
+		t.Fatalf("write: err: got %v, want nil", err)
+	}
+	if _, err := c.PatchSecret("test", map[string]interface{}{"password": "s3cr3t"}); err != nil {
+		t.Fatalf("patch: err: got %v, want nil", err)
+	}
+	if err := c.WriteSecrets(map[string]map[string]interface{}{"a": data, "b": {"token": "s.abcdef"}}); err == nil {
+		t.Fatal("write secrets: err: got nil, want error")
+	}
+
+	for _, field := range logger.fields {
+		out := fmt.Sprintf("%v %#v", field, field)
+		for _, v := range []string{"admin-user", "hunter2", "s3cr3t", "s.abcdef"} {
+			if strings.Contains(out, v) {
+				t.Errorf("logged field %s contains secret value %q", out, v)
+			}
+		}
+	}
+	logged := fmt.Sprint(logger.fields...)
+	for _, want := range []string{"Write", "JSONMergePatch", "/secret/data/test", "/secret/data/a", "/secret/data/b", "permission denied"} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("logged fields: got %v, want %q", logger.fields, want)
+		}
+	}
+}
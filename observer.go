@@ -0,0 +1,167 @@
+package vault
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// Observer is notified before and after each request made to Vault, for
+// example to record request latency and error metrics.
+//
+// The op is the LogicalClient operation, one of "Read", "List", "Write",
+// "JSONMergePatch", "Delete" or "Unwrap", and path is the Vault API path of
+// the request. A panic in an Observer method is recovered and does not affect
+// the request.
+//
+// The dur passed to AfterRequest is the wall-clock latency of the request to
+// Vault, including any standby retries and token renewal, but not the decoding
+// of the response by the secrets engine clients.
+type Observer interface {
+	BeforeRequest(op, path string)
+	AfterRequest(op, path string, err error, dur time.Duration)
+}
+
+// NopObserver is an Observer that does nothing.
+type NopObserver struct{}
+
+// BeforeRequest does nothing.
+func (NopObserver) BeforeRequest(op, path string) {}
+
+// AfterRequest does nothing.
+func (NopObserver) AfterRequest(op, path string, err error, dur time.Duration) {}
+
+// LatencyObserver is an Observer that calls the function after each request
+// with its latency, for example to record a request duration histogram.
+type LatencyObserver func(op, path string, err error, dur time.Duration)
+
+// BeforeRequest does nothing.
+func (f LatencyObserver) BeforeRequest(op, path string) {}
+
+// AfterRequest calls f(op, path, err, dur).
+func (f LatencyObserver) AfterRequest(op, path string, err error, dur time.Duration) {
+	f(op, path, err, dur)
+}
+
+// ObservedClient returns a LogicalClient that notifies obs around each request
+// made with client. If obs is nil, client is returned unchanged.
+func ObservedClient(client LogicalClient, obs Observer) LogicalClient {
+	if obs == nil {
+		return client
+	}
+	return &observedClient{client: client, obs: obs}
+}
+
+type observedClient struct {
+	client LogicalClient
+	obs    Observer
+}
+
+func (c *observedClient) Read(path string) (*api.Secret, error) {
+	return c.do("Read", path, func() (*api.Secret, error) {
+		return c.client.Read(path)
+	})
+}
+
+func (c *observedClient) ReadWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.do("Read", path, func() (*api.Secret, error) {
+		return c.client.ReadWithContext(ctx, path)
+	})
+}
+
+func (c *observedClient) ReadWithData(path string, data map[string][]string) (*api.Secret, error) {
+	return c.do("Read", path, func() (*api.Secret, error) {
+		return c.client.ReadWithData(path, data)
+	})
+}
+
+func (c *observedClient) ReadWithDataWithContext(ctx context.Context, path string, data map[string][]string) (*api.Secret, error) {
+	return c.do("Read", path, func() (*api.Secret, error) {
+		return c.client.ReadWithDataWithContext(ctx, path, data)
+	})
+}
+
+func (c *observedClient) List(path string) (*api.Secret, error) {
+	return c.do("List", path, func() (*api.Secret, error) {
+		return c.client.List(path)
+	})
+}
+
+func (c *observedClient) ListWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.do("List", path, func() (*api.Secret, error) {
+		return c.client.ListWithContext(ctx, path)
+	})
+}
+
+func (c *observedClient) Write(path string, data map[string]interface{}) (*api.Secret, error) {
+	return c.do("Write", path, func() (*api.Secret, error) {
+		return c.client.Write(path, data)
+	})
+}
+
+func (c *observedClient) WriteWithContext(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error) {
+	return c.do("Write", path, func() (*api.Secret, error) {
+		return c.client.WriteWithContext(ctx, path, data)
+	})
+}
+
+func (c *observedClient) JSONMergePatch(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error) {
+	return c.do("JSONMergePatch", path, func() (*api.Secret, error) {
+		return c.client.JSONMergePatch(ctx, path, data)
+	})
+}
+
+func (c *observedClient) Delete(path string) (*api.Secret, error) {
+	return c.do("Delete", path, func() (*api.Secret, error) {
+		return c.client.Delete(path)
+	})
+}
+
+func (c *observedClient) DeleteWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.do("Delete", path, func() (*api.Secret, error) {
+		return c.client.DeleteWithContext(ctx, path)
+	})
+}
+
+func (c *observedClient) DeleteWithData(path string, data map[string][]string) (*api.Secret, error) {
+	return c.do("Delete", path, func() (*api.Secret, error) {
+		return c.client.DeleteWithData(path, data)
+	})
+}
+
+func (c *observedClient) DeleteWithDataWithContext(ctx context.Context, path string, data map[string][]string) (*api.Secret, error) {
+	return c.do("Delete", path, func() (*api.Secret, error) {
+		return c.client.DeleteWithDataWithContext(ctx, path, data)
+	})
+}
+
+func (c *observedClient) Unwrap(wrappingToken string) (*api.Secret, error) {
+	return c.do("Unwrap", unwrapPath, func() (*api.Secret, error) {
+		return c.client.Unwrap(wrappingToken)
+	})
+}
+
+func (c *observedClient) UnwrapWithContext(ctx context.Context, wrappingToken string) (*api.Secret, error) {
+	return c.do("Unwrap", unwrapPath, func() (*api.Secret, error) {
+		return c.client.UnwrapWithContext(ctx, wrappingToken)
+	})
+}
+
+func (c *observedClient) do(op, path string, fn func() (*api.Secret, error)) (*api.Secret, error) {
+	c.before(op, path)
+	start := time.Now()
+	secret, err := fn()
+	c.after(op, path, err, time.Since(start))
+	return secret, err
+}
+
+func (c *observedClient) before(op, path string) {
+	defer func() { _ = recover() }()
+	c.obs.BeforeRequest(op, path)
+}
+
+func (c *observedClient) after(op, path string, err error, dur time.Duration) {
+	defer func() { _ = recover() }()
+	c.obs.AfterRequest(op, path, err, dur)
+}
@@ -0,0 +1,14 @@
+package vault
+
+import "os"
+
+// Ping reads the path with client to check that the Vault server is reachable
+// and unsealed, and that the client token is permitted to read the path. The
+// returned error wraps ErrUnreachable, ErrPermissionDenied or ErrSealed if it
+// is one of those failures, as well as the underlying error.
+func Ping(client LogicalClient, path string) error {
+	if _, err := client.Read(path); err != nil {
+		return &os.PathError{Op: "Ping", Path: path, Err: classifyError(err)}
+	}
+	return nil
+}
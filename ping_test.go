@@ -0,0 +1,61 @@
+package vault_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/vault/api"
+	"github.com/mwalto7/vault"
+	"github.com/mwalto7/vault/vaultmock"
+)
+
+func TestPing(t *testing.T) {
+	refused := &url.Error{Op: "Get", URL: "http://127.0.0.1:8200/v1/secret/config", Err: errors.New("connection refused")}
+	tt := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "OK"},
+		{name: "Unreachable", err: refused, want: vault.ErrUnreachable},
+		{name: "Timeout", err: context.DeadlineExceeded, want: vault.ErrUnreachable},
+		{
+			name: "PermissionDenied",
+			err:  &api.ResponseError{StatusCode: http.StatusForbidden, Errors: []string{"permission denied"}},
+			want: vault.ErrPermissionDenied,
+		},
+		{
+			name: "Sealed",
+			err:  &api.ResponseError{StatusCode: http.StatusServiceUnavailable, Errors: []string{"Vault is sealed"}},
+			want: vault.ErrSealed,
+		},
+		{
+			name: "Other",
+			err:  &api.ResponseError{StatusCode: http.StatusInternalServerError},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().Read("secret/config").Return(nil, tc.err)
+
+			err := vault.Ping(m, "secret/config")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("err: got %v, want %v", err, tc.err)
+			}
+			if tc.want != nil && !errors.Is(err, tc.want) {
+				t.Fatalf("err: got %v, want %v", err, tc.want)
+			}
+			for _, sentinel := range []error{vault.ErrUnreachable, vault.ErrPermissionDenied, vault.ErrSealed} {
+				if sentinel != tc.want && errors.Is(err, sentinel) {
+					t.Fatalf("err: got %v, want not %v", err, sentinel)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package vault
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PolicyRule grants capabilities on a Vault API path, as the path stanza of a
+// Vault policy.
+//
+// See https://www.vaultproject.io/docs/concepts/policies.
+type PolicyRule struct {
+	// The Vault API path, which may end with a "*" glob or contain "+"
+	// wildcard segments.
+	Path string
+
+	// The capabilities granted on the path, such as "read" and "list".
+	Capabilities []string
+}
+
+// FormatPolicy formats the rules as the path stanzas of a Vault policy in
+// HCL, in the order of the rules:
+//
+//    path "secret/data/apps/db" {
+//      capabilities = ["read"]
+//    }
+//
+// Rules without capabilities are omitted.
+func FormatPolicy(rules ...PolicyRule) string {
+	var b strings.Builder
+	for _, r := range rules {
+		if len(r.Capabilities) == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		caps := make([]string, len(r.Capabilities))
+		for i, c := range r.Capabilities {
+			caps[i] = strconv.Quote(c)
+		}
+		b.WriteString("path " + strconv.Quote(r.Path) + " {\n")
+		b.WriteString("  capabilities = [" + strings.Join(caps, ", ") + "]\n")
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,29 @@
+package vault_test
+
+import (
+	"testing"
+
+	"github.com/mwalto7/vault"
+)
+
+func TestFormatPolicy(t *testing.T) {
+	got := vault.FormatPolicy(
+		vault.PolicyRule{Path: "secret/data/apps/*", Capabilities: []string{"read", "update"}},
+		vault.PolicyRule{Path: "secret/metadata/apps/*"},
+		vault.PolicyRule{Path: `secret/data/"quoted"`, Capabilities: []string{"deny"}},
+	)
+	want := `path "secret/data/apps/*" {
+  capabilities = ["read", "update"]
+}
+
+path "secret/data/\"quoted\"" {
+  capabilities = ["deny"]
+}
+`
+	if got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if got := vault.FormatPolicy(); got != "" {
+		t.Fatalf("no rules: got %q, want empty", got)
+	}
+}
@@ -0,0 +1,108 @@
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// RedactedValue is the placeholder that replaces values redacted by
+// RedactData, unless another is set with RedactPlaceholder.
+const RedactedValue = "***"
+
+// RedactOption configures how RedactData redacts secret data.
+type RedactOption func(*redactConfig)
+
+type redactConfig struct {
+	placeholder  string
+	revealLength bool
+}
+
+// RedactPlaceholder sets the placeholder that replaces redacted values.
+// Defaults to RedactedValue.
+func RedactPlaceholder(placeholder string) RedactOption {
+	return func(cfg *redactConfig) {
+		cfg.placeholder = placeholder
+	}
+}
+
+// RevealLength appends the length of redacted string values to the
+// placeholder, e.g. "***(12)".
+func RevealLength() RedactOption {
+	return func(cfg *redactConfig) {
+		cfg.revealLength = true
+	}
+}
+
+// RedactData returns a copy of the secret data with every leaf value replaced
+// by RedactedValue, so it is safe to log. Nested maps and slices are redacted
+// recursively, preserving the keys and structure of the secret.
+func RedactData(data map[string]interface{}, opts ...RedactOption) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+	cfg := redactConfig{placeholder: RedactedValue}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return redactValue(data, cfg).(map[string]interface{})
+}
+
+func redactValue(v interface{}, cfg redactConfig) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			out[k] = redactValue(val, cfg)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			out[i] = redactValue(val, cfg)
+		}
+		return out
+	case string:
+		if cfg.revealLength {
+			return fmt.Sprintf("%s(%d)", cfg.placeholder, len(v))
+		}
+	}
+	return cfg.placeholder
+}
+
+// redactDecodeError returns err with the secret values that mapstructure
+// includes in its messages removed, keeping only the names of the fields that
+// failed to decode.
+func redactDecodeError(err error) error {
+	var msgs []string
+	var mErr *mapstructure.Error
+	if errors.As(err, &mErr) {
+		msgs = mErr.Errors
+	} else {
+		msgs = []string{err.Error()}
+	}
+	redacted := make([]string, len(msgs))
+	for i, msg := range msgs {
+		redacted[i] = redactDecodeMessage(msg)
+	}
+	return errors.New(strings.Join(redacted, "; "))
+}
+
+// redactDecodeMessage returns a message naming the field from the first
+// quoted name in msg, which is where mapstructure puts the field name.
+func redactDecodeMessage(msg string) string {
+	if strings.Contains(msg, "has invalid keys: ") {
+		return msg
+	}
+	start := strings.IndexByte(msg, '\'')
+	if start < 0 {
+		return "invalid value"
+	}
+	end := strings.IndexByte(msg[start+1:], '\'')
+	if end <= 0 {
+		return "invalid value"
+	}
+	return "invalid value for field " + msg[start:start+end+2]
+}
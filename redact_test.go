@@ -0,0 +1,94 @@
+package vault_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mwalto7/vault"
+)
+
+func TestRedactData(t *testing.T) {
+	data := map[string]interface{}{
+		"password": "hunter2",
+		"port":     8443,
+		"enabled":  true,
+		"db": map[string]interface{}{
+			"user": "admin-user",
+			"tls": map[string]interface{}{
+				"key": "private-key-pem",
+			},
+		},
+		"hosts": []interface{}{"host-a", map[string]interface{}{"token": "s.abcdef"}},
+		"empty": nil,
+	}
+	want := map[string]interface{}{
+		"password": "***",
+		"port":     "***",
+		"enabled":  "***",
+		"db": map[string]interface{}{
+			"user": "***",
+			"tls": map[string]interface{}{
+				"key": "***",
+			},
+		},
+		"hosts": []interface{}{"***", map[string]interface{}{"token": "***"}},
+		"empty": "***",
+	}
+	got := vault.RedactData(data)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("RedactData() = %v, want %v", got, want)
+	}
+	out := fmt.Sprintf("%v %#v", got, got)
+	for _, v := range []string{"hunter2", "8443", "true", "admin-user", "private-key-pem", "host-a", "s.abcdef"} {
+		if strings.Contains(out, v) {
+			t.Errorf("RedactData() output %q contains original value %q", out, v)
+		}
+	}
+	if data["password"] != "hunter2" {
+		t.Errorf("RedactData() modified its input")
+	}
+	if got := vault.RedactData(nil); got != nil {
+		t.Errorf("RedactData(nil) = %v, want nil", got)
+	}
+}
+
+func TestRedactData_Options(t *testing.T) {
+	data := map[string]interface{}{
+		"password": "hunter2",
+		"port":     8443,
+		"hosts":    []interface{}{"host-a"},
+	}
+	want := map[string]interface{}{
+		"password": "x(7)",
+		"port":     "x",
+		"hosts":    []interface{}{"x(6)"},
+	}
+	got := vault.RedactData(data, vault.RedactPlaceholder("x"), vault.RevealLength())
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("RedactData() = %v, want %v", got, want)
+	}
+}
+
+func TestDecodeData_RedactsErrors(t *testing.T) {
+	var out struct {
+		Port int  `mapstructure:"port"`
+		Flag bool `mapstructure:"flag"`
+	}
+	data := map[string]interface{}{"port": "hunter2", "flag": "s3cr3t"}
+	err := vault.DecodeData(data, &out, vault.WeaklyTypedInput())
+	if err == nil {
+		t.Fatal("DecodeData() error = nil, want error")
+	}
+	for _, v := range []string{"hunter2", "s3cr3t"} {
+		if strings.Contains(err.Error(), v) {
+			t.Errorf("DecodeData() error %q contains secret value %q", err, v)
+		}
+	}
+	for _, name := range []string{"'port'", "'flag'"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("DecodeData() error %q does not name field %s", err, name)
+		}
+	}
+}
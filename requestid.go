@@ -0,0 +1,45 @@
+package vault
+
+import (
+	"context"
+	"net/http"
+)
+
+// RequestIDHeader is the header RequestIDTransport sets to the request ID.
+const RequestIDHeader = "X-Request-Id"
+
+// RequestIDTransport returns an http.RoundTripper that sets the X-Request-Id
+// header of each request made with base to the ID returned by fn for the
+// context of the request, so a request can be correlated across the logs of a
+// gateway in front of Vault and the Vault audit log. fn is called for every
+// request, including retries. If fn returns the empty string, the header is
+// not set. If base is nil, http.DefaultTransport is used.
+func RequestIDTransport(base http.RoundTripper, fn func(ctx context.Context) string) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &requestIDTransport{base: base, fn: fn}
+}
+
+type requestIDTransport struct {
+	base http.RoundTripper
+	fn   func(ctx context.Context) string
+}
+
+func (t *requestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if id := t.fn(req.Context()); id != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(RequestIDHeader, id)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// CloseIdleConnections closes the idle connections of the base transport.
+func (t *requestIDTransport) CloseIdleConnections() {
+	type closeIdler interface {
+		CloseIdleConnections()
+	}
+	if base, ok := t.base.(closeIdler); ok {
+		base.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,43 @@
+package vault_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/mwalto7/vault"
+)
+
+type requestIDKey struct{}
+
+func TestRequestIDTransport(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get(vault.RequestIDHeader))
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: vault.RequestIDTransport(nil, func(ctx context.Context) string {
+		id, _ := ctx.Value(requestIDKey{}).(string)
+		return id
+	})}
+	for _, id := range []string{"a", "b", ""} {
+		req, err := http.NewRequestWithContext(context.WithValue(context.Background(), requestIDKey{}, id), http.MethodGet, srv.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("err: got %v, want nil", err)
+		}
+		resp.Body.Close()
+		if req.Header.Get(vault.RequestIDHeader) != "" {
+			t.Fatalf("request %q: header set on the caller's request", id)
+		}
+	}
+	if want := []string{"a", "b", ""}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("request IDs: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,105 @@
+package cubbyhole
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/mwalto7/vault"
+)
+
+// BatchOption configures a batch write.
+type BatchOption func(*batchConfig)
+
+type batchConfig struct {
+	allOrNothing bool
+}
+
+// AllOrNothing makes a batch write roll back the secrets it wrote if writing
+// any of the secrets fails. The rollback is best-effort, since rolling back a
+// secret can itself fail.
+func AllOrNothing() BatchOption {
+	return func(cfg *batchConfig) {
+		cfg.allOrNothing = true
+	}
+}
+
+// WriteSecrets writes each of the secrets at the specified paths using the
+// DefaultClient.
+func WriteSecrets(secrets map[string]map[string]interface{}, opts ...BatchOption) error {
+	return DefaultClient.WriteSecrets(secrets, opts...)
+}
+
+// WriteSecrets writes the data of each secret in the map, keyed by path, in
+// path order.
+//
+// The writes are not atomic. If writing some of the secrets fails, the other
+// secrets are still written, and a *vault.BatchError reports the error of
+// every failed path and the paths that were written. With the AllOrNothing
+// option, the previous data of each secret is read before it is written, and
+// the writes stop at the first failure. The secrets written before it are
+// rolled back: a secret created by the write is deleted, and an updated secret
+// is restored to its previous data. The remaining paths are not written. Paths
+// that could not be rolled back are reported in the errors of the
+// *vault.BatchError.
+func (c *Client) WriteSecrets(secrets map[string]map[string]interface{}, opts ...BatchOption) error {
+	var cfg batchConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	paths := make([]string, 0, len(secrets))
+	for path := range secrets {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	previous := make(map[string]map[string]interface{}, len(secrets))
+	errs := make(map[string]error)
+	for _, path := range paths {
+		var prev map[string]interface{}
+		var err error
+		if cfg.allOrNothing {
+			prev, err = c.ReadSecret(path)
+			if errors.Is(err, ErrSecretNotFound) {
+				err = nil
+			}
+		}
+		if err == nil {
+			err = c.WriteSecret(path, secrets[path])
+		}
+		if err != nil {
+			errs[path] = err
+			if cfg.allOrNothing {
+				break
+			}
+			continue
+		}
+		previous[path] = prev
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	var succeeded []string
+	for _, path := range paths {
+		prev, ok := previous[path]
+		if !ok {
+			continue
+		}
+		if !cfg.allOrNothing {
+			succeeded = append(succeeded, path)
+			continue
+		}
+		var err error
+		if prev == nil {
+			err = c.DeleteSecret(path)
+		} else {
+			err = c.WriteSecret(path, prev)
+		}
+		if err != nil {
+			errs[path] = fmt.Errorf("cubbyhole: rolling back write: %w", err)
+			succeeded = append(succeeded, path)
+		}
+	}
+	return &vault.BatchError{Errors: errs, Succeeded: succeeded}
+}
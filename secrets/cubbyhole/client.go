@@ -18,13 +18,16 @@
 package cubbyhole
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path"
+	"strings"
 
-	"github.com/hashicorp/vault/api"
 	"github.com/mitchellh/mapstructure"
 	"github.com/mwalto7/vault"
+	"github.com/mwalto7/vault/internal/vaultclient"
 )
 
 const defaultMountPath = "/cubbyhole"
@@ -33,12 +36,43 @@
 	// ErrEmptyPath is returned when the secret path is an empty string.
 	ErrEmptyPath = errors.New("cubbyhole: path is empty")
 
-	// ErrNoSecretData is returned when no data is stored at the secret path.
-	ErrNoSecretData = errors.New("cubbyhole: no secret data")
+	// ErrSecretNotFound is returned when no data is stored at the secret path.
+	ErrSecretNotFound = vault.ErrSecretNotFound
+
+	// ErrNoSecretData is returned by ReadSecret when no data is stored at the
+	// secret path. An empty list is not an error. It wraps ErrSecretNotFound,
+	// so errors.Is matches either error.
+	ErrNoSecretData = fmt.Errorf("cubbyhole: no secret data: %w", ErrSecretNotFound)
+
+	// ErrEmptyData is returned when the data of a write is nil or empty and
+	// the Client was created with WithRejectEmptyWrites.
+	ErrEmptyData = vault.ErrEmptyData
+
+	// ErrPermissionDenied is returned when the Vault token is invalid or is
+	// not permitted to make the request.
+	ErrPermissionDenied = vault.ErrPermissionDenied
 )
 
-// DefaultClient is a Cubbyhole API client mounted at the default path in Vault.
-var DefaultClient = NewClient(defaultMountPath, nil)
+// IsPermissionDenied reports whether err is a Vault permission denied error.
+// See vault.IsPermissionDenied.
+func IsPermissionDenied(err error) bool {
+	return vault.IsPermissionDenied(err)
+}
+
+// IsNotFound reports whether err is a Vault not found error, such as
+// ErrSecretNotFound. See vault.IsNotFound.
+func IsNotFound(err error) bool {
+	return vault.IsNotFound(err)
+}
+
+// MountPathEnv is the environment variable that overrides the mount path of
+// the DefaultClient.
+const MountPathEnv = "VAULT_CUBBYHOLE_MOUNT"
+
+// DefaultClient is a Cubbyhole API client mounted at the default path in Vault,
+// or at the path set by the MountPathEnv environment variable when the program
+// starts.
+var DefaultClient = NewClient(vaultclient.MountPath(MountPathEnv, defaultMountPath), nil)
 
 // ReadSecret reads the secret at the specified path using the DefaultClient.
 //
@@ -61,6 +95,54 @@ func WriteSecret(path string, data map[string]interface{}) error {
 	return DefaultClient.WriteSecret(path, data)
 }
 
+// ReadSecretWithContext reads the secret at the specified path with the context
+// using the DefaultClient.
+func ReadSecretWithContext(ctx context.Context, path string) (map[string]interface{}, error) {
+	return DefaultClient.ReadSecretWithContext(ctx, path)
+}
+
+// ListSecretsWithContext lists the secret keys at the specified path with the
+// context using the DefaultClient.
+func ListSecretsWithContext(ctx context.Context, path string) ([]string, error) {
+	return DefaultClient.ListSecretsWithContext(ctx, path)
+}
+
+// WriteSecretWithContext creates or updates the secret at the specified path
+// with the context using the DefaultClient.
+func WriteSecretWithContext(ctx context.Context, path string, data map[string]interface{}) error {
+	return DefaultClient.WriteSecretWithContext(ctx, path, data)
+}
+
+// DeleteSecretWithContext deletes the secret at the specified path with the
+// context using the DefaultClient.
+func DeleteSecretWithContext(ctx context.Context, path string) error {
+	return DefaultClient.DeleteSecretWithContext(ctx, path)
+}
+
+// ReadSecretInto reads the secret at the specified path using the
+// DefaultClient and decodes its data into out.
+func ReadSecretInto(path string, out interface{}, opts ...vault.DecodeOption) error {
+	return DefaultClient.ReadSecretInto(path, out, opts...)
+}
+
+// ExistsSecret reports whether a secret is stored at the specified path using
+// the DefaultClient.
+func ExistsSecret(path string) (bool, error) {
+	return DefaultClient.ExistsSecret(path)
+}
+
+// WriteSecretFrom creates or updates the secret at the specified path with the
+// data encoded from in using the DefaultClient.
+func WriteSecretFrom(path string, in interface{}) error {
+	return DefaultClient.WriteSecretFrom(path, in)
+}
+
+// UnwrapSecret returns the data of the response-wrapped secret of the wrapping
+// token using the DefaultClient.
+func UnwrapSecret(wrappingToken string) (map[string]interface{}, error) {
+	return DefaultClient.UnwrapSecret(wrappingToken)
+}
+
 // DeleteSecret deletes the secret at the specified path using the DefaultClient.
 //
 // See https://www.vaultproject.io/api-docs/secret/cubbyhole#delete-secret.
@@ -68,24 +150,45 @@ func DeleteSecret(path string) error {
 	return DefaultClient.DeleteSecret(path)
 }
 
+// DeleteSecretWithData deletes the secret at the specified path, sending data
+// as the request query parameters, using the DefaultClient.
+//
+// See https://www.vaultproject.io/api-docs/secret/cubbyhole#delete-secret.
+func DeleteSecretWithData(path string, data map[string][]string) error {
+	return DefaultClient.DeleteSecretWithData(path, data)
+}
+
 // Client is an API client for the Vault Cubbyhole secrets engine.
 //
 // See https://www.vaultproject.io/api-docs/secret/cubbyhole#cubbyhole-secrets-engine-api.
 type Client struct {
-	mountPath string
-	client    vault.LogicalClient
+	mountPath   string
+	rejectEmpty bool
+	core        *vaultclient.Client
 }
 
 // NewClient creates a new Cubbyhole API client for the secrets engine mounted
-// at the given path in Vault.
-func NewClient(path string, client vault.LogicalClient) *Client {
-	return &Client{mountPath: path, client: client}
+// at the given path in Vault, configured with the given options.
+func NewClient(path string, client vault.LogicalClient, opts ...Option) *Client {
+	c := &Client{mountPath: path, core: &vaultclient.Client{LogicalClient: client}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // ReadSecret reads the secret at the specified path.
 //
 // See https://www.vaultproject.io/api-docs/secret/cubbyhole#read-secret.
 func (c *Client) ReadSecret(path string) (map[string]interface{}, error) {
+	return c.ReadSecretWithContext(context.Background(), path)
+}
+
+// ReadSecretWithContext reads the secret at the specified path. The request is
+// canceled when the context is done.
+//
+// See https://www.vaultproject.io/api-docs/secret/cubbyhole#read-secret.
+func (c *Client) ReadSecretWithContext(ctx context.Context, path string) (map[string]interface{}, error) {
 	path, err := c.secretPath(path)
 	if err != nil {
 		return nil, err
@@ -94,7 +197,7 @@ func (c *Client) ReadSecret(path string) (map[string]interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	secret, err := client.Read(path)
+	secret, err := client.ReadWithContext(ctx, path)
 	if err != nil {
 		return nil, err
 	}
@@ -108,6 +211,15 @@ func (c *Client) ReadSecret(path string) (map[string]interface{}, error) {
 //
 // See https://www.vaultproject.io/api-docs/secret/cubbyhole#list-secrets.
 func (c *Client) ListSecrets(path string) ([]string, error) {
+	return c.ListSecretsWithContext(context.Background(), path)
+}
+
+// ListSecretsWithContext lists the secret keys at the specified path. The
+// request is canceled when the context is done. If no keys are stored under
+// the path, nil is returned with no error, as with the KV clients.
+//
+// See https://www.vaultproject.io/api-docs/secret/cubbyhole#list-secrets.
+func (c *Client) ListSecretsWithContext(ctx context.Context, path string) ([]string, error) {
 	path, err := c.secretPath(path)
 	if err != nil {
 		return nil, err
@@ -116,12 +228,12 @@ func (c *Client) ListSecrets(path string) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	secret, err := client.List(path)
+	secret, err := client.ListWithContext(ctx, path)
 	if err != nil {
 		return nil, err
 	}
 	if secret == nil || len(secret.Data) == 0 {
-		return nil, &os.PathError{Op: "ListSecrets", Path: path, Err: ErrNoSecretData}
+		return nil, nil
 	}
 	var aux struct {
 		Keys []string `mapstructure:"keys"`
@@ -136,22 +248,118 @@ func (c *Client) ListSecrets(path string) ([]string, error) {
 //
 // See https://www.vaultproject.io/api-docs/secret/cubbyhole#create-update-secret.
 func (c *Client) WriteSecret(path string, data map[string]interface{}) error {
+	return c.WriteSecretWithContext(context.Background(), path, data)
+}
+
+// WriteSecretWithContext creates or updates the secret at the specified path.
+// The request is canceled when the context is done.
+//
+// See https://www.vaultproject.io/api-docs/secret/cubbyhole#create-update-secret.
+func (c *Client) WriteSecretWithContext(ctx context.Context, path string, data map[string]interface{}) error {
 	path, err := c.secretPath(path)
 	if err != nil {
 		return err
 	}
+	if c.rejectEmpty && len(data) == 0 {
+		return &os.PathError{Op: "WriteSecret", Path: path, Err: ErrEmptyData}
+	}
 	client, err := c.vaultClient()
 	if err != nil {
 		return err
 	}
-	_, err = client.Write(path, data)
+	_, err = client.WriteWithContext(ctx, path, data)
 	return err
 }
 
+// ReadSecretInto reads the secret at the specified path and decodes its data
+// into out, which must be a pointer to a struct or map. Struct fields are
+// matched to secret keys using their "mapstructure" tags. If no data is stored
+// at the path, ErrSecretNotFound is returned.
+func (c *Client) ReadSecretInto(path string, out interface{}, opts ...vault.DecodeOption) error {
+	data, err := c.ReadSecret(path)
+	if err != nil {
+		return err
+	}
+	if err := vault.DecodeData(data, out, opts...); err != nil {
+		return &os.PathError{Op: "ReadSecretInto", Path: path, Err: err}
+	}
+	return nil
+}
+
+// ExistsSecret reports whether a secret is stored at the specified path. An
+// error is returned only if the secret could not be read.
+func (c *Client) ExistsSecret(path string) (bool, error) {
+	_, err := c.ReadSecret(path)
+	if errors.Is(err, ErrSecretNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// WriteSecretFrom creates or updates the secret at the specified path with the
+// data encoded from in, which must be a struct or a map with string keys.
+// Struct fields are named using their "mapstructure" tags, and nested structs
+// are encoded into nested maps. If the path is empty, ErrEmptyPath is returned.
+func (c *Client) WriteSecretFrom(path string, in interface{}) error {
+	if path == "" {
+		return ErrEmptyPath
+	}
+	data, err := vault.EncodeData(in)
+	if err != nil {
+		return err
+	}
+	return c.WriteSecret(path, data)
+}
+
+// UnwrapSecret returns the data of the response-wrapped secret of the wrapping
+// token. If the wrapping token is empty, invalid or was already used,
+// ErrSecretNotFound is returned.
+//
+// See https://www.vaultproject.io/api-docs/system/wrapping-unwrap.
+func (c *Client) UnwrapSecret(wrappingToken string) (map[string]interface{}, error) {
+	client, err := c.vaultClient()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := vault.Unwrap(client, wrappingToken)
+	if err != nil {
+		return nil, err
+	}
+	return secret.Data, nil
+}
+
 // DeleteSecret deletes the secret at the specified path.
 //
 // See https://www.vaultproject.io/api-docs/secret/cubbyhole#delete-secret.
 func (c *Client) DeleteSecret(path string) error {
+	return c.DeleteSecretWithContext(context.Background(), path)
+}
+
+// DeleteSecretWithContext deletes the secret at the specified path. The request
+// is canceled when the context is done.
+//
+// See https://www.vaultproject.io/api-docs/secret/cubbyhole#delete-secret.
+func (c *Client) DeleteSecretWithContext(ctx context.Context, path string) error {
+	path, err := c.secretPath(path)
+	if err != nil {
+		return err
+	}
+	client, err := c.vaultClient()
+	if err != nil {
+		return err
+	}
+	_, err = client.DeleteWithContext(ctx, path)
+	return err
+}
+
+// DeleteSecretWithData deletes the secret at the specified path, sending data
+// as the request query parameters.
+//
+// See https://www.vaultproject.io/api-docs/secret/cubbyhole#delete-secret.
+func (c *Client) DeleteSecretWithData(path string, data map[string][]string) error {
 	path, err := c.secretPath(path)
 	if err != nil {
 		return err
@@ -160,30 +368,52 @@ func (c *Client) DeleteSecret(path string) error {
 	if err != nil {
 		return err
 	}
-	_, err = client.Delete(path)
+	_, err = client.DeleteWithDataWithContext(context.Background(), path, data)
 	return err
 }
 
 var pathJoin = path.Join
 
+// Path returns the Vault API path that the Client reads and writes for the
+// secret at the specified path, such as "cubbyhole/token", joined exactly as the
+// Client joins it, for logging or Vault policies. If the path is empty, the
+// empty string is returned.
+func (c *Client) Path(path string) string {
+	p, err := c.secretPath(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(p, "/")
+}
+
 func (c *Client) secretPath(path string) (string, error) {
 	if path == "" {
 		return "", ErrEmptyPath
 	}
-	if c.mountPath == "" {
-		c.mountPath = defaultMountPath
+	mountPath := c.mountPath
+	if mountPath == "" {
+		mountPath = defaultMountPath
 	}
-	return pathJoin(c.mountPath, path), nil
+	return pathJoin(mountPath, path), nil
 }
 
 func (c *Client) vaultClient() (vault.LogicalClient, error) {
-	if c.client != nil {
-		return c.client, nil
+	mountPath := c.mountPath
+	if mountPath == "" {
+		mountPath = defaultMountPath
 	}
-	client, err := api.NewClient(api.DefaultConfig())
-	if err != nil {
-		return nil, err
+	return c.core.Logical(mountPath)
+}
+
+// Close closes the idle connections of the default HTTP client of the Vault
+// client the Client creates on first use, when NewClient is given a nil client.
+// A client passed to NewClient is owned by the caller and left unchanged. Close
+// is safe to call on a nil Client and more than once, and the Client remains
+// usable afterwards, opening new connections as needed.
+func (c *Client) Close() error {
+	if c == nil {
+		return nil
 	}
-	c.client = client.Logical()
-	return c.client, nil
+	c.core.Close()
+	return nil
 }
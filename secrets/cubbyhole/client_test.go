@@ -1,13 +1,21 @@
 package cubbyhole_test
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/hashicorp/vault/api"
+	"github.com/mwalto7/vault"
 	"github.com/mwalto7/vault/secrets/cubbyhole"
 	"github.com/mwalto7/vault/vaultmock"
 )
@@ -48,7 +56,7 @@ func TestClient_ReadSecret(t *testing.T) {
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
 			m := vaultmock.NewLogicalClient(gomock.NewController(t))
-			expect := m.EXPECT().Read("/cubbyhole/" + tc.path)
+			expect := m.EXPECT().ReadWithContext(gomock.Any(), "/cubbyhole/"+tc.path)
 			if tc.err != nil {
 				expect.Return(nil, tc.err)
 			} else {
@@ -62,6 +70,9 @@ func TestClient_ReadSecret(t *testing.T) {
 				if want := cubbyhole.ErrNoSecretData; !errors.Is(pathErr, want) {
 					t.Fatalf("err: got %v, want %v", pathErr, want)
 				}
+				if want := cubbyhole.ErrSecretNotFound; !errors.Is(pathErr, want) {
+					t.Fatalf("err: got %v, want %v", pathErr, want)
+				}
 				return
 			}
 
@@ -74,3 +85,286 @@ func TestClient_ReadSecret(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_ExistsSecret(t *testing.T) {
+	tt := []struct {
+		name   string
+		secret *api.Secret
+		err    error
+		exists bool
+	}{
+		{name: "NotFound", secret: nil},
+		{name: "OK", secret: &api.Secret{Data: map[string]interface{}{"foo": "bar"}}, exists: true},
+		{name: "ErrReadPath", err: errors.New("error")},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().ReadWithContext(gomock.Any(), "/cubbyhole/test").Return(tc.secret, tc.err)
+
+			exists, err := cubbyhole.NewClient("", m).ExistsSecret("test")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("err: got %v, want %v", err, tc.err)
+			}
+			if exists != tc.exists {
+				t.Fatalf("exists: got %t, want %t", exists, tc.exists)
+			}
+		})
+	}
+}
+
+func TestClient_WriteSecretFrom(t *testing.T) {
+	type Bootstrap struct {
+		Role  string   `mapstructure:"role"`
+		Hosts []string `mapstructure:"hosts"`
+	}
+
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().WriteWithContext(gomock.Any(), "/cubbyhole/bootstrap", map[string]interface{}{
+		"role":  "web",
+		"hosts": []interface{}{"a", "b"},
+	}).Return(nil, nil)
+
+	c := cubbyhole.NewClient("", m)
+	if err := c.WriteSecretFrom("bootstrap", Bootstrap{Role: "web", Hosts: []string{"a", "b"}}); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if err := c.WriteSecretFrom("", Bootstrap{}); !errors.Is(err, cubbyhole.ErrEmptyPath) {
+		t.Fatalf("err: got %v, want %v", err, cubbyhole.ErrEmptyPath)
+	}
+}
+
+func TestClient_WriteSecrets(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	gomock.InOrder(
+		m.EXPECT().ReadWithContext(gomock.Any(), "/cubbyhole/a").Return(nil, nil),
+		m.EXPECT().WriteWithContext(gomock.Any(), "/cubbyhole/a", map[string]interface{}{"foo": "new"}).Return(nil, nil),
+		m.EXPECT().ReadWithContext(gomock.Any(), "/cubbyhole/b").Return(&api.Secret{Data: map[string]interface{}{"foo": "old"}}, nil),
+		m.EXPECT().WriteWithContext(gomock.Any(), "/cubbyhole/b", map[string]interface{}{"foo": "new"}).Return(nil, nil),
+		m.EXPECT().ReadWithContext(gomock.Any(), "/cubbyhole/c").Return(nil, nil),
+		m.EXPECT().WriteWithContext(gomock.Any(), "/cubbyhole/c", gomock.Any()).Return(nil, errors.New("permission denied")),
+		m.EXPECT().DeleteWithContext(gomock.Any(), "/cubbyhole/a").Return(nil, nil),
+		m.EXPECT().WriteWithContext(gomock.Any(), "/cubbyhole/b", map[string]interface{}{"foo": "old"}).Return(nil, nil),
+	)
+
+	err := cubbyhole.NewClient("", m).WriteSecrets(map[string]map[string]interface{}{
+		"a": {"foo": "new"},
+		"b": {"foo": "new"},
+		"c": {"foo": "new"},
+	}, cubbyhole.AllOrNothing())
+	var batchErr *vault.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("err: got %v, want *vault.BatchError", err)
+	}
+	if got := batchErr.Paths(); !reflect.DeepEqual(got, []string{"c"}) {
+		t.Fatalf("failed paths: got %v, want [c]", got)
+	}
+	if len(batchErr.Succeeded) != 0 {
+		t.Fatalf("succeeded: got %v, want none", batchErr.Succeeded)
+	}
+}
+
+func TestClient_WriteSecrets_StopsAtFirstFailure(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	gomock.InOrder(
+		m.EXPECT().ReadWithContext(gomock.Any(), "/cubbyhole/a").Return(nil, nil),
+		m.EXPECT().WriteWithContext(gomock.Any(), "/cubbyhole/a", gomock.Any()).Return(nil, nil),
+		m.EXPECT().ReadWithContext(gomock.Any(), "/cubbyhole/b").Return(nil, nil),
+		m.EXPECT().WriteWithContext(gomock.Any(), "/cubbyhole/b", gomock.Any()).Return(nil, errors.New("permission denied")),
+		m.EXPECT().DeleteWithContext(gomock.Any(), "/cubbyhole/a").Return(nil, nil),
+	)
+
+	err := cubbyhole.NewClient("", m).WriteSecrets(map[string]map[string]interface{}{
+		"a": {"foo": "bar"},
+		"b": {"foo": "bar"},
+		"c": {"foo": "bar"},
+	}, cubbyhole.AllOrNothing())
+	var batchErr *vault.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("err: got %v, want *vault.BatchError", err)
+	}
+	if got := batchErr.Paths(); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Fatalf("failed paths: got %v, want [b]", got)
+	}
+	if len(batchErr.Succeeded) != 0 {
+		t.Fatalf("succeeded: got %v, want none", batchErr.Succeeded)
+	}
+}
+
+func TestClient_WithContext(t *testing.T) {
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	gomock.InOrder(
+		m.EXPECT().WriteWithContext(ctx, "/cubbyhole/test", map[string]interface{}{"foo": "bar"}).Return(nil, nil),
+		m.EXPECT().ReadWithContext(ctx, "/cubbyhole/test").Return(&api.Secret{Data: map[string]interface{}{"foo": "bar"}}, nil),
+		m.EXPECT().ListWithContext(ctx, "/cubbyhole/test").Return(&api.Secret{Data: map[string]interface{}{"keys": []interface{}{"a"}}}, nil),
+		m.EXPECT().DeleteWithContext(ctx, "/cubbyhole/test").Return(nil, nil),
+	)
+
+	c := cubbyhole.NewClient("", m)
+	if err := c.WriteSecretWithContext(ctx, "test", map[string]interface{}{"foo": "bar"}); err != nil {
+		t.Fatalf("WriteSecretWithContext: err: got %v, want nil", err)
+	}
+	if _, err := c.ReadSecretWithContext(ctx, "test"); err != nil {
+		t.Fatalf("ReadSecretWithContext: err: got %v, want nil", err)
+	}
+	if _, err := c.ListSecretsWithContext(ctx, "test"); err != nil {
+		t.Fatalf("ListSecretsWithContext: err: got %v, want nil", err)
+	}
+	if err := c.DeleteSecretWithContext(ctx, "test"); err != nil {
+		t.Fatalf("DeleteSecretWithContext: err: got %v, want nil", err)
+	}
+}
+
+func TestClient_DeleteSecretWithData(t *testing.T) {
+	data := map[string][]string{"foo": {"bar", "baz"}}
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().DeleteWithDataWithContext(gomock.Any(), "/cubbyhole/test", data).Return(nil, nil)
+
+	if err := cubbyhole.NewClient("", m).DeleteSecretWithData("test", data); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+}
+
+func TestClient_ConcurrentFirstUse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"foo":"bar"}}`)
+	}))
+	defer srv.Close()
+	setenv(t, "VAULT_ADDR", srv.URL)
+
+	c := cubbyhole.NewClient("", nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.ReadSecret("test"); err != nil {
+				t.Errorf("err: got %v, want nil", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNewClient_WithRequestIDFromContext(t *testing.T) {
+	var ids []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get("X-Request-Id"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"foo":"bar"}}`)
+	}))
+	defer srv.Close()
+	setenv(t, "VAULT_ADDR", srv.URL)
+
+	type ctxKey struct{}
+	requestID := cubbyhole.WithRequestIDFromContext(func(ctx context.Context) string {
+		id, _ := ctx.Value(ctxKey{}).(string)
+		return id
+	})
+	c := cubbyhole.NewClient("", nil, requestID)
+	ctx := context.WithValue(context.Background(), ctxKey{}, "incoming-1")
+	if _, err := c.ReadSecretWithContext(ctx, "test"); err != nil {
+		t.Fatalf("ReadSecretWithContext: err: got %v, want nil", err)
+	}
+	if _, err := c.ReadSecret("test"); err != nil {
+		t.Fatalf("ReadSecret: err: got %v, want nil", err)
+	}
+	if want := []string{"incoming-1", ""}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("request IDs: got %v, want %v", ids, want)
+	}
+
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	if _, err := cubbyhole.NewClient("", m, requestID).ReadSecret("test"); err == nil {
+		t.Fatal("caller client: err: got nil, want error")
+	}
+}
+
+func TestClient_Close(t *testing.T) {
+	closed := make(chan struct{}, 1)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"foo":"bar"}}`)
+	}))
+	srv.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			closed <- struct{}{}
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+	setenv(t, "VAULT_ADDR", srv.URL)
+
+	c := cubbyhole.NewClient("", nil)
+	if _, err := c.ReadSecret("test"); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: err: got %v, want nil", err)
+	}
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("idle connection was not closed")
+	}
+
+	var nilClient *cubbyhole.Client
+	if err := nilClient.Close(); err != nil {
+		t.Fatalf("nil Close: err: got %v, want nil", err)
+	}
+}
+
+func setenv(t *testing.T, key, value string) {
+	t.Helper()
+	prev, ok := os.LookupEnv(key)
+	if err := os.Setenv(key, value); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, prev)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+func TestClient_ListSecretsEmpty(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret *api.Secret
+	}{
+		{name: "NilSecret", secret: nil},
+		{name: "NoData", secret: &api.Secret{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().ListWithContext(gomock.Any(), "/cubbyhole/test").Return(tt.secret, nil)
+
+			keys, err := cubbyhole.NewClient("", m).ListSecrets("test")
+			if err != nil || keys != nil {
+				t.Fatalf("got %v, %v, want nil, nil", keys, err)
+			}
+		})
+	}
+}
+
+func TestNewClient_WithRejectEmptyWrites(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().WriteWithContext(gomock.Any(), "/cubbyhole/test", map[string]interface{}{}).Return(nil, nil)
+
+	if err := cubbyhole.NewClient("", m).WriteSecret("test", map[string]interface{}{}); err != nil {
+		t.Fatalf("permissive: err: got %v, want nil", err)
+	}
+	c := cubbyhole.NewClient("", m, cubbyhole.WithRejectEmptyWrites(true))
+	for _, data := range []map[string]interface{}{nil, {}} {
+		if err := c.WriteSecret("test", data); !errors.Is(err, cubbyhole.ErrEmptyData) {
+			t.Fatalf("write %v: err: got %v, want %v", data, err, cubbyhole.ErrEmptyData)
+		}
+	}
+}
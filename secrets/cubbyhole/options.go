@@ -0,0 +1,34 @@
+package cubbyhole
+
+import "context"
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithRejectEmptyWrites sets whether writes of nil or empty data fail with
+// ErrEmptyData instead of storing an empty secret, which is almost always a
+// bug. Empty writes are allowed by default.
+func WithRejectEmptyWrites(reject bool) Option {
+	return func(c *Client) {
+		c.rejectEmpty = reject
+	}
+}
+
+// WithRequestIDFromContext sets the X-Request-Id header of each request made by
+// the Client to the ID returned by fn for the context of the request, to
+// correlate the request across the logs of a gateway in front of Vault and the
+// Vault audit log. The *WithContext methods pass their context to fn, so it can
+// return the ID of the caller's request; the other methods pass
+// context.Background(). fn is called for every request, including retries; if
+// it returns the empty string, the header is not set.
+//
+// The header is set by the transport of the HTTP client of the Vault client the
+// Client creates on first use when NewClient is given a nil client. Requests
+// fail if NewClient is given a client, whose HTTP client belongs to the
+// caller; wrap its transport with vault.RequestIDTransport instead. Requests
+// also fail if the Vault address is a unix socket.
+func WithRequestIDFromContext(fn func(ctx context.Context) string) Option {
+	return func(c *Client) {
+		c.core.RequestID = fn
+	}
+}
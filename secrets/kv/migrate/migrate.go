@@ -0,0 +1,125 @@
+// Package migrate migrates secrets between Vault KV secrets engines, such as
+// from a KVv1 mount to a KVv2 mount when upgrading the secrets engine.
+//
+// See https://www.vaultproject.io/docs/secrets/kv/kv-v2#upgrading-from-version-1.
+package migrate
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/mwalto7/vault"
+	kv1 "github.com/mwalto7/vault/secrets/kv/v1"
+	kv2 "github.com/mwalto7/vault/secrets/kv/v2"
+)
+
+// ErrSecretExists is the error of a secret that is not migrated because a
+// secret already exists at its path in the destination mount.
+var ErrSecretExists = errors.New("migrate: secret already exists in destination")
+
+// Option configures a migration.
+type Option func(*config)
+
+type config struct {
+	dryRun   bool
+	onResult func(Result)
+}
+
+// DryRun makes Migrate read every secret it would migrate and check that no
+// secret exists at its path in the destination mount, without writing any
+// secret, for example to find the secrets that would fail to migrate before
+// migrating them for real.
+func DryRun() Option {
+	return func(cfg *config) {
+		cfg.dryRun = true
+	}
+}
+
+// OnResult sets a function that is called with the Result of each secret as
+// it is migrated, for example to log the progress of a migration.
+func OnResult(fn func(Result)) Option {
+	return func(cfg *config) {
+		cfg.onResult = fn
+	}
+}
+
+// Result is the result of migrating a single secret.
+type Result struct {
+	// Path is the path of the secret, relative to both mounts.
+	Path string
+
+	// Version is the KVv2 secret version written, which is always 1. It is
+	// zero for a dry run or if the secret failed to migrate.
+	Version int
+
+	// Err is the error migrating the secret, or nil if it succeeded.
+	Err error
+}
+
+// Migrate copies every secret under the root path of the KVv1 mount of src to
+// the same path of the KVv2 mount of dst, preserving the keys and data of each
+// secret. An empty root migrates the entire mount. The secrets are migrated
+// one at a time, in the order listed by src.Walk.
+//
+// Since KVv1 secrets are not versioned, each secret is written as version 1 of
+// a new KVv2 secret. An existing secret at the path in dst is never
+// overwritten: the secret fails to migrate with ErrSecretExists instead, and
+// the write uses check-and-set in case the secret is created concurrently.
+// Rerunning a migration that partially failed therefore leaves the secrets
+// already migrated, and any changes made to them since, unchanged.
+//
+// If listing the secrets fails, that error is returned. If migrating some of
+// the secrets fails, the other secrets are still migrated, and a
+// *vault.BatchError reports the error of every failed path and the paths that
+// were migrated.
+func Migrate(src *kv1.Client, dst *kv2.Client, root string, opts ...Option) error {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	var (
+		succeeded []string
+		errs      = make(map[string]error)
+	)
+	if err := src.Walk(root, func(path string) error {
+		res := migrateSecret(src, dst, path, cfg.dryRun)
+		if res.Err != nil {
+			errs[path] = res.Err
+		} else {
+			succeeded = append(succeeded, path)
+		}
+		if cfg.onResult != nil {
+			cfg.onResult(res)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		sort.Strings(succeeded)
+		return &vault.BatchError{Errors: errs, Succeeded: succeeded}
+	}
+	return nil
+}
+
+func migrateSecret(src *kv1.Client, dst *kv2.Client, path string, dryRun bool) Result {
+	data, err := src.ReadSecret(path)
+	if err != nil {
+		return Result{Path: path, Err: err}
+	}
+	exists, err := dst.ExistsSecret(path)
+	if err != nil {
+		return Result{Path: path, Err: err}
+	}
+	if exists {
+		return Result{Path: path, Err: ErrSecretExists}
+	}
+	if dryRun {
+		return Result{Path: path}
+	}
+	v, err := dst.WriteSecretVersion(path, 0, data)
+	if err != nil {
+		return Result{Path: path, Err: err}
+	}
+	return Result{Path: path, Version: v.Version}
+}
@@ -0,0 +1,120 @@
+package migrate_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mwalto7/vault"
+	"github.com/mwalto7/vault/secrets/kv/migrate"
+	kv1 "github.com/mwalto7/vault/secrets/kv/v1"
+	kv2 "github.com/mwalto7/vault/secrets/kv/v2"
+	"github.com/mwalto7/vault/vaulttest"
+)
+
+func newClients(t *testing.T) (*kv1.Client, *kv2.Client) {
+	t.Helper()
+	logical := vaulttest.NewInMemoryLogical(vaulttest.WithKVv1Mount("kv"))
+	src := kv1.NewClient("/kv", kv1.WithLogicalClient(logical))
+	dst := kv2.NewClient("/secret", kv2.WithLogicalClient(logical))
+	for path, data := range map[string]map[string]interface{}{
+		"apps/web/db":  {"user": "web", "password": "hunter2"},
+		"apps/worker":  {"token": "s.worker"},
+		"other/secret": {"foo": "bar"},
+	} {
+		if err := src.WriteSecret(path, data); err != nil {
+			t.Fatalf("WriteSecret(%s): err: got %v, want nil", path, err)
+		}
+	}
+	return src, dst
+}
+
+func TestMigrate(t *testing.T) {
+	src, dst := newClients(t)
+	if _, err := dst.WriteSecretLatest("apps/worker", map[string]interface{}{"token": "existing"}); err != nil {
+		t.Fatalf("WriteSecretLatest: err: got %v, want nil", err)
+	}
+
+	var results []migrate.Result
+	err := migrate.Migrate(src, dst, "apps", migrate.OnResult(func(res migrate.Result) {
+		results = append(results, res)
+	}))
+	var batchErr *vault.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("err: got %v, want *vault.BatchError", err)
+	}
+	if got, want := batchErr.Paths(), []string{"apps/worker"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("failed paths: got %v, want %v", got, want)
+	}
+	if err := batchErr.Errors["apps/worker"]; !errors.Is(err, migrate.ErrSecretExists) {
+		t.Fatalf("apps/worker: err: got %v, want %v", err, migrate.ErrSecretExists)
+	}
+	if got, want := batchErr.Succeeded, []string{"apps/web/db"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("succeeded paths: got %v, want %v", got, want)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results: got %+v, want 2 results", results)
+	}
+
+	secret, err := dst.ReadSecretLatest("apps/web/db")
+	if err != nil {
+		t.Fatalf("ReadSecretLatest: err: got %v, want nil", err)
+	}
+	if secret.Metadata.Version != 1 {
+		t.Fatalf("version: got %d, want 1", secret.Metadata.Version)
+	}
+	if want := map[string]interface{}{"user": "web", "password": "hunter2"}; !vault.EqualData(secret.Data, want) {
+		t.Fatalf("data: got %v, want %v", secret.Data, want)
+	}
+	if secret, err := dst.ReadSecretLatest("apps/worker"); err != nil || secret.Data["token"] != "existing" {
+		t.Fatalf("existing secret: got %v, %v, want it unchanged", secret.Data, err)
+	}
+	if exists, err := dst.ExistsSecret("other/secret"); err != nil || exists {
+		t.Fatalf("ExistsSecret(other/secret): got %t, %v, want false, nil", exists, err)
+	}
+}
+
+func TestMigrate_DryRun(t *testing.T) {
+	src, dst := newClients(t)
+
+	var paths []string
+	err := migrate.Migrate(src, dst, "", migrate.DryRun(), migrate.OnResult(func(res migrate.Result) {
+		if res.Err != nil || res.Version != 0 {
+			t.Errorf("result %s: got %+v, want no error and version 0", res.Path, res)
+		}
+		paths = append(paths, res.Path)
+	}))
+	if err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if len(paths) != 3 {
+		t.Fatalf("results: got %v, want 3 paths", paths)
+	}
+	for _, path := range paths {
+		if exists, err := dst.ExistsSecret(path); err != nil || exists {
+			t.Fatalf("ExistsSecret(%s): got %t, %v, want false, nil", path, exists, err)
+		}
+	}
+}
+
+func TestMigrate_DryRunConflict(t *testing.T) {
+	src, dst := newClients(t)
+	if _, err := dst.WriteSecretLatest("apps/worker", map[string]interface{}{"token": "existing"}); err != nil {
+		t.Fatalf("WriteSecretLatest: err: got %v, want nil", err)
+	}
+
+	err := migrate.Migrate(src, dst, "apps", migrate.DryRun())
+	var batchErr *vault.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("err: got %v, want *vault.BatchError", err)
+	}
+	if err := batchErr.Errors["apps/worker"]; !errors.Is(err, migrate.ErrSecretExists) {
+		t.Fatalf("apps/worker: err: got %v, want %v", err, migrate.ErrSecretExists)
+	}
+	if got, want := batchErr.Succeeded, []string{"apps/web/db"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("succeeded paths: got %v, want %v", got, want)
+	}
+	if exists, err := dst.ExistsSecret("apps/web/db"); err != nil || exists {
+		t.Fatalf("ExistsSecret(apps/web/db): got %t, %v, want false, nil", exists, err)
+	}
+}
@@ -0,0 +1,98 @@
+package kv
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/mitchellh/mapstructure"
+	"github.com/mwalto7/vault"
+	kv1 "github.com/mwalto7/vault/secrets/kv/v1"
+	kv2 "github.com/mwalto7/vault/secrets/kv/v2"
+)
+
+// ErrNotKVMount is returned when the secrets engine at a mount path is not a
+// KV secrets engine.
+var ErrNotKVMount = errors.New("kv: mount is not a KV secrets engine")
+
+// DetectVersion returns the version of the KV secrets engine mounted at the
+// given path in Vault, either 1 or 2. If client is nil, a client is created
+// from the default Vault API configuration.
+//
+// The version is read from the mount options returned by the
+// "sys/internal/ui/mounts" endpoint, which only requires the token to have a
+// capability on the mount.
+func DetectVersion(mountPath string, client vault.LogicalClient) (int, error) {
+	if client == nil {
+		c, err := api.NewClient(api.DefaultConfig())
+		if err != nil {
+			return 0, err
+		}
+		client = c.Logical()
+	}
+	p := path.Join("sys/internal/ui/mounts", strings.Trim(mountPath, "/"))
+	secret, err := client.Read(p)
+	if err != nil {
+		return 0, &os.PathError{Op: "DetectVersion", Path: p, Err: err}
+	}
+	if secret == nil || len(secret.Data) == 0 {
+		return 0, &os.PathError{Op: "DetectVersion", Path: p, Err: ErrNotKVMount}
+	}
+	var aux struct {
+		Type    string            `mapstructure:"type"`
+		Options map[string]string `mapstructure:"options"`
+	}
+	if err := mapstructure.WeakDecode(secret.Data, &aux); err != nil {
+		return 0, err
+	}
+	switch aux.Type {
+	case "kv", "generic":
+	default:
+		return 0, &os.PathError{Op: "DetectVersion", Path: p, Err: ErrNotKVMount}
+	}
+	switch v := aux.Options["version"]; v {
+	case "", "1":
+		return 1, nil
+	case "2":
+		return 2, nil
+	default:
+		return 0, fmt.Errorf("kv: unsupported KV secrets engine version %q", v)
+	}
+}
+
+// Store is a SecretStore for a KV secrets engine of either version.
+type Store struct {
+	vault.SecretStore
+	version int
+}
+
+// OpenStore detects the version of the KV secrets engine mounted at the given
+// path in Vault and returns a Store backed by the KVv1 or KVv2 client. The
+// detected version is kept by the Store, so the mount is only inspected once.
+// If client is nil, a client is created from the default Vault API
+// configuration.
+func OpenStore(mountPath string, client vault.LogicalClient) (*Store, error) {
+	if client == nil {
+		c, err := api.NewClient(api.DefaultConfig())
+		if err != nil {
+			return nil, err
+		}
+		client = c.Logical()
+	}
+	version, err := DetectVersion(mountPath, client)
+	if err != nil {
+		return nil, err
+	}
+	if version == 1 {
+		return &Store{SecretStore: kv1.NewClient(mountPath, kv1.WithLogicalClient(client)), version: 1}, nil
+	}
+	return &Store{SecretStore: kv2.NewClient(mountPath, kv2.WithLogicalClient(client)), version: 2}, nil
+}
+
+// Version returns the version of the KV secrets engine, either 1 or 2.
+func (s *Store) Version() int {
+	return s.version
+}
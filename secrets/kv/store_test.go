@@ -0,0 +1,66 @@
+package kv_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/vault/api"
+	"github.com/mwalto7/vault/secrets/kv"
+	kv1 "github.com/mwalto7/vault/secrets/kv/v1"
+	kv2 "github.com/mwalto7/vault/secrets/kv/v2"
+	"github.com/mwalto7/vault/vaultmock"
+)
+
+func TestOpenStore(t *testing.T) {
+	tt := []struct {
+		name    string
+		data    map[string]interface{}
+		version int
+		err     error
+	}{
+		{
+			name:    "KVv1",
+			data:    map[string]interface{}{"type": "kv", "options": nil},
+			version: 1,
+		},
+		{
+			name:    "KVv2",
+			data:    map[string]interface{}{"type": "kv", "options": map[string]interface{}{"version": "2"}},
+			version: 2,
+		},
+		{
+			name: "ErrNotKVMount",
+			data: map[string]interface{}{"type": "transit"},
+			err:  kv.ErrNotKVMount,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().Read("sys/internal/ui/mounts/my-kv").Return(&api.Secret{Data: tc.data}, nil).Times(1)
+
+			store, err := kv.OpenStore("/my-kv/", m)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("err: got %v, want %v", err, tc.err)
+			}
+			if tc.err != nil {
+				return
+			}
+			if got := store.Version(); got != tc.version {
+				t.Fatalf("version: got %d, want %d", got, tc.version)
+			}
+			switch store.SecretStore.(type) {
+			case *kv1.Client:
+				if tc.version != 1 {
+					t.Fatalf("store: got KVv1 client, want KVv%d", tc.version)
+				}
+			case *kv2.Client:
+				if tc.version != 2 {
+					t.Fatalf("store: got KVv2 client, want KVv%d", tc.version)
+				}
+			}
+		})
+	}
+}
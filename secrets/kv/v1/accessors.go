@@ -0,0 +1,53 @@
+package kv
+
+import "github.com/mwalto7/vault"
+
+// GetString returns the string value of the key in the secret data read with
+// ReadSecret. See vault.GetString.
+func GetString(data map[string]interface{}, key string) (string, bool) {
+	return vault.GetString(data, key)
+}
+
+// GetInt returns the integer value of the key in the secret data read with
+// ReadSecret, converting json.Numbers and numeric strings. See vault.GetInt.
+func GetInt(data map[string]interface{}, key string) (int, bool) {
+	return vault.GetInt(data, key)
+}
+
+// GetFloat returns the floating-point value of the key in the secret data read
+// with ReadSecret, converting json.Numbers and numeric strings. See
+// vault.GetFloat.
+func GetFloat(data map[string]interface{}, key string) (float64, bool) {
+	return vault.GetFloat(data, key)
+}
+
+// GetBool returns the boolean value of the key in the secret data read with
+// ReadSecret, converting strings such as "true". See vault.GetBool.
+func GetBool(data map[string]interface{}, key string) (bool, bool) {
+	return vault.GetBool(data, key)
+}
+
+// GetBytes returns the base64-decoded value of the key in the secret data read
+// with ReadSecret. See vault.GetBytes.
+func GetBytes(data map[string]interface{}, key string) ([]byte, error) {
+	return vault.GetBytes(data, key)
+}
+
+// GetPath returns the value at the dotted path in the nested secret data read
+// with ReadSecret, such as "db.password" or "hosts.0". See vault.GetPath.
+func GetPath(data map[string]interface{}, path string) (interface{}, bool) {
+	return vault.GetPath(data, path)
+}
+
+// WriteSecretBinary creates or updates the secret at the specified path with
+// the base64-encoded binary data using the DefaultClient.
+func WriteSecretBinary(path string, data map[string][]byte) error {
+	return DefaultClient.WriteSecretBinary(path, data)
+}
+
+// WriteSecretBinary creates or updates the secret at the specified path with
+// each value of data encoded as a standard base64 string. The values can be
+// decoded with GetBytes.
+func (c *Client) WriteSecretBinary(path string, data map[string][]byte) error {
+	return c.WriteSecret(path, vault.EncodeBinary(data))
+}
@@ -0,0 +1,164 @@
+package kv
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/mwalto7/vault"
+	"golang.org/x/sync/errgroup"
+)
+
+// BatchOption configures a batch write.
+type BatchOption func(*batchConfig)
+
+type batchConfig struct {
+	allOrNothing bool
+}
+
+// AllOrNothing makes a batch write roll back the secrets it wrote if writing
+// any of the secrets fails. The rollback is best-effort: since Vault has no
+// transactions, other clients can observe the written secrets before they are
+// rolled back, and rolling back a secret can itself fail.
+func AllOrNothing() BatchOption {
+	return func(cfg *batchConfig) {
+		cfg.allOrNothing = true
+	}
+}
+
+// WriteSecrets writes each of the secrets at the specified paths using the
+// DefaultClient.
+func WriteSecrets(secrets map[string]map[string]interface{}, opts ...BatchOption) error {
+	return DefaultClient.WriteSecrets(secrets, opts...)
+}
+
+// WriteSecrets writes the data of each secret in the map, keyed by path,
+// making at most as many concurrent requests as configured with
+// WithConcurrency.
+//
+// The writes are not atomic. If writing some of the secrets fails, the other
+// secrets are still written, and a *vault.BatchError reports the error of
+// every failed path and the paths that were written. With the AllOrNothing
+// option, the previous data of each secret is read before it is written, and
+// no further writes are started after a write fails. The secrets written
+// before then, including writes already in flight, are rolled back: a secret
+// created by the write is deleted, and an updated secret is restored to its
+// previous data. The remaining paths are not written. Paths that could not be
+// rolled back are reported in the errors of the *vault.BatchError.
+func (c *Client) WriteSecrets(secrets map[string]map[string]interface{}, opts ...BatchOption) error {
+	var cfg batchConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if _, err := c.vaultClient(); err != nil {
+		return err
+	}
+	paths := make([]string, 0, len(secrets))
+	for path := range secrets {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var (
+		failed   bool
+		mu       sync.Mutex
+		previous = make(map[string]map[string]interface{}, len(secrets))
+		errs     = make(map[string]error)
+	)
+	c.forEach(paths, func(path string) {
+		mu.Lock()
+		stop := cfg.allOrNothing && failed
+		mu.Unlock()
+		if stop {
+			return
+		}
+		var prev map[string]interface{}
+		var err error
+		if cfg.allOrNothing {
+			prev, err = c.ReadSecret(path)
+			if errors.Is(err, ErrSecretNotFound) {
+				err = nil
+			}
+		}
+		if err == nil {
+			err = c.WriteSecret(path, secrets[path])
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs[path] = err
+			failed = true
+			return
+		}
+		previous[path] = prev
+	})
+	if len(errs) == 0 {
+		return nil
+	}
+	if cfg.allOrNothing {
+		previous = c.rollbackWrites(previous, errs)
+	}
+	succeeded := make([]string, 0, len(previous))
+	for path := range previous {
+		succeeded = append(succeeded, path)
+	}
+	sort.Strings(succeeded)
+	return &vault.BatchError{Errors: errs, Succeeded: succeeded}
+}
+
+// rollbackWrites restores the previous data of the written secrets, deleting
+// the secrets that had no previous data, and adds the error of every path that
+// could not be rolled back to errs. The secrets that remain written are
+// returned.
+func (c *Client) rollbackWrites(previous map[string]map[string]interface{}, errs map[string]error) map[string]map[string]interface{} {
+	paths := make([]string, 0, len(previous))
+	for path := range previous {
+		paths = append(paths, path)
+	}
+	var (
+		mu        sync.Mutex
+		remaining = make(map[string]map[string]interface{})
+	)
+	c.forEach(paths, func(path string) {
+		var err error
+		if prev := previous[path]; prev == nil {
+			err = c.DeleteSecret(path)
+		} else {
+			err = c.WriteSecret(path, prev)
+		}
+		if err == nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		errs[path] = fmt.Errorf("vault: rolling back write: %w", err)
+		remaining[path] = previous[path]
+	})
+	return remaining
+}
+
+// forEach calls fn for each unique path, with at most c.concurrency calls
+// running at the same time.
+func (c *Client) forEach(paths []string, fn func(path string)) {
+	n := c.concurrency
+	if n < 1 {
+		n = 1
+	}
+	sem := make(chan struct{}, n)
+	seen := make(map[string]bool, len(paths))
+	var g errgroup.Group
+	for _, path := range paths {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		path := path
+		sem <- struct{}{}
+		g.Go(func() error {
+			defer func() { <-sem }()
+			fn(path)
+			return nil
+		})
+	}
+	_ = g.Wait()
+}
@@ -10,7 +10,7 @@
 // To use a kv secrets engine mounted at a custom path, create a new Client:
 //
 //    // Create a secret at the KV path "/my-kv/some/path".
-//    c := kv.NewClient("/my-kv", nil)
+//    c := kv.NewClient("/my-kv")
 //    c.WriteSecret("some/path", map[string]interface{}{"foo": "bar"})
 //
 // See https://www.vaultproject.io/api-docs/secret/kv/kv-v1 for more information
@@ -19,25 +19,74 @@
 
 import (
 	"errors"
+	"fmt"
+	"os"
 	"path"
+	"strings"
+	"sync"
 
 	"github.com/hashicorp/vault/api"
 	"github.com/mitchellh/mapstructure"
 	"github.com/mwalto7/vault"
+	"github.com/mwalto7/vault/internal/vaultclient"
 )
 
-const defaultMountPath = "/secret"
+const (
+	defaultMountPath   = "/secret"
+	defaultConcurrency = 8
+)
+
+// ErrInvalidSecretPath is returned when a secret path starts with a slash or
+// contains "." or ".." segments.
+var ErrInvalidSecretPath = errors.New("vault: invalid secret path")
+
+// ErrSecretNotFound is returned when no data is stored at the secret path.
+var ErrSecretNotFound = vault.ErrSecretNotFound
+
+// ErrEmptyData is returned when the data of a write is nil or empty and the
+// Client was created with WithRejectEmptyWrites.
+var ErrEmptyData = vault.ErrEmptyData
+
+// ErrPermissionDenied is returned when the Vault token is invalid or is not
+// permitted to make the request. Use IsPermissionDenied to also match errors
+// of clients that do not classify their errors.
+var ErrPermissionDenied = vault.ErrPermissionDenied
+
+// IsPermissionDenied reports whether err is a Vault permission denied error.
+// See vault.IsPermissionDenied.
+func IsPermissionDenied(err error) bool {
+	return vault.IsPermissionDenied(err)
+}
+
+// IsNotFound reports whether err is a Vault not found error, such as
+// ErrSecretNotFound. See vault.IsNotFound.
+func IsNotFound(err error) bool {
+	return vault.IsNotFound(err)
+}
 
-// DefaultClient is a KVv1 API client mounted at the default path in Vault.
-var DefaultClient = NewClient(defaultMountPath, nil)
+// MountPathEnv is the environment variable that overrides the mount path of
+// the DefaultClient, for KVv1 secrets engines not mounted at "/secret".
+const MountPathEnv = "VAULT_KV1_MOUNT"
+
+// DefaultClient is a KVv1 API client mounted at the default path in Vault, or
+// at the path set by the MountPathEnv environment variable when the program
+// starts.
+var DefaultClient = NewClient(vaultclient.MountPath(MountPathEnv, defaultMountPath))
 
 // ReadSecret reads the secret at the specified path using the DefaultClient.
+// If no data is stored at the path, ErrSecretNotFound is returned.
 //
 // See https://www.vaultproject.io/api/secret/kv/kv-v1#read-secret.
 func ReadSecret(path string) (map[string]interface{}, error) {
 	return DefaultClient.ReadSecret(path)
 }
 
+// ReadSecretWithLease reads the secret at the specified path using the
+// DefaultClient and also returns the lease of the Vault response.
+func ReadSecretWithLease(path string) (map[string]interface{}, vault.Lease, error) {
+	return DefaultClient.ReadSecretWithLease(path)
+}
+
 // ListSecrets lists the secret keys at the specified path using the DefaultClient.
 //
 // See https://www.vaultproject.io/api/secret/kv/kv-v1#list-secrets.
@@ -52,6 +101,30 @@ func WriteSecret(path string, data map[string]interface{}) error {
 	return DefaultClient.WriteSecret(path, data)
 }
 
+// ReadSecretInto reads the secret at the specified path using the
+// DefaultClient and decodes its data into out.
+func ReadSecretInto(path string, out interface{}, opts ...vault.DecodeOption) error {
+	return DefaultClient.ReadSecretInto(path, out, opts...)
+}
+
+// ExistsSecret reports whether a secret is stored at the specified path using
+// the DefaultClient.
+func ExistsSecret(path string) (bool, error) {
+	return DefaultClient.ExistsSecret(path)
+}
+
+// WriteSecretFrom creates or updates the secret at the specified path with the
+// data encoded from in using the DefaultClient.
+func WriteSecretFrom(path string, in interface{}) error {
+	return DefaultClient.WriteSecretFrom(path, in)
+}
+
+// UnwrapSecret returns the data of the response-wrapped secret of the wrapping
+// token using the DefaultClient.
+func UnwrapSecret(wrappingToken string) (map[string]interface{}, error) {
+	return DefaultClient.UnwrapSecret(wrappingToken)
+}
+
 // DeleteSecret deletes the secret at the specified path using the DefaultClient.
 //
 // See https://www.vaultproject.io/api/secret/kv/kv-v1#delete-secret.
@@ -59,24 +132,79 @@ func DeleteSecret(path string) error {
 	return DefaultClient.DeleteSecret(path)
 }
 
+// DeleteSecretWithData deletes the secret at the specified path, sending data
+// as the request query parameters, using the DefaultClient.
+//
+// See https://www.vaultproject.io/api/secret/kv/kv-v1#delete-secret.
+func DeleteSecretWithData(path string, data map[string][]string) error {
+	return DefaultClient.DeleteSecretWithData(path, data)
+}
+
 // Client is an API client for the Vault KVv1 secrets engine.
 //
 // See https://www.vaultproject.io/api-docs/secret/kv/kv-v1#kv-secrets-engine-version-1-api.
 type Client struct {
-	mountPath string
-	client    vault.LogicalClient
+	mountPath        string
+	mountErr         error
+	prefix           string
+	concurrency      int
+	defaultMountPath string
+	core             *vaultclient.Client
+	validator        vault.WriteValidator
+	rejectEmpty      bool
+	dryRun           bool
+	dryRunMu         sync.Mutex
+	planned          []Action
 }
 
-// NewClient creates a new KVv1 API client for the secrets engine mounted
-// at the given path in Vault.
-func NewClient(path string, client vault.LogicalClient) *Client {
-	return &Client{mountPath: path, client: client}
+// NewClient creates a new KVv1 API client for the secrets engine mounted at the
+// given path in Vault, configured with the given options.
+func NewClient(path string, opts ...Option) *Client {
+	c := &Client{
+		mountPath:        path,
+		concurrency:      defaultConcurrency,
+		defaultMountPath: defaultMountPath,
+		core:             &vaultclient.Client{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.mountPath == "" {
+		c.mountPath = c.defaultMountPath
+	}
+	c.mountPath, c.mountErr = cleanMountPath(c.mountPath)
+	return c
 }
 
-// ReadSecret reads the secret at the specified path.
+// ReadSecret reads the secret at the specified path. If no data is stored at
+// the path, ErrSecretNotFound is returned.
 //
 // See https://www.vaultproject.io/api/secret/kv/kv-v1#read-secret.
 func (c *Client) ReadSecret(path string) (map[string]interface{}, error) {
+	secret, err := c.readSecret(path)
+	if err != nil {
+		return nil, err
+	}
+	return secret.Data, nil
+}
+
+// ReadSecretWithLease reads the secret at the specified path like ReadSecret
+// and also returns the lease of the Vault response. KVv1 secrets have no
+// lease, but a secret written with a "ttl" key reports the TTL as the lease
+// duration, which is the interval at which the secret should be read again.
+//
+// See https://www.vaultproject.io/api/secret/kv/kv-v1#read-secret.
+func (c *Client) ReadSecretWithLease(path string) (map[string]interface{}, vault.Lease, error) {
+	secret, err := c.readSecret(path)
+	if err != nil {
+		return nil, vault.Lease{}, err
+	}
+	return secret.Data, vault.LeaseOf(secret), nil
+}
+
+// readSecret reads the secret at the specified path and returns the Vault
+// response, which has data.
+func (c *Client) readSecret(path string) (*api.Secret, error) {
 	path, err := c.secretPath(path)
 	if err != nil {
 		return nil, err
@@ -87,12 +215,12 @@ func (c *Client) ReadSecret(path string) (map[string]interface{}, error) {
 	}
 	secret, err := client.Read(path)
 	if err != nil {
-		return nil, err
+		return nil, &os.PathError{Op: "ReadSecret", Path: path, Err: err}
 	}
 	if secret == nil || len(secret.Data) == 0 {
-		return nil, nil
+		return nil, &os.PathError{Op: "ReadSecret", Path: path, Err: ErrSecretNotFound}
 	}
-	return secret.Data, nil
+	return secret, nil
 }
 
 // ListSecrets lists the secret keys at the specified path.
@@ -103,40 +231,128 @@ func (c *Client) ListSecrets(path string) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	return c.list("ListSecrets", path)
+}
+
+func (c *Client) list(op, path string) ([]string, error) {
+	data, err := c.listData(op, path)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return decodeKeys(data)
+}
+
+// listData makes the list request for the path and returns the data of the
+// response, or nil if no keys are stored under the path.
+func (c *Client) listData(op, path string) (map[string]interface{}, error) {
 	client, err := c.vaultClient()
 	if err != nil {
 		return nil, err
 	}
 	secret, err := client.List(path)
 	if err != nil {
-		return nil, err
+		return nil, &os.PathError{Op: op, Path: path, Err: err}
 	}
 	if secret == nil || len(secret.Data) == 0 {
 		return nil, nil
 	}
+	return secret.Data, nil
+}
+
+// decodeKeys decodes the keys of the data of a list response.
+func decodeKeys(data map[string]interface{}) ([]string, error) {
 	var aux struct {
 		Keys []string `mapstructure:"keys"`
 	}
-	if err := mapstructure.Decode(secret.Data, &aux); err != nil {
+	if err := mapstructure.Decode(data, &aux); err != nil {
 		return nil, err
 	}
 	return aux.Keys, nil
 }
 
-// WriteSecret creates or updates the secret at the specified path.
+// WriteSecret creates or updates the secret at the specified path. The data is
+// validated first with the validator set with WithWriteValidator, if any.
 //
 // See https://www.vaultproject.io/api/secret/kv/kv-v1#create-update-secret.
 func (c *Client) WriteSecret(path string, data map[string]interface{}) error {
+	relPath := path
 	path, err := c.secretPath(path)
 	if err != nil {
 		return err
 	}
+	if c.rejectEmpty && len(data) == 0 {
+		return &os.PathError{Op: "WriteSecret", Path: path, Err: ErrEmptyData}
+	}
+	if c.validator != nil {
+		if err := c.validator(relPath, data); err != nil {
+			return &os.PathError{Op: "WriteSecret", Path: path, Err: err}
+		}
+	}
 	client, err := c.vaultClient()
 	if err != nil {
 		return err
 	}
-	_, err = client.Write(path, data)
-	return err
+	if _, err := client.Write(path, data); err != nil {
+		return &os.PathError{Op: "WriteSecret", Path: path, Err: err}
+	}
+	return nil
+}
+
+// ReadSecretInto reads the secret at the specified path and decodes its data
+// into out, which must be a pointer to a struct or map. Struct fields are
+// matched to secret keys using their "mapstructure" tags. If no data is stored
+// at the path, ErrSecretNotFound is returned.
+func (c *Client) ReadSecretInto(path string, out interface{}, opts ...vault.DecodeOption) error {
+	data, err := c.ReadSecret(path)
+	if err != nil {
+		return err
+	}
+	if err := vault.DecodeData(data, out, opts...); err != nil {
+		return &os.PathError{Op: "ReadSecretInto", Path: path, Err: err}
+	}
+	return nil
+}
+
+// ExistsSecret reports whether a secret is stored at the specified path. An
+// error is returned only if the secret could not be read.
+func (c *Client) ExistsSecret(path string) (bool, error) {
+	_, err := c.ReadSecret(path)
+	if errors.Is(err, ErrSecretNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// WriteSecretFrom creates or updates the secret at the specified path with the
+// data encoded from in, which must be a struct or a map with string keys.
+// Struct fields are named using their "mapstructure" tags, and nested structs
+// are encoded into nested maps.
+func (c *Client) WriteSecretFrom(path string, in interface{}) error {
+	data, err := vault.EncodeData(in)
+	if err != nil {
+		return err
+	}
+	return c.WriteSecret(path, data)
+}
+
+// UnwrapSecret returns the data of the response-wrapped secret of the wrapping
+// token. If the wrapping token is empty, invalid or was already used,
+// ErrSecretNotFound is returned.
+//
+// See https://www.vaultproject.io/api-docs/system/wrapping-unwrap.
+func (c *Client) UnwrapSecret(wrappingToken string) (map[string]interface{}, error) {
+	client, err := c.vaultClient()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := vault.Unwrap(client, wrappingToken)
+	if err != nil {
+		return nil, err
+	}
+	return secret.Data, nil
 }
 
 // DeleteSecret deletes the secret at the specified path.
@@ -151,30 +367,176 @@ func (c *Client) DeleteSecret(path string) error {
 	if err != nil {
 		return err
 	}
-	_, err = client.Delete(path)
-	return err
+	if c.skipDryRun(Action{Op: "DeleteSecret", Path: path}) {
+		return nil
+	}
+	if _, err := client.Delete(path); err != nil {
+		return &os.PathError{Op: "DeleteSecret", Path: path, Err: err}
+	}
+	return nil
+}
+
+// DeleteSecretWithData deletes the secret at the specified path, sending data
+// as the request query parameters.
+//
+// See https://www.vaultproject.io/api/secret/kv/kv-v1#delete-secret.
+func (c *Client) DeleteSecretWithData(path string, data map[string][]string) error {
+	path, err := c.secretPath(path)
+	if err != nil {
+		return err
+	}
+	client, err := c.vaultClient()
+	if err != nil {
+		return err
+	}
+	if c.skipDryRun(Action{Op: "DeleteSecretWithData", Path: path}) {
+		return nil
+	}
+	if _, err := client.DeleteWithData(path, data); err != nil {
+		return &os.PathError{Op: "DeleteSecretWithData", Path: path, Err: err}
+	}
+	return nil
 }
 
 var pathJoin = path.Join
 
+// PolicyFor returns a Vault policy in HCL that grants the capabilities on the
+// secrets at the specified paths, which may use the policy "*" and "+"
+// wildcards, such as c.PolicyFor([]string{"read", "list"}, "apps/web/*").
+// Paths are joined with the mount like Path, but are not validated.
+func (c *Client) PolicyFor(capabilities []string, paths ...string) string {
+	rules := make([]vault.PolicyRule, len(paths))
+	for i, path := range paths {
+		rules[i] = vault.PolicyRule{
+			Path:         strings.TrimPrefix(pathJoin(c.mountPath, c.prefix, path), "/"),
+			Capabilities: capabilities,
+		}
+	}
+	return vault.FormatPolicy(rules...)
+}
+
+// Path returns the Vault API path that the Client reads and writes for the
+// secret at the specified path, such as "secret/apps/db", joined exactly as the
+// Client joins it, for logging or Vault policies. If the path is invalid, so
+// that every request for it fails, the empty string is returned.
+func (c *Client) Path(path string) string {
+	if c.mountErr != nil {
+		return ""
+	}
+	p, err := c.secretPath(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(p, "/")
+}
+
 func (c *Client) secretPath(path string) (string, error) {
 	if path == "" {
 		return "", errors.New("vault: secret path is empty")
 	}
-	if c.mountPath == "" {
-		c.mountPath = defaultMountPath
+	if err := checkSecretPath(path); err != nil {
+		return "", err
+	}
+	return pathJoin(c.mountPath, c.prefix, path), nil
+}
+
+// checkSecretPath rejects secret paths that could escape the mount they are
+// joined to, such as "../other-kv/foo".
+func checkSecretPath(path string) error {
+	if strings.HasPrefix(path, "/") {
+		return fmt.Errorf("%w %q: must not start with a slash", ErrInvalidSecretPath, path)
+	}
+	for _, seg := range strings.Split(path, "/") {
+		if seg == "." || seg == ".." {
+			return fmt.Errorf("%w %q: must not contain %q segments", ErrInvalidSecretPath, path, seg)
+		}
+	}
+	return nil
+}
+
+// cleanMountPath returns the mount path with a single leading slash, no
+// trailing slash and no repeated slashes, so the mount is joined with the
+// secret paths identically however it is spelled. Mount paths that are empty
+// or contain ".." segments are rejected.
+func cleanMountPath(mount string) (string, error) {
+	for _, seg := range strings.Split(mount, "/") {
+		if seg == ".." {
+			return "", fmt.Errorf("vault: invalid mount path %q", mount)
+		}
+	}
+	cleaned := strings.Trim(path.Clean("/"+mount), "/")
+	if cleaned == "" {
+		return "", fmt.Errorf("vault: invalid mount path %q", mount)
 	}
-	return pathJoin(c.mountPath, path), nil
+	return "/" + cleaned, nil
 }
 
+// vaultClient returns the Vault client used to make requests. Since every
+// request is made with it, it also reports an invalid mount path of the
+// Client.
 func (c *Client) vaultClient() (vault.LogicalClient, error) {
-	if c.client != nil {
-		return c.client, nil
+	if c.mountErr != nil {
+		return nil, c.mountErr
 	}
-	client, err := api.NewClient(api.DefaultConfig())
-	if err != nil {
-		return nil, err
+	return c.core.Logical(c.mountPath)
+}
+
+// ForMount returns a Client for the secrets engine mounted at the given path
+// with the same configuration as c, such as for a tenant-specific mount. The
+// returned Client shares the Vault client of c, so deriving a Client per
+// request does not create a new Vault client. Dry-run actions are recorded
+// separately for each Client.
+func (c *Client) ForMount(path string) *Client {
+	n := &Client{
+		mountPath:        path,
+		concurrency:      c.concurrency,
+		defaultMountPath: c.defaultMountPath,
+		core:             c.core,
+		validator:        c.validator,
+		rejectEmpty:      c.rejectEmpty,
+		dryRun:           c.dryRun,
+	}
+	if n.mountPath == "" {
+		n.mountPath = n.defaultMountPath
+	}
+	n.mountPath, n.mountErr = cleanMountPath(n.mountPath)
+	return n
+}
+
+// Sub returns a Client for the secrets under the given prefix of the mount of
+// c, such as a service-specific subtree, with the same configuration as c.
+// Secret paths of the returned Client are relative to the prefix, so
+// sub.ReadSecret("db") reads the "apps/myservice/db" secret of the mount:
+//
+//    sub := c.Sub("apps/myservice")
+//
+// Like ForMount, the returned Client shares the Vault client of c. A prefix
+// that starts with a slash or contains "." or ".." segments is rejected with
+// ErrInvalidSecretPath by every request of the returned Client.
+func (c *Client) Sub(prefix string) *Client {
+	n := c.ForMount(c.mountPath)
+	n.mountErr = c.mountErr
+	if n.mountErr == nil {
+		n.mountErr = checkSecretPath(prefix)
+	}
+	n.prefix = pathJoin(c.prefix, prefix)
+	return n
+}
+
+// Close closes the idle connections of the HTTP client the Client makes
+// requests with, if the Client owns it: the client set with WithHTTPClient or
+// the default HTTP client of a Vault client created by the Client. Clients set
+// with WithLogicalClient or WithAPIClient are owned by the caller and left
+// unchanged. Close is safe to call on a nil Client and more than once, and the
+// Client remains usable afterwards, opening new connections as needed.
+//
+// Close does not create the Vault client, so it does nothing before the first
+// request. Clients returned by ForMount and Sub share the HTTP client of c,
+// so Close also closes their idle connections.
+func (c *Client) Close() error {
+	if c == nil {
+		return nil
 	}
-	c.client = client.Logical()
-	return c.client, nil
+	c.core.Close()
+	return nil
 }
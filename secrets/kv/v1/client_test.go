@@ -0,0 +1,446 @@
+package kv_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/vault/api"
+	"github.com/mwalto7/vault"
+	kv "github.com/mwalto7/vault/secrets/kv/v1"
+	"github.com/mwalto7/vault/vaultmock"
+)
+
+func TestClient_ReadSecret(t *testing.T) {
+	tt := []struct {
+		name   string
+		path   string
+		secret *api.Secret
+		data   map[string]interface{}
+		err    error
+	}{
+		{
+			name:   "ErrSecretNotFound",
+			path:   "test",
+			secret: nil,
+			err:    kv.ErrSecretNotFound,
+		},
+		{
+			name:   "ErrNoSecretData",
+			path:   "test",
+			secret: &api.Secret{},
+			err:    kv.ErrSecretNotFound,
+		},
+		{
+			name:   "OK",
+			path:   "test",
+			secret: &api.Secret{Data: map[string]interface{}{"foo": "bar"}},
+			data:   map[string]interface{}{"foo": "bar"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().Read("/secret/"+tc.path).Return(tc.secret, nil)
+
+			data, err := kv.NewClient("", kv.WithLogicalClient(m)).ReadSecret(tc.path)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("err: got %v, want %v", err, tc.err)
+			}
+			if tc.err != nil {
+				var pathErr *os.PathError
+				if !errors.As(err, &pathErr) {
+					t.Fatalf("err: got %T, want *os.PathError", err)
+				}
+				if !errors.Is(err, vault.ErrSecretNotFound) {
+					t.Fatalf("err: got %v, want %v", err, vault.ErrSecretNotFound)
+				}
+			}
+			if !reflect.DeepEqual(data, tc.data) {
+				t.Fatalf("data: got %v, want %v", data, tc.data)
+			}
+		})
+	}
+}
+
+func TestClient_ReadRaw(t *testing.T) {
+	want := &api.Secret{
+		RequestID: "abc",
+		LeaseID:   "lease",
+		Warnings:  []string{"warning"},
+		Data:      map[string]interface{}{"foo": "bar"},
+	}
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Read("/secret/test").Return(want, nil)
+
+	secret, err := kv.NewClient("", kv.WithLogicalClient(m)).ReadRaw("test")
+	if err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if secret != want {
+		t.Fatalf("secret: got %+v, want %+v", secret, want)
+	}
+}
+
+func TestNewClient_WithDefaultMountPath(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Write("/kv/test", map[string]interface{}{"foo": "bar"}).Return(nil, nil)
+
+	c := kv.NewClient("", kv.WithLogicalClient(m), kv.WithDefaultMountPath("/kv"))
+	if err := c.WriteSecret("test", map[string]interface{}{"foo": "bar"}); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+}
+
+func TestClient_PathErrors(t *testing.T) {
+	want := errors.New("error")
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Read("/secret/test").Return(nil, want)
+	m.EXPECT().List("/secret/test").Return(nil, want)
+	m.EXPECT().Write("/secret/test", gomock.Any()).Return(nil, want)
+	m.EXPECT().Delete("/secret/test").Return(nil, want)
+	m.EXPECT().DeleteWithData("/secret/test", map[string][]string{"foo": {"bar"}}).Return(nil, want)
+
+	c := kv.NewClient("", kv.WithLogicalClient(m))
+	calls := map[string]func() error{
+		"ReadSecret": func() error {
+			_, err := c.ReadSecret("test")
+			return err
+		},
+		"ListSecrets": func() error {
+			_, err := c.ListSecrets("test")
+			return err
+		},
+		"WriteSecret": func() error {
+			return c.WriteSecret("test", map[string]interface{}{"foo": "bar"})
+		},
+		"DeleteSecret": func() error {
+			return c.DeleteSecret("test")
+		},
+		"DeleteSecretWithData": func() error {
+			return c.DeleteSecretWithData("test", map[string][]string{"foo": {"bar"}})
+		},
+	}
+	for op, call := range calls {
+		err := call()
+		var pathErr *os.PathError
+		if !errors.As(err, &pathErr) {
+			t.Fatalf("%s: got %v, want *os.PathError", op, err)
+		}
+		if pathErr.Op != op || pathErr.Path != "/secret/test" {
+			t.Fatalf("%s: got op %q and path %q", op, pathErr.Op, pathErr.Path)
+		}
+		if !errors.Is(err, want) {
+			t.Fatalf("%s: got %v, want %v", op, err, want)
+		}
+	}
+}
+
+func TestNewClient_MountPath(t *testing.T) {
+	for _, mount := range []string{"my-kv", "/my-kv", "my-kv/", "//my-kv//"} {
+		m := vaultmock.NewLogicalClient(gomock.NewController(t))
+		m.EXPECT().Read("/my-kv/test").Return(&api.Secret{Data: map[string]interface{}{"foo": "bar"}}, nil)
+		if _, err := kv.NewClient(mount, kv.WithLogicalClient(m)).ReadSecret("test"); err != nil {
+			t.Fatalf("mount %q: err: got %v, want nil", mount, err)
+		}
+	}
+	if _, err := kv.NewClient("my-kv/../other").ReadSecret("test"); err == nil {
+		t.Fatal("err: got nil, want error for mount containing \"..\"")
+	}
+}
+
+func TestClient_InvalidSecretPath(t *testing.T) {
+	c := kv.NewClient("", kv.WithLogicalClient(vaultmock.NewLogicalClient(gomock.NewController(t))))
+	for _, path := range []string{"../other-kv/foo", "foo/../../bar", "./foo", "/foo"} {
+		if _, err := c.ReadSecret(path); !errors.Is(err, kv.ErrInvalidSecretPath) {
+			t.Errorf("ReadSecret(%q): err: got %v, want %v", path, err, kv.ErrInvalidSecretPath)
+		}
+	}
+}
+
+func TestClient_Walk(t *testing.T) {
+	list := func(keys ...interface{}) *api.Secret {
+		return &api.Secret{Data: map[string]interface{}{"keys": keys}}
+	}
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().List("/secret").Return(list("apps", "apps/", "root"), nil)
+	m.EXPECT().List("/secret/apps").Return(list("web/", "worker"), nil)
+	m.EXPECT().List("/secret/apps/web").Return(list("db"), nil)
+
+	var paths []string
+	err := kv.NewClient("", kv.WithLogicalClient(m)).Walk("", func(path string) error {
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if want := []string{"apps", "apps/web/db", "apps/worker", "root"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("paths: got %v, want %v", paths, want)
+	}
+}
+
+func TestClient_ListSecretsFunc(t *testing.T) {
+	errStop := errors.New("stop")
+	tt := []struct {
+		name   string
+		secret *api.Secret
+		stopAt string
+		keys   []string
+		err    error
+		// wantErr is set if an error other than err is returned.
+		wantErr bool
+	}{
+		{
+			name:   "All",
+			secret: &api.Secret{Data: map[string]interface{}{"keys": []interface{}{"a", "b/", "c"}}},
+			keys:   []string{"a", "b/", "c"},
+		},
+		{
+			name:   "StopEarly",
+			secret: &api.Secret{Data: map[string]interface{}{"keys": []interface{}{"a", "b/", "c"}}},
+			stopAt: "b/",
+			keys:   []string{"a", "b/"},
+			err:    errStop,
+		},
+		{
+			name:   "StringSlice",
+			secret: &api.Secret{Data: map[string]interface{}{"keys": []string{"a", "b/"}}},
+			keys:   []string{"a", "b/"},
+		},
+		{
+			name:    "InvalidKey",
+			secret:  &api.Secret{Data: map[string]interface{}{"keys": []interface{}{"a", map[string]interface{}{}, "c"}}},
+			keys:    []string{"a"},
+			wantErr: true,
+		},
+		{name: "Empty"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().List("/secret/test").Return(tc.secret, nil)
+
+			var keys []string
+			err := kv.NewClient("", kv.WithLogicalClient(m)).ListSecretsFunc("test", func(key string) error {
+				keys = append(keys, key)
+				if key == tc.stopAt {
+					return errStop
+				}
+				return nil
+			})
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("err: got nil, want error")
+				}
+			} else if !errors.Is(err, tc.err) {
+				t.Fatalf("err: got %v, want %v", err, tc.err)
+			}
+			if !reflect.DeepEqual(keys, tc.keys) {
+				t.Fatalf("keys: got %v, want %v", keys, tc.keys)
+			}
+		})
+	}
+}
+
+func TestClient_ConcurrentFirstUse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"foo":"bar"}}`)
+	}))
+	defer srv.Close()
+	setenv(t, "VAULT_ADDR", srv.URL)
+
+	c := kv.NewClient("")
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.ReadSecret("test"); err != nil {
+				t.Errorf("err: got %v, want nil", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func setenv(t *testing.T, key, value string) {
+	t.Helper()
+	prev, ok := os.LookupEnv(key)
+	if err := os.Setenv(key, value); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, prev)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+func TestClient_Sub(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	gomock.InOrder(
+		m.EXPECT().Write("/secret/apps/myservice/db", gomock.Any()).Return(nil, nil),
+		m.EXPECT().List("/secret/apps/myservice").Return(&api.Secret{Data: map[string]interface{}{
+			"keys": []interface{}{"db"},
+		}}, nil),
+	)
+
+	sub := kv.NewClient("", kv.WithLogicalClient(m)).Sub("apps/myservice")
+	if err := sub.WriteSecret("db", map[string]interface{}{"password": "hunter2"}); err != nil {
+		t.Fatalf("WriteSecret: err: got %v, want nil", err)
+	}
+	var paths []string
+	if err := sub.Walk("", func(path string) error {
+		paths = append(paths, path)
+		return nil
+	}); err != nil {
+		t.Fatalf("Walk: err: got %v, want nil", err)
+	}
+	if want := []string{"db"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("Walk: got %v, want %v", paths, want)
+	}
+	if _, err := sub.Sub("/other").ReadSecret("db"); !errors.Is(err, kv.ErrInvalidSecretPath) {
+		t.Fatalf("invalid prefix: err: got %v, want %v", err, kv.ErrInvalidSecretPath)
+	}
+}
+
+func TestClient_ReadSecretWithLease(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Read("/secret/test").Return(&api.Secret{
+		LeaseDuration: 3600,
+		Data:          map[string]interface{}{"foo": "bar", "ttl": "1h"},
+	}, nil)
+	m.EXPECT().Read("/secret/missing").Return(nil, nil)
+
+	c := kv.NewClient("", kv.WithLogicalClient(m))
+	data, lease, err := c.ReadSecretWithLease("test")
+	if err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if data["foo"] != "bar" {
+		t.Fatalf("data: got %v, want foo=bar", data)
+	}
+	if want := (vault.Lease{Duration: time.Hour}); lease != want {
+		t.Fatalf("lease: got %+v, want %+v", lease, want)
+	}
+	if _, _, err := c.ReadSecretWithLease("missing"); !errors.Is(err, kv.ErrSecretNotFound) {
+		t.Fatalf("missing: err: got %v, want %v", err, kv.ErrSecretNotFound)
+	}
+}
+
+func TestClient_Path(t *testing.T) {
+	tt := []struct {
+		name   string
+		client *kv.Client
+		path   string
+		want   string
+	}{
+		{name: "DefaultMount", client: kv.NewClient(""), path: "apps/db", want: "secret/apps/db"},
+		{name: "CustomMount", client: kv.NewClient("/teams/kv/"), path: "db", want: "teams/kv/db"},
+		{name: "Sub", client: kv.NewClient("").Sub("apps"), path: "db", want: "secret/apps/db"},
+		{name: "InvalidPath", client: kv.NewClient(""), path: "/db"},
+		{name: "EmptyPath", client: kv.NewClient(""), path: ""},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.client.Path(tc.path); got != tc.want {
+				t.Fatalf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDefaultClient_MountPathEnv(t *testing.T) {
+	// The DefaultClient reads the environment when the package is
+	// initialized, so each case runs the test in a subprocess.
+	const wantEnv = "KV1_TEST_DEFAULT_CLIENT_PATH"
+	if want, ok := os.LookupEnv(wantEnv); ok {
+		if got := kv.DefaultClient.Path("db"); got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+		return
+	}
+
+	tt := []struct {
+		name string
+		env  []string
+		want string
+	}{
+		{name: "Default", want: "secret/db"},
+		{name: "Override", env: []string{kv.MountPathEnv + "=teams/kv"}, want: "teams/kv/db"},
+		{name: "KVv2Override", env: []string{"VAULT_KV2_MOUNT=teams/kv"}, want: "secret/db"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := exec.Command(os.Args[0], "-test.run=^TestDefaultClient_MountPathEnv$")
+			for _, e := range os.Environ() {
+				if !strings.HasPrefix(e, "VAULT_KV") {
+					cmd.Env = append(cmd.Env, e)
+				}
+			}
+			cmd.Env = append(cmd.Env, wantEnv+"="+tc.want)
+			cmd.Env = append(cmd.Env, tc.env...)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("err: got %v, want nil\n%s", err, out)
+			}
+		})
+	}
+}
+
+func TestNewClient_WithRejectEmptyWrites(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Write("/secret/test", map[string]interface{}{"foo": "bar"}).Return(nil, nil)
+
+	c := kv.NewClient("", kv.WithLogicalClient(m), kv.WithRejectEmptyWrites(true))
+	if err := c.WriteSecret("test", nil); !errors.Is(err, kv.ErrEmptyData) {
+		t.Fatalf("empty write: err: got %v, want %v", err, kv.ErrEmptyData)
+	}
+	if err := c.WriteSecret("test", map[string]interface{}{"foo": "bar"}); err != nil {
+		t.Fatalf("write: err: got %v, want nil", err)
+	}
+}
+
+func TestClient_WriteSecrets_StopsAtFirstFailure(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	gomock.InOrder(
+		m.EXPECT().Read("/secret/a").Return(nil, nil),
+		m.EXPECT().Write("/secret/a", gomock.Any()).Return(nil, nil),
+		m.EXPECT().Read("/secret/b").Return(&api.Secret{Data: map[string]interface{}{"foo": "old"}}, nil),
+		m.EXPECT().Write("/secret/b", gomock.Any()).Return(nil, errors.New("permission denied")),
+		m.EXPECT().Delete("/secret/a").Return(nil, nil),
+	)
+
+	c := kv.NewClient("", kv.WithLogicalClient(m), kv.WithConcurrency(1))
+	err := c.WriteSecrets(map[string]map[string]interface{}{
+		"a": {"foo": "bar"},
+		"b": {"foo": "bar"},
+		"c": {"foo": "bar"},
+	}, kv.AllOrNothing())
+	var batchErr *vault.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("err: got %v, want *vault.BatchError", err)
+	}
+	if got := batchErr.Paths(); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Fatalf("failed paths: got %v, want [b]", got)
+	}
+	if len(batchErr.Succeeded) != 0 {
+		t.Fatalf("succeeded: got %v, want none", batchErr.Succeeded)
+	}
+}
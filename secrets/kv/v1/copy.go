@@ -0,0 +1,52 @@
+package kv
+
+import "fmt"
+
+// CopySecret copies the secret at the src path to the dst path using the
+// DefaultClient.
+func CopySecret(src, dst string) error {
+	return DefaultClient.CopySecret(src, dst)
+}
+
+// CopySecretTo copies the secret at the src path using the DefaultClient to the
+// dst path using the dstClient.
+func CopySecretTo(dstClient *Client, src, dst string) error {
+	return DefaultClient.CopySecretTo(dstClient, src, dst)
+}
+
+// MoveSecret moves the secret at the src path to the dst path using the
+// DefaultClient.
+func MoveSecret(src, dst string) error {
+	return DefaultClient.MoveSecret(src, dst)
+}
+
+// CopySecret copies the secret at the src path to the dst path. See
+// CopySecretTo for details.
+func (c *Client) CopySecret(src, dst string) error {
+	return c.CopySecretTo(c, src, dst)
+}
+
+// CopySecretTo copies the secret at the src path to the dst path of dstClient,
+// which may use a different mount or Vault server. If the source secret does
+// not exist, ErrSecretNotFound is returned. Existing data at the dst path is
+// overwritten, since KVv1 secrets are not versioned.
+func (c *Client) CopySecretTo(dstClient *Client, src, dst string) error {
+	data, err := c.ReadSecret(src)
+	if err != nil {
+		return err
+	}
+	return dstClient.WriteSecret(dst, data)
+}
+
+// MoveSecret copies the secret at the src path to the dst path, then deletes
+// the source secret. The source is deleted only after the copy succeeds. If the
+// copy fails, the source is left intact and the copy error is returned.
+func (c *Client) MoveSecret(src, dst string) error {
+	if err := c.CopySecret(src, dst); err != nil {
+		return err
+	}
+	if err := c.DeleteSecret(src); err != nil {
+		return fmt.Errorf("vault: secret copied to %q but source not deleted: %w", dst, err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package kv
+
+// Action is a destructive request that a Client in dry-run mode skipped.
+type Action struct {
+	// The Client method that would have made the request.
+	Op string
+
+	// The Vault API path of the request.
+	Path string
+}
+
+// PlannedActions returns the destructive requests skipped by the Client in
+// dry-run mode, in the order the operations were called.
+func (c *Client) PlannedActions() []Action {
+	c.dryRunMu.Lock()
+	defer c.dryRunMu.Unlock()
+	return append([]Action(nil), c.planned...)
+}
+
+// ClearPlannedActions discards the planned actions recorded by the Client.
+func (c *Client) ClearPlannedActions() {
+	c.dryRunMu.Lock()
+	c.planned = nil
+	c.dryRunMu.Unlock()
+}
+
+// skipDryRun records the action and reports whether it must be skipped
+// because the Client is in dry-run mode.
+func (c *Client) skipDryRun(a Action) bool {
+	if !c.dryRun {
+		return false
+	}
+	if c.core.Logger != nil {
+		c.core.Logger.Info("dry run: skipped destructive request", "op", a.Op, "path", a.Path)
+	}
+	c.dryRunMu.Lock()
+	c.planned = append(c.planned, a)
+	c.dryRunMu.Unlock()
+	return true
+}
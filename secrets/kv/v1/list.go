@@ -0,0 +1,80 @@
+package kv
+
+import (
+	"github.com/mitchellh/mapstructure"
+	"github.com/mwalto7/vault"
+)
+
+// ListSecretsSorted lists the secret keys at the specified path in a stable
+// order using the DefaultClient.
+func ListSecretsSorted(path string) ([]string, error) {
+	return DefaultClient.ListSecretsSorted(path)
+}
+
+// ListSecretsFunc calls fn for each secret key at the specified path using the
+// DefaultClient.
+//
+// See https://www.vaultproject.io/api/secret/kv/kv-v1#list-secrets.
+func ListSecretsFunc(path string, fn func(key string) error) error {
+	return DefaultClient.ListSecretsFunc(path, fn)
+}
+
+// ListSecretsFunc calls fn for each secret key at the specified path, in the
+// order returned by Vault. Keys ending in a slash are folders. If fn returns
+// an error, listing stops and the error is returned.
+//
+// Vault returns all keys in a single response, but each key is decoded like
+// ListSecrets only when fn is called for it, so no key slice is built and fn
+// can stop listing early. If a key cannot be decoded, listing stops with the
+// error ListSecrets would return.
+//
+// See https://www.vaultproject.io/api/secret/kv/kv-v1#list-secrets.
+func (c *Client) ListSecretsFunc(path string, fn func(key string) error) error {
+	path, err := c.secretPath(path)
+	if err != nil {
+		return err
+	}
+	data, err := c.listData("ListSecretsFunc", path)
+	if err != nil || data == nil {
+		return err
+	}
+	raw, ok := data["keys"].([]interface{})
+	if !ok {
+		// The keys are not in the []interface{} of a JSON response, such as
+		// for a LogicalClient that returns them as a []string.
+		keys, err := decodeKeys(data)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if err := fn(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, v := range raw {
+		var key string
+		if err := mapstructure.Decode(v, &key); err != nil {
+			_, err = decodeKeys(data)
+			return err
+		}
+		if err := fn(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListSecretsSorted lists the secret keys at the specified path like
+// ListSecrets, without duplicates and in a stable order: the folders first and
+// then the secrets, each sorted lexically. See vault.SortKeys.
+//
+// See https://www.vaultproject.io/api/secret/kv/kv-v1#list-secrets.
+func (c *Client) ListSecretsSorted(path string) ([]string, error) {
+	keys, err := c.ListSecrets(path)
+	if err != nil || keys == nil {
+		return keys, err
+	}
+	return vault.SortKeys(keys), nil
+}
@@ -0,0 +1,38 @@
+package kv
+
+import (
+	"os"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// ReadRaw reads the secret at the specified path using the DefaultClient and
+// returns the Vault response untouched.
+func ReadRaw(path string) (*api.Secret, error) {
+	return DefaultClient.ReadRaw(path)
+}
+
+// ReadRaw reads the secret at the specified path and returns the Vault
+// response untouched, for access to the fields that ReadSecret does not
+// return, such as the request ID, lease and warnings.
+//
+// The response data is the secret data itself, unlike the KVv2 response data,
+// which nests the secret data and version metadata under the "data" and
+// "metadata" keys. If no secret is stored at the path, nil is returned.
+//
+// See https://www.vaultproject.io/api/secret/kv/kv-v1#read-secret.
+func (c *Client) ReadRaw(path string) (*api.Secret, error) {
+	path, err := c.secretPath(path)
+	if err != nil {
+		return nil, err
+	}
+	client, err := c.vaultClient()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := client.Read(path)
+	if err != nil {
+		return nil, &os.PathError{Op: "ReadRaw", Path: path, Err: err}
+	}
+	return secret, nil
+}
@@ -0,0 +1,28 @@
+package kv
+
+import "github.com/mwalto7/vault"
+
+var _ vault.SecretStore = (*Client)(nil)
+
+// Read reads the secret at the specified path. It is equivalent to ReadSecret.
+func (c *Client) Read(path string) (map[string]interface{}, error) {
+	return c.ReadSecret(path)
+}
+
+// Write creates or updates the secret at the specified path. It is equivalent
+// to WriteSecret.
+func (c *Client) Write(path string, data map[string]interface{}) error {
+	return c.WriteSecret(path, data)
+}
+
+// Delete deletes the secret at the specified path. It is equivalent to
+// DeleteSecret.
+func (c *Client) Delete(path string) error {
+	return c.DeleteSecret(path)
+}
+
+// List lists the secret keys at the specified path. It is equivalent to
+// ListSecrets.
+func (c *Client) List(path string) ([]string, error) {
+	return c.ListSecrets(path)
+}
@@ -0,0 +1,88 @@
+package kv
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/mwalto7/vault"
+)
+
+// ReadSecretWrapped reads the secret at the specified path using the
+// DefaultClient and returns a response-wrapping token for it.
+func ReadSecretWrapped(path string, ttl time.Duration) (string, error) {
+	return DefaultClient.ReadSecretWrapped(path, ttl)
+}
+
+// ReadSecretWrapped reads the secret at the specified path and returns
+// a single-use response-wrapping token valid for the given TTL, instead of the
+// secret data. The data can be retrieved with UnwrapSecret.
+//
+// Response wrapping requires the Client to create its own Vault client, so it
+// is not supported for a client set with WithLogicalClient.
+//
+// See https://www.vaultproject.io/docs/concepts/response-wrapping.
+func (c *Client) ReadSecretWrapped(path string, ttl time.Duration) (string, error) {
+	info, err := c.readWrapped("ReadSecretWrapped", path, ttl)
+	return info.Token, err
+}
+
+// ReadSecretWrappedInfo reads the secret at the specified path using the
+// DefaultClient and returns the response-wrapping information for it.
+func ReadSecretWrappedInfo(path string, ttl time.Duration) (vault.WrapInfo, error) {
+	return DefaultClient.ReadSecretWrappedInfo(path, ttl)
+}
+
+// ReadSecretWrappedInfo is like ReadSecretWrapped, but returns the full
+// response-wrapping information, including the accessor, TTL and creation
+// time of the wrapping token, instead of only the token.
+func (c *Client) ReadSecretWrappedInfo(path string, ttl time.Duration) (vault.WrapInfo, error) {
+	return c.readWrapped("ReadSecretWrappedInfo", path, ttl)
+}
+
+func (c *Client) readWrapped(op, path string, ttl time.Duration) (vault.WrapInfo, error) {
+	path, err := c.secretPath(path)
+	if err != nil {
+		return vault.WrapInfo{}, err
+	}
+	client, err := c.wrappingClient(ttl)
+	if err != nil {
+		return vault.WrapInfo{}, err
+	}
+	secret, err := client.Read(path)
+	if err != nil {
+		return vault.WrapInfo{}, &os.PathError{Op: op, Path: path, Err: err}
+	}
+	info := vault.WrapInfoOf(secret)
+	if info.Token == "" {
+		return vault.WrapInfo{}, &os.PathError{Op: op, Path: path, Err: errors.New("vault: response was not wrapped")}
+	}
+	return info, nil
+}
+
+// wrappingClient returns a clone of the Vault client that wraps every
+// response with the given TTL.
+func (c *Client) wrappingClient(ttl time.Duration) (vault.LogicalClient, error) {
+	if ttl <= 0 {
+		return nil, errors.New("vault: wrapping TTL must be positive")
+	}
+	if _, err := c.vaultClient(); err != nil {
+		return nil, err
+	}
+	apiClient, err := c.core.API()
+	if err != nil {
+		return nil, err
+	}
+	if apiClient == nil {
+		return nil, errors.New("vault: response wrapping requires a Vault API client")
+	}
+	client, err := apiClient.CloneWithHeaders()
+	if err != nil {
+		return nil, err
+	}
+	client.SetToken(apiClient.Token())
+	client.SetWrappingLookupFunc(func(operation, path string) string {
+		return ttl.String()
+	})
+	return c.core.Wrap(client.Logical(), c.mountPath), nil
+}
@@ -0,0 +1,96 @@
+package kv
+
+import (
+	"errors"
+
+	"github.com/mwalto7/vault"
+)
+
+// GetString returns the string value of the key in the secret data. See
+// vault.GetString.
+func (s Secret) GetString(key string) (string, bool) {
+	return vault.GetString(s.Data, key)
+}
+
+// GetInt returns the integer value of the key in the secret data, converting
+// json.Numbers and numeric strings. See vault.GetInt.
+func (s Secret) GetInt(key string) (int, bool) {
+	return vault.GetInt(s.Data, key)
+}
+
+// GetFloat returns the floating-point value of the key in the secret data,
+// converting json.Numbers and numeric strings. See vault.GetFloat.
+func (s Secret) GetFloat(key string) (float64, bool) {
+	return vault.GetFloat(s.Data, key)
+}
+
+// GetBool returns the boolean value of the key in the secret data, converting
+// strings such as "true". See vault.GetBool.
+func (s Secret) GetBool(key string) (bool, bool) {
+	return vault.GetBool(s.Data, key)
+}
+
+// GetBytes returns the base64-decoded value of the key in the secret data. See
+// vault.GetBytes.
+func (s Secret) GetBytes(key string) ([]byte, error) {
+	return vault.GetBytes(s.Data, key)
+}
+
+// GetPath returns the value at the dotted path in the nested secret data, such
+// as "db.password" or "hosts.0". See vault.GetPath.
+func (s Secret) GetPath(path string) (interface{}, bool) {
+	return vault.GetPath(s.Data, path)
+}
+
+// WriteSecretBinary creates or updates the latest secret version at the
+// specified path with the base64-encoded binary data using the DefaultClient.
+func WriteSecretBinary(path string, data map[string][]byte) (SecretVersion, error) {
+	return DefaultClient.WriteSecretBinary(path, data)
+}
+
+// WriteSecretBinary creates or updates the latest secret version at the
+// specified path with each value of data encoded as a standard base64 string.
+// The values can be decoded with Secret.GetBytes.
+func (c *Client) WriteSecretBinary(path string, data map[string][]byte) (SecretVersion, error) {
+	return c.WriteSecretLatest(path, vault.EncodeBinary(data))
+}
+
+// EqualData reports whether the secret data equals other as Vault stores it.
+// See vault.EqualData.
+func (s Secret) EqualData(other map[string]interface{}) bool {
+	return vault.EqualData(s.Data, other)
+}
+
+// CloneData returns a deep copy of the secret data. See vault.CloneData.
+func (s Secret) CloneData() map[string]interface{} {
+	return vault.CloneData(s.Data)
+}
+
+// WriteSecretIfChanged writes the data as the latest secret version at the
+// specified path, unless it equals the latest version, using the
+// DefaultClient.
+func WriteSecretIfChanged(path string, data map[string]interface{}) (SecretVersion, bool, error) {
+	return DefaultClient.WriteSecretIfChanged(path, data)
+}
+
+// WriteSecretIfChanged writes the data as the latest secret version at the
+// specified path, unless it equals the data of the latest version, so
+// unchanged writes do not create new versions. It reports whether the secret
+// was written; if not, the metadata of the latest version is returned.
+//
+// The write is made without a check-and-set version, so a concurrent write
+// between the read and the write is overwritten.
+func (c *Client) WriteSecretIfChanged(path string, data map[string]interface{}) (SecretVersion, bool, error) {
+	cur, err := c.ReadSecretLatest(path)
+	if err != nil && !errors.Is(err, ErrSecretNotFound) {
+		return SecretVersion{}, false, err
+	}
+	if err == nil && cur.EqualData(data) {
+		return cur.Metadata, false, nil
+	}
+	v, err := c.WriteSecretLatest(path, data)
+	if err != nil {
+		return SecretVersion{}, false, err
+	}
+	return v, true, nil
+}
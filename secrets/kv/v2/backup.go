@@ -0,0 +1,137 @@
+package kv
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// backupExt is the file extension of the secrets in a backup tarball. It keeps
+// a secret and a folder of the same name, such as "foo" and "foo/bar", from
+// colliding when the tarball is extracted.
+const backupExt = ".json"
+
+// backupEntry is a secret stored in a backup tarball.
+type backupEntry struct {
+	Secret         Secret            `json:"secret"`
+	CustomMetadata map[string]string `json:"custom_metadata,omitempty"`
+}
+
+// BackupTree writes every secret under the root path to w as a tarball using
+// the DefaultClient.
+func BackupTree(root string, w io.Writer) error {
+	return DefaultClient.BackupTree(root, w)
+}
+
+// RestoreTree writes every secret in the tarball read from r under the root
+// path using the DefaultClient.
+func RestoreTree(root string, r io.Reader) error {
+	return DefaultClient.RestoreTree(root, r)
+}
+
+// BackupTree writes the latest version and custom metadata of every secret
+// under the root path to w as a tar stream. Each secret is stored as a JSON
+// file named after its path relative to the root, with a ".json" extension,
+// so "app/db" under the root is stored as "app/db.json". Secrets are read and
+// written one at a time, so the tree is never held in memory.
+//
+// Secrets whose latest version is deleted or destroyed have no data to back
+// up and are skipped.
+func (c *Client) BackupTree(root string, w io.Writer) error {
+	root = strings.Trim(root, "/")
+	tw := tar.NewWriter(w)
+	err := c.Walk(root, func(path string) error {
+		secret, err := c.ReadSecretLatest(path)
+		if errors.Is(err, ErrSecretNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		md, err := c.ReadSecretMetadata(path)
+		if err != nil {
+			return err
+		}
+		b, err := json.Marshal(backupEntry{Secret: secret, CustomMetadata: md.CustomMetadata})
+		if err != nil {
+			return err
+		}
+		hdr := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     relPath(root, path) + backupExt,
+			Mode:     0600,
+			Size:     int64(len(b)),
+			ModTime:  secret.Metadata.CreatedTime,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err = tw.Write(b)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// RestoreTree reads a tar stream written by BackupTree from r and writes each
+// secret in it as the latest version at its path under the root path, along
+// with its custom metadata. Secrets are restored one at a time as they are
+// read. Files without a ".json" extension are ignored.
+//
+// Restoring stops at the first error, so the secrets before it remain
+// written.
+func (c *Client) RestoreTree(root string, r io.Reader) error {
+	root = strings.Trim(root, "/")
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg || !strings.HasSuffix(hdr.Name, backupExt) {
+			continue
+		}
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		var entry backupEntry
+		if err := json.Unmarshal(b, &entry); err != nil {
+			return fmt.Errorf("kv2: decoding backup of %q: %w", hdr.Name, err)
+		}
+		// The name is checked before it is joined to the root, so it cannot
+		// escape the root with ".." segments.
+		path := strings.TrimSuffix(hdr.Name, backupExt)
+		if err := checkSecretPath(path); err != nil {
+			return err
+		}
+		if root != "" {
+			path = root + "/" + path
+		}
+		if _, err := c.WriteSecretLatest(path, entry.Secret.Data); err != nil {
+			return err
+		}
+		if len(entry.CustomMetadata) > 0 {
+			if err := c.WriteSecretMetadata(path, SecretConfig{CustomMetadata: entry.CustomMetadata}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// relPath returns the path relative to the root path.
+func relPath(root, path string) string {
+	if root == "" {
+		return path
+	}
+	return strings.TrimPrefix(path, root+"/")
+}
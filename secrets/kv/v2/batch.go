@@ -0,0 +1,305 @@
+package kv
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mwalto7/vault"
+	"golang.org/x/sync/errgroup"
+)
+
+// ReadSecrets reads the latest secret version at each of the specified paths
+// using the DefaultClient.
+func ReadSecrets(paths []string) (map[string]Secret, error) {
+	return DefaultClient.ReadSecrets(paths)
+}
+
+// ReadSecrets reads the latest secret version at each of the specified paths
+// concurrently, making at most as many concurrent requests as configured with
+// WithConcurrency. The returned map is keyed by path.
+//
+// If reading some of the paths fails, the secrets that were read are returned
+// along with a *vault.BatchError reporting the error of every failed path.
+func (c *Client) ReadSecrets(paths []string) (map[string]Secret, error) {
+	if _, err := c.vaultClient(); err != nil {
+		return nil, err
+	}
+	var (
+		mu      sync.Mutex
+		secrets = make(map[string]Secret, len(paths))
+		errs    = make(map[string]error)
+	)
+	c.forEach(paths, func(path string) {
+		secret, err := c.ReadSecretLatest(path)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs[path] = err
+			return
+		}
+		secrets[path] = secret
+	})
+	if len(errs) > 0 {
+		return secrets, &vault.BatchError{Errors: errs}
+	}
+	return secrets, nil
+}
+
+// ReadSecretVersions reads each of the specified secret versions at the path
+// using the DefaultClient.
+func ReadSecretVersions(path string, versions ...int) (map[int]Secret, error) {
+	return DefaultClient.ReadSecretVersions(path, versions...)
+}
+
+// ReadSecretVersions reads each of the specified secret versions at the path
+// concurrently, making at most as many concurrent requests as configured with
+// WithConcurrency. The returned map is keyed by version number. Must specify
+// at least one version.
+//
+// If reading some of the versions fails, such as deleted or destroyed versions
+// that return ErrSecretNotFound, the versions that were read are returned
+// along with a *vault.BatchError reporting the error of every failed version,
+// keyed by version number.
+func (c *Client) ReadSecretVersions(path string, versions ...int) (map[int]Secret, error) {
+	if len(versions) == 0 {
+		return nil, ErrNoVersions
+	}
+	if _, err := c.secretPath(path, false); err != nil {
+		return nil, err
+	}
+	if _, err := c.vaultClient(); err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(versions))
+	for i, v := range versions {
+		keys[i] = strconv.Itoa(v)
+	}
+	var (
+		mu      sync.Mutex
+		secrets = make(map[int]Secret, len(versions))
+		errs    = make(map[string]error)
+	)
+	c.forEach(keys, func(key string) {
+		version, _ := strconv.Atoi(key)
+		secret, err := c.ReadSecretVersion(path, version)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs[key] = err
+			return
+		}
+		secrets[version] = secret
+	})
+	if len(errs) > 0 {
+		return secrets, &vault.BatchError{Errors: errs}
+	}
+	return secrets, nil
+}
+
+// BatchOption configures a batch write.
+type BatchOption func(*batchConfig)
+
+type batchConfig struct {
+	allOrNothing bool
+}
+
+// AllOrNothing makes a batch write roll back the secrets it wrote if writing
+// any of the secrets fails. The rollback is best-effort: since Vault has no
+// transactions, other clients can observe the written secrets before they are
+// rolled back, and rolling back a secret can itself fail.
+func AllOrNothing() BatchOption {
+	return func(cfg *batchConfig) {
+		cfg.allOrNothing = true
+	}
+}
+
+// WriteSecrets writes the latest secret version at each of the specified paths
+// using the DefaultClient.
+func WriteSecrets(secrets map[string]map[string]interface{}, opts ...BatchOption) error {
+	return DefaultClient.WriteSecrets(secrets, opts...)
+}
+
+// WriteSecrets writes the data of each secret in the map, keyed by path, as a
+// new latest version, making at most as many concurrent requests as
+// configured with WithConcurrency.
+//
+// The writes are not atomic. If writing some of the secrets fails, the other
+// secrets are still written, and a *vault.BatchError reports the error of
+// every failed path and the paths that were written. With the AllOrNothing
+// option, no further writes are started after a write fails, and the secrets
+// written before then, including writes already in flight, are rolled back: a
+// secret created by the write is deleted along with its metadata, and an
+// updated secret is restored to its previous version as a new version. The
+// remaining paths are not written. Paths that could not be rolled back are
+// reported in the errors of the *vault.BatchError. In dry-run mode, the
+// AllOrNothing option is not supported, and ErrDryRunUnsupported is returned
+// without writing any secret.
+func (c *Client) WriteSecrets(secrets map[string]map[string]interface{}, opts ...BatchOption) error {
+	var cfg batchConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.allOrNothing && c.dryRun {
+		return ErrDryRunUnsupported
+	}
+	if _, err := c.vaultClient(); err != nil {
+		return err
+	}
+	paths := make([]string, 0, len(secrets))
+	for path := range secrets {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var (
+		failed  bool
+		mu      sync.Mutex
+		written = make(map[string]SecretVersion, len(secrets))
+		errs    = make(map[string]error)
+	)
+	c.forEach(paths, func(path string) {
+		mu.Lock()
+		stop := cfg.allOrNothing && failed
+		mu.Unlock()
+		if stop {
+			return
+		}
+		v, err := c.WriteSecretLatest(path, secrets[path])
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs[path] = err
+			failed = true
+			return
+		}
+		written[path] = v
+	})
+	if len(errs) == 0 {
+		return nil
+	}
+	if cfg.allOrNothing {
+		written = c.rollbackWrites(written, errs)
+	}
+	succeeded := make([]string, 0, len(written))
+	for path := range written {
+		succeeded = append(succeeded, path)
+	}
+	sort.Strings(succeeded)
+	return &vault.BatchError{Errors: errs, Succeeded: succeeded}
+}
+
+// DeleteTree deletes every secret under the root path using the DefaultClient.
+func DeleteTree(root string, destroy bool) error {
+	return DefaultClient.DeleteTree(root, destroy)
+}
+
+// DeleteTree deletes every secret under the root path, as listed by Walk,
+// making at most as many concurrent requests as configured with
+// WithConcurrency. If destroy is false, the latest version of each secret is
+// soft deleted and can be undeleted; otherwise the metadata and all versions
+// of each secret are permanently deleted. The root must not be empty, so the
+// entire mount is never deleted by mistake.
+//
+// If listing the secrets fails, no secret is deleted. If deleting some of the
+// secrets fails, the other secrets are still deleted, and a *vault.BatchError
+// reports the error of every failed path and the paths that were deleted.
+func (c *Client) DeleteTree(root string, destroy bool) error {
+	if strings.Trim(root, "/") == "" {
+		return errors.New("kv2: delete tree root is empty")
+	}
+	var paths []string
+	if err := c.Walk(root, func(path string) error {
+		paths = append(paths, path)
+		return nil
+	}); err != nil {
+		return err
+	}
+	var (
+		mu      sync.Mutex
+		deleted = make([]string, 0, len(paths))
+		errs    = make(map[string]error)
+	)
+	c.forEach(paths, func(path string) {
+		var err error
+		if destroy {
+			err = c.DeleteSecretMetadata(path)
+		} else {
+			err = c.DeleteSecretLatest(path)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs[path] = err
+			return
+		}
+		deleted = append(deleted, path)
+	})
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Strings(deleted)
+	return &vault.BatchError{Errors: errs, Succeeded: deleted}
+}
+
+// rollbackWrites rolls back the written secret versions, adding the error of
+// every path that could not be rolled back to errs. The versions that remain
+// written are returned.
+func (c *Client) rollbackWrites(written map[string]SecretVersion, errs map[string]error) map[string]SecretVersion {
+	paths := make([]string, 0, len(written))
+	for path := range written {
+		paths = append(paths, path)
+	}
+	var (
+		mu        sync.Mutex
+		remaining = make(map[string]SecretVersion)
+	)
+	c.forEach(paths, func(path string) {
+		v := written[path]
+		var err error
+		switch v.Version {
+		case 0:
+			err = errors.New("kv2: written version is unknown")
+		case 1:
+			err = c.DeleteSecretMetadata(path)
+		default:
+			_, err = c.RollbackSecret(path, v.Version-1)
+		}
+		if err == nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		errs[path] = fmt.Errorf("kv2: rolling back write: %w", err)
+		remaining[path] = v
+	})
+	return remaining
+}
+
+// forEach calls fn for each unique path, with at most c.concurrency calls
+// running at the same time.
+func (c *Client) forEach(paths []string, fn func(path string)) {
+	n := c.concurrency
+	if n < 1 {
+		n = 1
+	}
+	sem := make(chan struct{}, n)
+	seen := make(map[string]bool, len(paths))
+	var g errgroup.Group
+	for _, path := range paths {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		path := path
+		sem <- struct{}{}
+		g.Go(func() error {
+			defer func() { <-sem }()
+			fn(path)
+			return nil
+		})
+	}
+	_ = g.Wait()
+}
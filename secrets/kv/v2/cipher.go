@@ -0,0 +1,98 @@
+package kv
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// encryptedPrefix marks a field value encrypted with a field cipher.
+const encryptedPrefix = "aead:v1:"
+
+// ErrFieldNotEncrypted is returned when reading a secret whose field, listed
+// with WithFieldCipher, is not encrypted.
+var ErrFieldNotEncrypted = errors.New("kv2: field is not encrypted")
+
+type fieldCipher struct {
+	aead   cipher.AEAD
+	fields map[string]bool
+}
+
+// encrypt returns a copy of the data of the secret at path, relative to the
+// mount, with the values of the cipher fields encrypted. Nil values are not
+// encrypted, so a JSON merge patch can still remove a field.
+func (f *fieldCipher) encrypt(path string, data map[string]interface{}) (map[string]interface{}, error) {
+	if f == nil || data == nil {
+		return data, nil
+	}
+	out := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		out[k] = v
+		if !f.fields[k] || v == nil {
+			continue
+		}
+		plaintext, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("kv2: encrypting field %q: %w", k, err)
+		}
+		nonce := make([]byte, f.aead.NonceSize(), f.aead.NonceSize()+len(plaintext)+f.aead.Overhead())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return nil, fmt.Errorf("kv2: encrypting field %q: %w", k, err)
+		}
+		sealed := f.aead.Seal(nonce, nonce, plaintext, additionalData(path, k))
+		out[k] = encryptedPrefix + base64.StdEncoding.EncodeToString(sealed)
+	}
+	return out, nil
+}
+
+// decrypt returns a copy of the data of the secret at path, relative to the
+// mount, with the values of the cipher fields decrypted.
+func (f *fieldCipher) decrypt(path string, data map[string]interface{}) (map[string]interface{}, error) {
+	if f == nil {
+		return data, nil
+	}
+	out := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		out[k] = v
+		if !f.fields[k] {
+			continue
+		}
+		s, ok := v.(string)
+		if !ok || !strings.HasPrefix(s, encryptedPrefix) {
+			return nil, fmt.Errorf("%w: %q", ErrFieldNotEncrypted, k)
+		}
+		sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s, encryptedPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("kv2: decrypting field %q: %w", k, err)
+		}
+		if len(sealed) < f.aead.NonceSize() {
+			return nil, fmt.Errorf("kv2: decrypting field %q: ciphertext too short", k)
+		}
+		nonce, ciphertext := sealed[:f.aead.NonceSize()], sealed[f.aead.NonceSize():]
+		plaintext, err := f.aead.Open(nil, nonce, ciphertext, additionalData(path, k))
+		if err != nil {
+			return nil, fmt.Errorf("kv2: decrypting field %q: %w", k, err)
+		}
+		dec := json.NewDecoder(bytes.NewReader(plaintext))
+		dec.UseNumber()
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			return nil, fmt.Errorf("kv2: decrypting field %q: %w", k, err)
+		}
+		out[k] = value
+	}
+	return out, nil
+}
+
+// additionalData returns the additional data sealed with the value of field k
+// of the secret at path, so the ciphertext only decrypts for that field of
+// that secret.
+func additionalData(path, k string) []byte {
+	return []byte(path + "\x00" + k)
+}
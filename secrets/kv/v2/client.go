@@ -10,8 +10,8 @@
 // To use a kv secrets engine mounted at a custom path, create a new Client:
 //
 //    // Create a secret at the KV path "/my-kv/some/path".
-//    c := kv.NewClient("/my-kv", nil)
-//    c.WriteSecret("some/path", map[string]interface{}{"foo": "bar"})
+//    c := kv.NewClient("/my-kv")
+//    c.WriteSecret("some/path", map[string]interface{}{"foo": "bar"}, kv.WriteOptions{})
 //
 // vailable
 //
@@ -20,21 +20,77 @@
 package kv
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
+	"os"
 	"path"
+	"reflect"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/vault/api"
 	"github.com/mitchellh/mapstructure"
 	"github.com/mwalto7/vault"
+	"github.com/mwalto7/vault/internal/vaultclient"
 )
 
-const defaultMountPath = "/secret"
+const (
+	defaultMountPath   = "/secret"
+	defaultConcurrency = 8
+	defaultCASRetries  = 3
+)
+
+// ErrInvalidSecretPath is returned when a secret path starts with a slash or
+// contains "." or ".." segments.
+var ErrInvalidSecretPath = errors.New("kv2: invalid secret path")
+
+// ErrSecretNotFound is returned when no data is stored at the secret path, or
+// the requested secret version has been deleted or destroyed.
+var ErrSecretNotFound = vault.ErrSecretNotFound
+
+// ErrEmptyData is returned when the data of a write is nil or empty and the
+// Client was created with WithRejectEmptyWrites.
+var ErrEmptyData = vault.ErrEmptyData
+
+// ErrVersionDestroyed is returned when the requested secret version was
+// destroyed, so its data is permanently deleted. It wraps ErrSecretNotFound,
+// so errors.Is matches either error.
+var ErrVersionDestroyed = fmt.Errorf("kv2: secret version destroyed: %w", ErrSecretNotFound)
 
-// DefaultClient is a KVv2 API client mounted at the default path in Vault.
-var DefaultClient = NewClient(defaultMountPath, nil)
+// ErrNoVersions is returned when deleting, undeleting or destroying secret
+// versions without specifying any version.
+var ErrNoVersions = errors.New("kv2: must specify at least one version")
+
+// ErrPermissionDenied is returned when the Vault token is invalid or is not
+// permitted to make the request. Use IsPermissionDenied to also match errors
+// of clients that do not classify their errors.
+var ErrPermissionDenied = vault.ErrPermissionDenied
+
+// IsPermissionDenied reports whether err is a Vault permission denied error.
+// See vault.IsPermissionDenied.
+func IsPermissionDenied(err error) bool {
+	return vault.IsPermissionDenied(err)
+}
+
+// IsNotFound reports whether err is a Vault not found error, such as
+// ErrSecretNotFound. See vault.IsNotFound.
+func IsNotFound(err error) bool {
+	return vault.IsNotFound(err)
+}
+
+// MountPathEnv is the environment variable that overrides the mount path of
+// the DefaultClient, for KVv2 secrets engines not mounted at "/secret".
+const MountPathEnv = "VAULT_KV2_MOUNT"
+
+// DefaultClient is a KVv2 API client mounted at the default path in Vault, or
+// at the path set by the MountPathEnv environment variable when the program
+// starts.
+var DefaultClient = NewClient(vaultclient.MountPath(MountPathEnv, defaultMountPath))
 
 // SetEngineConfig updates the KVv2 secrets engine configuration using the
 // DefaultClient.
@@ -52,22 +108,47 @@ func EngineConfig() (SecretConfig, error) {
 	return DefaultClient.EngineConfig()
 }
 
+// Ping checks the connection to Vault and the token of the DefaultClient.
+func Ping() error {
+	return DefaultClient.Ping()
+}
+
 // ReadSecretLatest reads the latest secret version at the specified path using
-// the DefaultClient.
+// the DefaultClient. If no data is stored at the path, ErrSecretNotFound is
+// returned.
 //
 // See https://www.vaultproject.io/api-docs/secret/kv/kv-v2#read-secret-version.
 func ReadSecretLatest(path string) (Secret, error) {
 	return DefaultClient.ReadSecretLatest(path)
 }
 
+// ReadSecretLatestInto reads the latest secret version at the specified path
+// using the DefaultClient and decodes its data into out.
+func ReadSecretLatestInto(path string, out interface{}, opts ...vault.DecodeOption) error {
+	return DefaultClient.ReadSecretLatestInto(path, out, opts...)
+}
+
+// ReadSecretWithLease reads the latest secret version at the specified path
+// using the DefaultClient and also returns the lease of the Vault response.
+func ReadSecretWithLease(path string) (Secret, vault.Lease, error) {
+	return DefaultClient.ReadSecretWithLease(path)
+}
+
 // ReadSecretVersion reads the secret version at the specified path using the
 // DefaultClient. If the version is negative, the latest secret version is read.
+// If no data is stored for the version, ErrSecretNotFound is returned.
 //
 // See https://www.vaultproject.io/api-docs/secret/kv/kv-v2#read-secret-version.
 func ReadSecretVersion(path string, version int) (Secret, error) {
 	return DefaultClient.ReadSecretVersion(path, version)
 }
 
+// UnwrapSecret returns the response-wrapped secret version of the wrapping
+// token using the DefaultClient.
+func UnwrapSecret(wrappingToken string) (Secret, error) {
+	return DefaultClient.UnwrapSecret(wrappingToken)
+}
+
 // WriteSecretLatest creates or updates the latest secret version at the
 // specified path using the DefaultClient.
 //
@@ -76,6 +157,14 @@ func WriteSecretLatest(path string, data map[string]interface{}) (SecretVersion,
 	return DefaultClient.WriteSecretLatest(path, data)
 }
 
+// WriteSecret creates or updates the latest secret version at the specified
+// path with the write options using the DefaultClient.
+//
+// See https://www.vaultproject.io/api-docs/secret/kv/kv-v2#create-update-secret.
+func WriteSecret(path string, data map[string]interface{}, opts WriteOptions) (SecretVersion, error) {
+	return DefaultClient.WriteSecret(path, data, opts)
+}
+
 // WriteSecretVersion creates or updates a secret version at the specified path
 // using the DefaultClient.
 //
@@ -89,6 +178,20 @@ func WriteSecretVersion(path string, version int, data map[string]interface{}) (
 	return DefaultClient.WriteSecretVersion(path, version, data)
 }
 
+// WriteSecretFrom creates or updates the latest secret version at the
+// specified path with the data encoded from in using the DefaultClient.
+func WriteSecretFrom(path string, in interface{}) (SecretVersion, error) {
+	return DefaultClient.WriteSecretFrom(path, in)
+}
+
+// PatchSecret partially updates the latest secret version at the specified
+// path using the DefaultClient.
+//
+// See https://www.vaultproject.io/api-docs/secret/kv/kv-v2#patch-secret.
+func PatchSecret(path string, data map[string]interface{}) (SecretVersion, error) {
+	return DefaultClient.PatchSecret(path, data)
+}
+
 // DeleteSecretLatest soft deletes the latest secret version at the specified
 // path using the DefaultClient.
 //
@@ -105,6 +208,13 @@ func DeleteSecretVersion(path string, version ...int) error {
 	return DefaultClient.DeleteSecretVersion(path, version...)
 }
 
+// DeleteSecretVersionQuery soft deletes the secret version(s) at the specified
+// path with a DELETE request on the data path using the DefaultClient. Must
+// specify at least one version.
+func DeleteSecretVersionQuery(path string, version ...int) error {
+	return DefaultClient.DeleteSecretVersionQuery(path, version...)
+}
+
 // UndeleteSecretVersion restores the secret version(s) at the specified path
 // using the DefaultClient. Must specify at least one version.
 //
@@ -121,6 +231,14 @@ func DestroySecretVersion(path string, version ...int) error {
 	return DefaultClient.DestroySecretVersion(path, version...)
 }
 
+// ReadSecretSubkeys returns the subkeys of the secret version at the specified
+// path using the DefaultClient.
+//
+// See https://www.vaultproject.io/api-docs/secret/kv/kv-v2#read-secret-subkeys.
+func ReadSecretSubkeys(path string, version, depth int) (map[string]interface{}, error) {
+	return DefaultClient.ReadSecretSubkeys(path, version, depth)
+}
+
 // ListSecrets lists the secret keys at the specified path using the
 // DefaultClient.
 //
@@ -137,6 +255,12 @@ func ReadSecretMetadata(path string) (SecretMetadata, error) {
 	return DefaultClient.ReadSecretMetadata(path)
 }
 
+// ExistsSecret reports whether a secret is stored at the specified path using
+// the DefaultClient.
+func ExistsSecret(path string) (bool, error) {
+	return DefaultClient.ExistsSecret(path)
+}
+
 // WriteSecretMetadata updates the secret configuration at the specified path
 // using the DefaultClient.
 //
@@ -157,14 +281,42 @@ func DeleteSecretMetadata(path string) error {
 //
 // See https://www.vaultproject.io/api-docs/secret/kv/kv-v2#kv-secrets-engine-version-2-api.
 type Client struct {
-	mountPath string
-	client    vault.LogicalClient
+	mountPath        string
+	mountErr         error
+	prefix           string
+	defaultMountPath string
+	core             *vaultclient.Client
+	validator        vault.WriteValidator
+	rejectEmpty      bool
+	dryRun           bool
+	dryRunMu         sync.Mutex
+	planned          []Action
+	normalize        bool
+	rawPaths         bool
+	concurrency      int
+	casRetries       int
+	autoCAS          bool
+	cipher           *fieldCipher
 }
 
 // NewClient creates a new KVv2 API client for the secrets engine mounted at the
-// given path in Vault.
-func NewClient(path string, client vault.LogicalClient) *Client {
-	return &Client{mountPath: path, client: client}
+// given path in Vault, configured with the given options.
+func NewClient(path string, opts ...Option) *Client {
+	c := &Client{
+		mountPath:        path,
+		defaultMountPath: defaultMountPath,
+		core:             &vaultclient.Client{},
+		concurrency:      defaultConcurrency,
+		casRetries:       defaultCASRetries,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.mountPath == "" {
+		c.mountPath = c.defaultMountPath
+	}
+	c.mountPath, c.mountErr = cleanMountPath(c.mountPath)
+	return c
 }
 
 // SecretConfig represents the configurable settings of a secret stored in the
@@ -178,6 +330,10 @@ type SecretConfig struct {
 
 	// Specified the duration after which to delete secret version(s).
 	DeleteVersionAfter time.Duration `json:"delete_version_after,omitempty"`
+
+	// The user-provided key-value metadata of a secret. Only applies to
+	// secret metadata, not to the engine configuration.
+	CustomMetadata map[string]string `json:"custom_metadata,omitempty"`
 }
 
 // SetEngineConfig updates the KVv2 secrets engine configuration.
@@ -196,8 +352,11 @@ func (c *Client) SetEngineConfig(cfg SecretConfig) error {
 	if err := json.Unmarshal(b, &data); err != nil {
 		return err
 	}
-	_, err = client.Write(pathJoin(c.mountPath, "config"), data)
-	return err
+	path := pathJoin(c.mountPath, "config")
+	if _, err := client.Write(path, data); err != nil {
+		return &os.PathError{Op: "SetEngineConfig", Path: path, Err: err}
+	}
+	return nil
 }
 
 // EngineConfig returns the KVv2 secrets engine configuration.
@@ -208,9 +367,10 @@ func (c *Client) EngineConfig() (SecretConfig, error) {
 	if err != nil {
 		return SecretConfig{}, err
 	}
-	secret, err := client.Read(pathJoin(c.mountPath, "config"))
+	path := pathJoin(c.mountPath, "config")
+	secret, err := client.Read(path)
 	if err != nil {
-		return SecretConfig{}, err
+		return SecretConfig{}, &os.PathError{Op: "EngineConfig", Path: path, Err: err}
 	}
 	if secret == nil || len(secret.Data) == 0 {
 		return SecretConfig{}, nil
@@ -224,6 +384,18 @@ func (c *Client) EngineConfig() (SecretConfig, error) {
 	return aux.Data, nil
 }
 
+// Ping checks that Vault is reachable and unsealed, and that the token of the
+// Client can read the engine configuration, by reading it. The returned error
+// wraps vault.ErrUnreachable, vault.ErrPermissionDenied or vault.ErrSealed if
+// it is one of those failures.
+func (c *Client) Ping() error {
+	client, err := c.vaultClient()
+	if err != nil {
+		return err
+	}
+	return vault.Ping(client, pathJoin(c.mountPath, "config"))
+}
+
 // SecretMetadata represents a secret's data and all of its version metadata.
 type SecretMetadata struct {
 	// The time at which the secret was created.
@@ -235,12 +407,22 @@ type SecretMetadata struct {
 	// The maximum allowed number of secret versions to store.
 	MaxVersions int `json:"max_versions"`
 
+	// Specifies if CAS is required for writes to the secret.
+	CASRequired bool `json:"cas_required"`
+
+	// The duration after which secret versions are deleted, or zero if
+	// versions are kept until deleted explicitly.
+	DeleteVersionAfter time.Duration `json:"delete_version_after"`
+
 	// The oldest available version of the secret.
 	OldestVersion int `json:"oldest_version"`
 
 	// The last time at which the secret was updated, or modified.
 	UpdatedTime time.Time `json:"updated_time"`
 
+	// The user-provided key-value metadata of the secret.
+	CustomMetadata map[string]string `json:"custom_metadata"`
+
 	// The version metadata for all versions of the secret.
 	Versions map[string]SecretVersion `json:"versions"`
 }
@@ -258,6 +440,10 @@ type SecretVersion struct {
 
 	// The specific version of the secret.
 	Version int `json:"version"`
+
+	// The non-fatal warnings returned by Vault with the write that created
+	// the secret version. Only set for the versions returned by writes.
+	Warnings []string `json:"-"`
 }
 
 // Secret represents a secret's data and its specific version metadata.
@@ -267,51 +453,163 @@ type Secret struct {
 
 	// The version metadata associated with the secret.
 	Metadata SecretVersion `json:"metadata"`
+
+	// The non-fatal warnings returned by Vault with the read.
+	Warnings []string `json:"-"`
 }
 
-// ReadSecretLatest reads the latest secret version at the specified path.
+// ReadSecretLatest reads the latest secret version at the specified path. If
+// no data is stored at the path, ErrSecretNotFound is returned.
 //
 // See https://www.vaultproject.io/api-docs/secret/kv/kv-v2#read-secret-version.
 func (c *Client) ReadSecretLatest(path string) (Secret, error) {
 	return c.ReadSecretVersion(path, -1)
 }
 
+// ReadSecretLatestInto reads the latest secret version at the specified path
+// and decodes its data into out, which must be a pointer to a struct or map.
+// Struct fields are matched to secret keys using their "mapstructure" tags. If
+// no data is stored at the path, ErrSecretNotFound is returned.
+func (c *Client) ReadSecretLatestInto(path string, out interface{}, opts ...vault.DecodeOption) error {
+	secret, err := c.ReadSecretLatest(path)
+	if err != nil {
+		return err
+	}
+	if err := vault.DecodeData(secret.Data, out, opts...); err != nil {
+		return &os.PathError{Op: "ReadSecretLatestInto", Path: path, Err: err}
+	}
+	return nil
+}
+
 // ReadSecretVersion reads the secret version at the specified path. If the
-// version is negative, the latest secret version is read.
+// version is negative, the latest secret version is read. If the version was
+// destroyed, ErrVersionDestroyed is returned; if no data is otherwise stored
+// for the version, such as when it was deleted or the path does not exist,
+// ErrSecretNotFound is returned.
 //
 // See https://www.vaultproject.io/api-docs/secret/kv/kv-v2#read-secret-version.
 func (c *Client) ReadSecretVersion(path string, version int) (Secret, error) {
+	s, _, err := c.readSecretVersion(path, version)
+	return s, err
+}
+
+// ReadSecretWithLease reads the latest secret version at the specified path
+// like ReadSecretLatest and also returns the lease of the Vault response. KV
+// secrets have no lease, so the lease is usually zero, but it is reported for
+// KV mounts behind proxies or plugins that lease their responses.
+func (c *Client) ReadSecretWithLease(path string) (Secret, vault.Lease, error) {
+	s, secret, err := c.readSecretVersion(path, -1)
+	if err != nil {
+		return Secret{}, vault.Lease{}, err
+	}
+	return s, vault.LeaseOf(secret), nil
+}
+
+// readSecretVersion reads and decodes the secret version at the specified path
+// and also returns the Vault response.
+func (c *Client) readSecretVersion(path string, version int) (Secret, *api.Secret, error) {
+	relPath := path
 	path, err := c.secretPath(path, false)
 	if err != nil {
-		return Secret{}, err
+		return Secret{}, nil, err
 	}
 	client, err := c.vaultClient()
 	if err != nil {
-		return Secret{}, err
+		return Secret{}, nil, err
 	}
 	var secret *api.Secret
 	if version > -1 {
 		v := strconv.Itoa(version)
 		secret, err = client.ReadWithData(path, map[string][]string{"version": {v}})
 		if err != nil {
-			return Secret{}, err
+			return Secret{}, nil, &os.PathError{Op: "ReadSecretVersion", Path: path, Err: err}
 		}
 	} else {
 		secret, err = client.Read(path)
 		if err != nil {
-			return Secret{}, err
+			return Secret{}, nil, &os.PathError{Op: "ReadSecretVersion", Path: path, Err: err}
 		}
 	}
 	if secret == nil || len(secret.Data) == 0 {
-		return Secret{}, nil
+		return Secret{}, nil, &os.PathError{Op: "ReadSecretVersion", Path: path, Err: ErrSecretNotFound}
 	}
-	var aux struct {
-		Data Secret `mapstructure:"data"`
+	var s Secret
+	if err := decode(secret.Data, &s); err != nil {
+		return Secret{}, nil, err
 	}
-	if err := mapstructure.Decode(secret.Data, &aux); err != nil {
+	if len(s.Data) == 0 && s.Metadata.Destroyed {
+		return Secret{}, nil, &os.PathError{Op: "ReadSecretVersion", Path: path, Err: ErrVersionDestroyed}
+	}
+	if len(s.Data) == 0 {
+		return Secret{}, nil, &os.PathError{Op: "ReadSecretVersion", Path: path, Err: ErrSecretNotFound}
+	}
+	if s.Data, err = c.cipher.decrypt(c.cipherPath(relPath), s.Data); err != nil {
+		return Secret{}, nil, &os.PathError{Op: "ReadSecretVersion", Path: path, Err: err}
+	}
+	if c.normalize {
+		s.Data = normalize(s.Data).(map[string]interface{})
+	}
+	s.Warnings = secret.Warnings
+	return s, secret, nil
+}
+
+// UnwrapSecret returns the response-wrapped secret version of the wrapping
+// token, such as one returned by a wrapped read of the secret data. If the
+// wrapping token is empty, invalid or was already used, ErrSecretNotFound is
+// returned.
+//
+// See https://www.vaultproject.io/api-docs/system/wrapping-unwrap.
+func (c *Client) UnwrapSecret(wrappingToken string) (Secret, error) {
+	client, err := c.vaultClient()
+	if err != nil {
 		return Secret{}, err
 	}
-	return aux.Data, nil
+	var cipherPath string
+	if c.cipher != nil && strings.TrimSpace(wrappingToken) != "" {
+		if cipherPath, err = c.wrappedSecretPath(client, wrappingToken); err != nil {
+			return Secret{}, err
+		}
+	}
+	secret, err := vault.Unwrap(client, wrappingToken)
+	if err != nil {
+		return Secret{}, err
+	}
+	var s Secret
+	if err := decode(secret.Data, &s); err != nil {
+		return Secret{}, err
+	}
+	if len(s.Data) == 0 {
+		return Secret{}, &os.PathError{Op: "UnwrapSecret", Path: "sys/wrapping/unwrap", Err: ErrSecretNotFound}
+	}
+	if s.Data, err = c.cipher.decrypt(cipherPath, s.Data); err != nil {
+		return Secret{}, &os.PathError{Op: "UnwrapSecret", Path: "sys/wrapping/unwrap", Err: err}
+	}
+	s.Warnings = secret.Warnings
+	return s, nil
+}
+
+// wrappedSecretPath looks up, without using the wrapping token, the path
+// relative to the mount of the secret whose response it wraps, which the
+// field ciphertexts of the secret are bound to.
+func (c *Client) wrappedSecretPath(client vault.LogicalClient, wrappingToken string) (string, error) {
+	const lookupPath = "sys/wrapping/lookup"
+	secret, err := client.Write(lookupPath, map[string]interface{}{"token": wrappingToken})
+	if err != nil {
+		return "", &os.PathError{Op: "UnwrapSecret", Path: lookupPath, Err: err}
+	}
+	var creationPath string
+	if secret != nil {
+		creationPath, _ = secret.Data["creation_path"].(string)
+	}
+	dataPath := strings.Trim(c.mountPath, "/") + "/"
+	if !c.rawPaths {
+		dataPath += "data/"
+	}
+	creationPath = strings.TrimPrefix(creationPath, "/")
+	if !strings.HasPrefix(creationPath, dataPath) {
+		return "", &os.PathError{Op: "UnwrapSecret", Path: lookupPath, Err: fmt.Errorf("kv2: wrapped response of %q is not a secret of the mount", creationPath)}
+	}
+	return strings.TrimPrefix(creationPath, dataPath), nil
 }
 
 // WriteSecretLatest creates or updates the latest secret version at the
@@ -331,32 +629,142 @@ func (c *Client) WriteSecretLatest(path string, data map[string]interface{}) (Se
 //
 // See https://www.vaultproject.io/api-docs/secret/kv/kv-v2#create-update-secret.
 func (c *Client) WriteSecretVersion(path string, version int, data map[string]interface{}) (SecretVersion, error) {
+	var opts WriteOptions
+	if version > -1 {
+		opts.CAS = &version
+	}
+	return c.writeSecret("WriteSecretVersion", path, data, opts)
+}
+
+// WriteOptions configures a secret write.
+type WriteOptions struct {
+	// The check-and-set version of the write. If nil, all writes are allowed.
+	// If zero, writes are allowed only if the secret does not already exist. If
+	// positive, writes are allowed only if the version matches the current
+	// version of the secret. Use Int to set it.
+	CAS *int
+}
+
+// WriteSecret creates or updates the latest secret version at the specified
+// path with the write options.
+//
+// See https://www.vaultproject.io/api-docs/secret/kv/kv-v2#create-update-secret.
+func (c *Client) WriteSecret(path string, data map[string]interface{}, opts WriteOptions) (SecretVersion, error) {
+	return c.writeSecret("WriteSecret", path, data, opts)
+}
+
+func (c *Client) writeSecret(op, path string, data map[string]interface{}, opts WriteOptions) (SecretVersion, error) {
+	relPath := path
 	path, err := c.secretPath(path, false)
 	if err != nil {
 		return SecretVersion{}, err
 	}
+	if c.rejectEmpty && len(data) == 0 {
+		return SecretVersion{}, &os.PathError{Op: op, Path: path, Err: ErrEmptyData}
+	}
+	if c.validator != nil {
+		if err := c.validator(relPath, data); err != nil {
+			return SecretVersion{}, &os.PathError{Op: op, Path: path, Err: err}
+		}
+	}
 	client, err := c.vaultClient()
 	if err != nil {
 		return SecretVersion{}, err
 	}
+	data, err = c.cipher.encrypt(c.cipherPath(relPath), data)
+	if err != nil {
+		return SecretVersion{}, &os.PathError{Op: op, Path: path, Err: err}
+	}
 	d := map[string]interface{}{"data": data}
-	if version > -1 {
-		d["options"] = map[string]interface{}{"cas": version}
+	if opts.CAS != nil {
+		d["options"] = map[string]interface{}{"cas": *opts.CAS}
 	}
 	secret, err := client.Write(path, d)
+	if opts.CAS == nil && isCASRequired(err) {
+		if !c.autoCAS {
+			return SecretVersion{}, &os.PathError{Op: op, Path: path, Err: ErrCASRequired}
+		}
+		secret, err = c.writeCurrentVersion(client, relPath, d)
+	}
+	if err != nil {
+		return SecretVersion{}, &os.PathError{Op: op, Path: path, Err: err}
+	}
+	return decodeWriteResponse(secret)
+}
+
+// writeCurrentVersion writes the request data d of a write to the secret at
+// the specified path with the current version of the secret, from its
+// metadata, as the check-and-set version.
+func (c *Client) writeCurrentVersion(client vault.LogicalClient, path string, d map[string]interface{}) (*api.Secret, error) {
+	md, err := c.ReadSecretMetadata(path)
+	if err != nil {
+		return nil, fmt.Errorf("kv2: reading current version: %w", err)
+	}
+	dataPath, err := c.secretPath(path, false)
+	if err != nil {
+		return nil, err
+	}
+	d["options"] = map[string]interface{}{"cas": md.CurrentVersion}
+	return client.Write(dataPath, d)
+}
+
+// WriteSecretFrom creates or updates the latest secret version at the
+// specified path with the data encoded from in, which must be a struct or a map
+// with string keys. Struct fields are named using their "mapstructure" tags,
+// and nested structs are encoded into nested maps.
+func (c *Client) WriteSecretFrom(path string, in interface{}) (SecretVersion, error) {
+	data, err := vault.EncodeData(in)
 	if err != nil {
 		return SecretVersion{}, err
 	}
-	if secret == nil || len(secret.Data) == 0 {
-		return SecretVersion{}, nil
+	return c.WriteSecretLatest(path, data)
+}
+
+// PatchSecret partially updates the latest secret version at the specified
+// path using a JSON merge patch. Only the keys present in data are changed, and
+// keys set to nil are removed from the secret. The patch is not validated with
+// the validator set with WithWriteValidator, since it is only part of the
+// secret data.
+//
+// See https://www.vaultproject.io/api-docs/secret/kv/kv-v2#patch-secret.
+func (c *Client) PatchSecret(path string, data map[string]interface{}) (SecretVersion, error) {
+	relPath := path
+	path, err := c.secretPath(path, false)
+	if err != nil {
+		return SecretVersion{}, err
 	}
-	var aux struct {
-		Data SecretVersion `mapstructure:"data"`
+	if c.rejectEmpty && len(data) == 0 {
+		return SecretVersion{}, &os.PathError{Op: "PatchSecret", Path: path, Err: ErrEmptyData}
 	}
-	if err := mapstructure.Decode(secret.Data, &aux); err != nil {
+	client, err := c.vaultClient()
+	if err != nil {
 		return SecretVersion{}, err
 	}
-	return aux.Data, nil
+	data, err = c.cipher.encrypt(c.cipherPath(relPath), data)
+	if err != nil {
+		return SecretVersion{}, &os.PathError{Op: "PatchSecret", Path: path, Err: err}
+	}
+	secret, err := client.JSONMergePatch(context.Background(), path, map[string]interface{}{"data": data})
+	if err != nil {
+		return SecretVersion{}, &os.PathError{Op: "PatchSecret", Path: path, Err: err}
+	}
+	return decodeWriteResponse(secret)
+}
+
+// decodeWriteResponse returns the secret version created by a write, with the
+// warnings of the response.
+func decodeWriteResponse(secret *api.Secret) (SecretVersion, error) {
+	if secret == nil {
+		return SecretVersion{}, nil
+	}
+	var v SecretVersion
+	if len(secret.Data) > 0 {
+		if err := decode(secret.Data, &v); err != nil {
+			return SecretVersion{}, err
+		}
+	}
+	v.Warnings = secret.Warnings
+	return v, nil
 }
 
 // DeleteSecretLatest soft deletes the latest secret version at the specified
@@ -372,8 +780,13 @@ func (c *Client) DeleteSecretLatest(path string) error {
 	if err != nil {
 		return err
 	}
-	_, err = client.Delete(path)
-	return err
+	if c.skipDryRun(Action{Op: "DeleteSecretLatest", Path: path}) {
+		return nil
+	}
+	if _, err := client.Delete(path); err != nil {
+		return &os.PathError{Op: "DeleteSecretLatest", Path: path, Err: err}
+	}
+	return nil
 }
 
 // DeleteSecretVersion soft deletes the secret version(s) at the specified path.
@@ -381,13 +794,57 @@ func (c *Client) DeleteSecretLatest(path string) error {
 //
 // See https://www.vaultproject.io/api-docs/secret/kv/kv-v2#delete-secret-versions.
 func (c *Client) DeleteSecretVersion(path string, version ...int) error {
+	if len(version) == 0 {
+		return ErrNoVersions
+	}
+	path, err := c.endpointPath("delete", path)
+	if err != nil {
+		return err
+	}
+	client, err := c.vaultClient()
+	if err != nil {
+		return err
+	}
+	if c.skipDryRun(Action{Op: "DeleteSecretVersion", Path: path, Versions: version}) {
+		return nil
+	}
+	if _, err := client.Write(path, map[string]interface{}{"versions": version}); err != nil {
+		return &os.PathError{Op: "DeleteSecretVersion", Path: path, Err: err}
+	}
+	return nil
+}
+
+// DeleteSecretVersionQuery soft deletes the secret version(s) at the specified
+// path with a DELETE request on the data path, passing the versions as
+// "versions" query parameters, instead of the POST request with a body made by
+// DeleteSecretVersion. Use it where proxies block requests to the delete
+// endpoint. Must specify at least one version.
+//
+// Vault servers that ignore the query parameters soft delete the latest
+// version instead, so prefer DeleteSecretVersion where it is not blocked.
+func (c *Client) DeleteSecretVersionQuery(path string, version ...int) error {
+	if len(version) == 0 {
+		return ErrNoVersions
+	}
+	path, err := c.secretPath(path, false)
+	if err != nil {
+		return err
+	}
 	client, err := c.vaultClient()
 	if err != nil {
 		return err
 	}
-	path = pathJoin(c.mountPath, "delete", path)
-	_, err = client.Write(path, map[string]interface{}{"versions": version})
-	return err
+	if c.skipDryRun(Action{Op: "DeleteSecretVersionQuery", Path: path, Versions: version}) {
+		return nil
+	}
+	versions := make([]string, len(version))
+	for i, v := range version {
+		versions[i] = strconv.Itoa(v)
+	}
+	if _, err := client.DeleteWithData(path, map[string][]string{"versions": versions}); err != nil {
+		return &os.PathError{Op: "DeleteSecretVersionQuery", Path: path, Err: err}
+	}
+	return nil
 }
 
 // UndeleteSecretVersion restores the secret version(s) at the specified path.
@@ -396,15 +853,20 @@ func (c *Client) DeleteSecretVersion(path string, version ...int) error {
 // See https://www.vaultproject.io/api-docs/secret/kv/kv-v2#undelete-secret-versions.
 func (c *Client) UndeleteSecretVersion(path string, version ...int) error {
 	if len(version) == 0 {
-		return errors.New("kv2: must specify at least one version")
+		return ErrNoVersions
+	}
+	path, err := c.endpointPath("undelete", path)
+	if err != nil {
+		return err
 	}
 	client, err := c.vaultClient()
 	if err != nil {
 		return err
 	}
-	path = pathJoin(c.mountPath, "undelete", path)
-	_, err = client.Write(path, map[string]interface{}{"versions": version})
-	return err
+	if _, err := client.Write(path, map[string]interface{}{"versions": version}); err != nil {
+		return &os.PathError{Op: "UndeleteSecretVersion", Path: path, Err: err}
+	}
+	return nil
 }
 
 // DestroySecretVersion permanently deletes the secret version(s) at the
@@ -413,15 +875,72 @@ func (c *Client) UndeleteSecretVersion(path string, version ...int) error {
 // See https://www.vaultproject.io/api-docs/secret/kv/kv-v2#destroy-secret-versions.
 func (c *Client) DestroySecretVersion(path string, version ...int) error {
 	if len(version) == 0 {
-		return errors.New("kv2: must specify at least one version")
+		return ErrNoVersions
+	}
+	path, err := c.endpointPath("destroy", path)
+	if err != nil {
+		return err
 	}
 	client, err := c.vaultClient()
 	if err != nil {
 		return err
 	}
-	path = pathJoin(c.mountPath, "destroy", path)
-	_, err = client.Write(path, map[string]interface{}{"versions": version})
-	return err
+	if c.skipDryRun(Action{Op: "DestroySecretVersion", Path: path, Versions: version}) {
+		return nil
+	}
+	if _, err := client.Write(path, map[string]interface{}{"versions": version}); err != nil {
+		return &os.PathError{Op: "DestroySecretVersion", Path: path, Err: err}
+	}
+	return nil
+}
+
+// ReadSecretSubkeys returns the subkeys of the secret version at the specified
+// path. The keys of the secret data are returned with all non-object values
+// set to nil, so the structure of a secret can be inspected without reading it.
+//
+// If the version is zero, the latest secret version is used. If the depth is
+// zero, all nested keys are returned; otherwise the result is limited to the
+// given depth.
+//
+// See https://www.vaultproject.io/api-docs/secret/kv/kv-v2#read-secret-subkeys.
+func (c *Client) ReadSecretSubkeys(path string, version, depth int) (map[string]interface{}, error) {
+	path, err := c.endpointPath("subkeys", path)
+	if err != nil {
+		return nil, err
+	}
+	client, err := c.vaultClient()
+	if err != nil {
+		return nil, err
+	}
+	params := make(map[string][]string)
+	if version != 0 {
+		params["version"] = []string{strconv.Itoa(version)}
+	}
+	if depth != 0 {
+		params["depth"] = []string{strconv.Itoa(depth)}
+	}
+	var secret *api.Secret
+	if len(params) > 0 {
+		secret, err = client.ReadWithData(path, params)
+		if err != nil {
+			return nil, &os.PathError{Op: "ReadSecretSubkeys", Path: path, Err: err}
+		}
+	} else {
+		secret, err = client.Read(path)
+		if err != nil {
+			return nil, &os.PathError{Op: "ReadSecretSubkeys", Path: path, Err: err}
+		}
+	}
+	if secret == nil || len(secret.Data) == 0 {
+		return nil, nil
+	}
+	var aux struct {
+		Subkeys map[string]interface{} `mapstructure:"subkeys"`
+	}
+	if err := mapstructure.Decode(secret.Data, &aux); err != nil {
+		return nil, err
+	}
+	return aux.Subkeys, nil
 }
 
 // ListSecrets lists the secret keys at the specified path.
@@ -432,26 +951,43 @@ func (c *Client) ListSecrets(path string) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	return c.list("ListSecrets", path)
+}
+
+func (c *Client) list(op, path string) ([]string, error) {
+	data, err := c.listData(op, path)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return decodeKeys(data)
+}
+
+// listData makes the list request for the path and returns the data of the
+// response, or nil if no keys are stored under the path.
+func (c *Client) listData(op, path string) (map[string]interface{}, error) {
 	client, err := c.vaultClient()
 	if err != nil {
 		return nil, err
 	}
 	secret, err := client.List(path)
 	if err != nil {
-		return nil, err
+		return nil, &os.PathError{Op: op, Path: path, Err: err}
 	}
 	if secret == nil || len(secret.Data) == 0 {
 		return nil, nil
 	}
+	return secret.Data, nil
+}
+
+// decodeKeys decodes the keys of the data of a list response.
+func decodeKeys(data map[string]interface{}) ([]string, error) {
 	var aux struct {
-		Data struct {
-			Keys []string `mapstructure:"keys"`
-		} `json:"data"`
+		Keys []string `mapstructure:"keys"`
 	}
-	if err := mapstructure.Decode(secret.Data, &aux); err != nil {
+	if err := mapstructure.Decode(data, &aux); err != nil {
 		return nil, err
 	}
-	return aux.Data.Keys, nil
+	return aux.Keys, nil
 }
 
 // ReadSecretMetadata returns the metadata of the secret at the specified path.
@@ -466,23 +1002,35 @@ func (c *Client) ReadSecretMetadata(path string) (SecretMetadata, error) {
 	if err != nil {
 		return SecretMetadata{}, err
 	}
-	secret, err := client.List(path)
+	secret, err := client.Read(path)
 	if err != nil {
-		return SecretMetadata{}, err
+		return SecretMetadata{}, &os.PathError{Op: "ReadSecretMetadata", Path: path, Err: err}
 	}
 	if secret == nil || len(secret.Data) == 0 {
 		return SecretMetadata{}, nil
 	}
-	var aux struct {
-		Data SecretMetadata `mapstructure:"data"`
-	}
-	if err := mapstructure.Decode(secret.Data, &aux); err != nil {
+	var md SecretMetadata
+	if err := decode(secret.Data, &md); err != nil {
 		return SecretMetadata{}, err
 	}
-	return aux.Data, nil
+	return md, nil
+}
+
+// ExistsSecret reports whether a secret is stored at the specified path. The
+// secret metadata is read instead of the data, so a secret whose latest version
+// is deleted or destroyed still exists. An error is returned only if the
+// metadata could not be read.
+func (c *Client) ExistsSecret(path string) (bool, error) {
+	md, err := c.ReadSecretMetadata(path)
+	if err != nil {
+		return false, err
+	}
+	return md.CurrentVersion > 0 || len(md.Versions) > 0, nil
 }
 
 // WriteSecretMetadata updates the secret configuration at the specified path.
+// Zero fields of cfg are not sent, so they leave the settings unchanged; use
+// UpdateSecretMetadata to turn off CAS or reset a setting to zero.
 //
 // See https://www.vaultproject.io/api-docs/secret/kv/kv-v2#update-metadata.
 func (c *Client) WriteSecretMetadata(path string, cfg SecretConfig) error {
@@ -502,8 +1050,10 @@ func (c *Client) WriteSecretMetadata(path string, cfg SecretConfig) error {
 	if err := json.Unmarshal(b, &data); err != nil {
 		return err
 	}
-	_, err = client.Write(path, data)
-	return err
+	if _, err := client.Write(path, data); err != nil {
+		return &os.PathError{Op: "WriteSecretMetadata", Path: path, Err: err}
+	}
+	return nil
 }
 
 // DeleteSecretMetadata permanently deletes the secret metadata and all versions
@@ -519,36 +1069,256 @@ func (c *Client) DeleteSecretMetadata(path string) error {
 	if err != nil {
 		return err
 	}
-	_, err = client.Delete(path)
-	return err
+	if c.skipDryRun(Action{Op: "DeleteSecretMetadata", Path: path}) {
+		return nil
+	}
+	if _, err := client.Delete(path); err != nil {
+		return &os.PathError{Op: "DeleteSecretMetadata", Path: path, Err: err}
+	}
+	return nil
 }
 
 var pathJoin = path.Join
 
+// decode decodes the raw secret data returned by Vault into output using the
+// JSON field names of the KVv2 types. Timestamps are parsed as RFC 3339
+// strings, with empty strings decoding to the zero time. Integers, such as
+// version numbers, are parsed from numbers and numeric strings. The Version of
+// each SecretVersion in a version map is set from its map key.
+func decode(input, output interface{}) error {
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(decodeVersionKeys, decodeTime, decodeDuration, decodeInt),
+		TagName:    "json",
+		Result:     output,
+	})
+	if err != nil {
+		return err
+	}
+	return dec.Decode(input)
+}
+
+// decodeVersionKeys sets the "version" field of each version in the raw
+// version metadata map to its map key, which holds the version number that
+// Vault does not include in the version metadata itself.
+func decodeVersionKeys(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if to != reflect.TypeOf(map[string]SecretVersion(nil)) {
+		return data, nil
+	}
+	versions, ok := data.(map[string]interface{})
+	if !ok {
+		return data, nil
+	}
+	out := make(map[string]interface{}, len(versions))
+	for k, v := range versions {
+		if md, ok := v.(map[string]interface{}); ok {
+			withVersion := make(map[string]interface{}, len(md)+1)
+			for mk, mv := range md {
+				withVersion[mk] = mv
+			}
+			withVersion["version"] = k
+			v = withVersion
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+// decodeInt converts floats, json.Numbers and strings holding integral values
+// to int64, so they are decoded into int fields without being truncated.
+func decodeInt(from, to reflect.Type, data interface{}) (interface{}, error) {
+	switch to.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+	default:
+		return data, nil
+	}
+	var f float64
+	switch v := data.(type) {
+	case float64:
+		f = v
+	case float32:
+		f = float64(v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		var err error
+		if f, err = v.Float64(); err != nil {
+			return nil, fmt.Errorf("kv2: cannot parse %q as an integer", v)
+		}
+	case string:
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i, nil
+		}
+		var err error
+		if f, err = strconv.ParseFloat(v, 64); err != nil {
+			return nil, fmt.Errorf("kv2: cannot parse %q as an integer", v)
+		}
+	default:
+		return data, nil
+	}
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return nil, fmt.Errorf("kv2: %v is not an integer", f)
+	}
+	return int64(f), nil
+}
+
+// decodeDuration parses Go duration strings such as "3h25m19s", the format in
+// which Vault returns durations.
+func decodeDuration(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to != reflect.TypeOf(time.Duration(0)) {
+		return data, nil
+	}
+	if data.(string) == "" {
+		return time.Duration(0), nil
+	}
+	return time.ParseDuration(data.(string))
+}
+
+func decodeTime(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to != reflect.TypeOf(time.Time{}) {
+		return data, nil
+	}
+	if data.(string) == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, data.(string))
+}
+
 func (c *Client) secretPath(path string, metadata bool) (string, error) {
+	if metadata {
+		return c.endpointPath("metadata", path)
+	}
+	return c.endpointPath("data", path)
+}
+
+// cipherPath returns the path of the secret relative to the mount, including
+// the prefix of a Client returned by Sub, which field ciphertexts are bound to.
+func (c *Client) cipherPath(path string) string {
+	return pathJoin(c.prefix, path)
+}
+
+func (c *Client) endpointPath(endpoint, path string) (string, error) {
 	if path == "" {
 		return "", errors.New("kv2: secret path is empty")
 	}
-	if c.mountPath == "" {
-		c.mountPath = defaultMountPath
+	if err := checkSecretPath(path); err != nil {
+		return "", err
 	}
-	fields := []string{c.mountPath}
-	if metadata {
-		fields = append(fields, "metadata")
-	} else {
-		fields = append(fields, "data")
+	return pathJoin(c.endpointRoot(endpoint), path), nil
+}
+
+// endpointRoot returns the path of the endpoint at the root of the mount, or
+// of the prefix of a Client returned by Sub. With WithRawPaths, it is the root
+// of the mount or prefix itself.
+func (c *Client) endpointRoot(endpoint string) string {
+	if c.rawPaths {
+		return pathJoin(c.mountPath, c.prefix)
+	}
+	return pathJoin(c.mountPath, endpoint, c.prefix)
+}
+
+// checkSecretPath rejects secret paths that could escape the endpoint they are
+// joined to, such as "../metadata/foo" escaping the "data" endpoint.
+func checkSecretPath(path string) error {
+	if strings.HasPrefix(path, "/") {
+		return fmt.Errorf("%w %q: must not start with a slash", ErrInvalidSecretPath, path)
+	}
+	for _, seg := range strings.Split(path, "/") {
+		if seg == "." || seg == ".." {
+			return fmt.Errorf("%w %q: must not contain %q segments", ErrInvalidSecretPath, path, seg)
+		}
 	}
-	return pathJoin(append(fields, path)...), nil
+	return nil
 }
 
+// cleanMountPath returns the mount path with a single leading slash, no
+// trailing slash and no repeated slashes, so the mount is joined with the
+// secret paths identically however it is spelled. Mount paths that are empty
+// or contain ".." segments are rejected.
+func cleanMountPath(mount string) (string, error) {
+	for _, seg := range strings.Split(mount, "/") {
+		if seg == ".." {
+			return "", fmt.Errorf("kv2: invalid mount path %q", mount)
+		}
+	}
+	cleaned := strings.Trim(path.Clean("/"+mount), "/")
+	if cleaned == "" {
+		return "", fmt.Errorf("kv2: invalid mount path %q", mount)
+	}
+	return "/" + cleaned, nil
+}
+
+// vaultClient returns the Vault client used to make requests. Since every
+// request is made with it, it also reports an invalid mount path of the
+// Client.
 func (c *Client) vaultClient() (vault.LogicalClient, error) {
-	if c.client != nil {
-		return c.client, nil
+	if c.mountErr != nil {
+		return nil, c.mountErr
 	}
-	client, err := api.NewClient(api.DefaultConfig())
-	if err != nil {
-		return nil, err
+	return c.core.Logical(c.mountPath)
+}
+
+// ForMount returns a Client for the secrets engine mounted at the given path
+// with the same configuration as c, such as for a tenant-specific mount. The
+// returned Client shares the Vault client of c, so deriving a Client per
+// request does not create a new Vault client. Dry-run actions are recorded
+// separately for each Client.
+func (c *Client) ForMount(path string) *Client {
+	n := &Client{
+		mountPath:        path,
+		concurrency:      c.concurrency,
+		defaultMountPath: c.defaultMountPath,
+		core:             c.core,
+		validator:        c.validator,
+		rejectEmpty:      c.rejectEmpty,
+		dryRun:           c.dryRun,
+		normalize:        c.normalize,
+		rawPaths:         c.rawPaths,
+		casRetries:       c.casRetries,
+		autoCAS:          c.autoCAS,
+		cipher:           c.cipher,
+	}
+	if n.mountPath == "" {
+		n.mountPath = n.defaultMountPath
+	}
+	n.mountPath, n.mountErr = cleanMountPath(n.mountPath)
+	return n
+}
+
+// Sub returns a Client for the secrets under the given prefix of the mount of
+// c, such as a service-specific subtree, with the same configuration as c.
+// Secret paths of the returned Client are relative to the prefix, so
+// sub.ReadSecretLatest("db") reads the "apps/myservice/db" secret of the mount:
+//
+//    sub := c.Sub("apps/myservice")
+//
+// Like ForMount, the returned Client shares the Vault client of c. A prefix
+// that starts with a slash or contains "." or ".." segments is rejected with
+// ErrInvalidSecretPath by every request of the returned Client.
+func (c *Client) Sub(prefix string) *Client {
+	n := c.ForMount(c.mountPath)
+	n.mountErr = c.mountErr
+	if n.mountErr == nil {
+		n.mountErr = checkSecretPath(prefix)
+	}
+	n.prefix = pathJoin(c.prefix, prefix)
+	return n
+}
+
+// Close closes the idle connections of the HTTP client the Client makes
+// requests with, if the Client owns it: the client set with WithHTTPClient or
+// the default HTTP client of a Vault client created by the Client. Clients set
+// with WithLogicalClient or WithAPIClient are owned by the caller and left
+// unchanged. Close is safe to call on a nil Client and more than once, and the
+// Client remains usable afterwards, opening new connections as needed.
+//
+// Close does not create the Vault client, so it does nothing before the first
+// request. Clients returned by ForMount and Sub share the HTTP client of c,
+// so Close also closes their idle connections.
+func (c *Client) Close() error {
+	if c == nil {
+		return nil
 	}
-	c.client = client.Logical()
-	return c.client, nil
+	c.core.Close()
+	return nil
 }
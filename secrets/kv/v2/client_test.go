@@ -0,0 +1,3112 @@
+package kv_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/vault/api"
+	"github.com/mwalto7/vault"
+	kv "github.com/mwalto7/vault/secrets/kv/v2"
+	"github.com/mwalto7/vault/vaultmock"
+	"github.com/mwalto7/vault/vaultotel"
+	"github.com/mwalto7/vault/vaulttest"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestClient_PatchSecret(t *testing.T) {
+	created := time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC)
+	tt := []struct {
+		name    string
+		path    string
+		data    map[string]interface{}
+		version kv.SecretVersion
+		err     error
+	}{
+		{
+			name: "ErrPatchPath",
+			path: "test",
+			data: map[string]interface{}{"foo": "bar"},
+			err:  errors.New("error"),
+		},
+		{
+			name:    "OK",
+			path:    "test",
+			data:    map[string]interface{}{"foo": "bar", "baz": nil},
+			version: kv.SecretVersion{CreatedTime: created, Version: 2},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			expect := m.EXPECT().JSONMergePatch(context.Background(), "/secret/data/"+tc.path, map[string]interface{}{"data": tc.data})
+			if tc.err != nil {
+				expect.Return(nil, tc.err)
+			} else {
+				expect.Return(&api.Secret{Data: map[string]interface{}{
+					"created_time":  "2020-09-01T12:00:00Z",
+					"deletion_time": "",
+					"destroyed":     false,
+					"version":       json.Number("2"),
+				}}, nil)
+			}
+
+			version, err := kv.NewClient("", kv.WithLogicalClient(m)).PatchSecret(tc.path, tc.data)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("err: got %v, want %v", err, tc.err)
+			}
+			if !reflect.DeepEqual(version, tc.version) {
+				t.Fatalf("version: got %v, want %v", version, tc.version)
+			}
+		})
+	}
+}
+
+func TestClient_ToK8sSecret(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Read("/secret/data/test").Return(&api.Secret{Data: map[string]interface{}{
+		"data": map[string]interface{}{
+			"username": "admin",
+			"port":     json.Number("5432"),
+			"tls":      map[string]interface{}{"enabled": true},
+		},
+		"metadata": map[string]interface{}{
+			"created_time":  "2020-09-01T12:00:00Z",
+			"deletion_time": "",
+			"destroyed":     false,
+			"version":       json.Number("1"),
+		},
+	}}, nil)
+
+	b, err := kv.NewClient("", kv.WithLogicalClient(m)).ToK8sSecret("test", "db", "apps")
+	if err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	want := `{
+  "apiVersion": "v1",
+  "kind": "Secret",
+  "metadata": {
+    "name": "db",
+    "namespace": "apps"
+  },
+  "type": "Opaque",
+  "data": {
+    "port": "NTQzMg==",
+    "tls": "eyJlbmFibGVkIjp0cnVlfQ==",
+    "username": "YWRtaW4="
+  }
+}`
+	if got := string(b); got != want {
+		t.Fatalf("manifest: got %s, want %s", got, want)
+	}
+}
+
+func TestClient_ReadSecretSubkeys(t *testing.T) {
+	subkeys := map[string]interface{}{
+		"foo": nil,
+		"bar": map[string]interface{}{"baz": nil},
+	}
+	tt := []struct {
+		name    string
+		version int
+		depth   int
+		params  map[string][]string
+	}{
+		{name: "Latest"},
+		{name: "Version", version: 2, params: map[string][]string{"version": {"2"}}},
+		{name: "VersionDepth", version: 2, depth: 1, params: map[string][]string{"version": {"2"}, "depth": {"1"}}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			secret := &api.Secret{Data: map[string]interface{}{
+				"subkeys":  subkeys,
+				"metadata": map[string]interface{}{"version": json.Number("2")},
+			}}
+			if tc.params == nil {
+				m.EXPECT().Read("/secret/subkeys/test").Return(secret, nil)
+			} else {
+				m.EXPECT().ReadWithData("/secret/subkeys/test", tc.params).Return(secret, nil)
+			}
+
+			got, err := kv.NewClient("", kv.WithLogicalClient(m)).ReadSecretSubkeys("test", tc.version, tc.depth)
+			if err != nil {
+				t.Fatalf("err: got %v, want nil", err)
+			}
+			if !reflect.DeepEqual(got, subkeys) {
+				t.Fatalf("subkeys: got %v, want %v", got, subkeys)
+			}
+		})
+	}
+}
+
+func TestClient_ReadSecretRenamed(t *testing.T) {
+	tt := []struct {
+		name   string
+		rename map[string]string
+		opts   []kv.RenameOption
+		data   map[string]interface{}
+		err    bool
+	}{
+		{
+			name:   "PassThrough",
+			rename: map[string]string{"user": "USERNAME"},
+			data:   map[string]interface{}{"USERNAME": "admin", "pass": "secret"},
+		},
+		{
+			name:   "DropUnmapped",
+			rename: map[string]string{"user": "USERNAME"},
+			opts:   []kv.RenameOption{kv.DropUnmapped()},
+			data:   map[string]interface{}{"USERNAME": "admin"},
+		},
+		{
+			name:   "ErrCollision",
+			rename: map[string]string{"user": "login", "pass": "login"},
+			err:    true,
+		},
+		{
+			name:   "ErrCollisionPassThrough",
+			rename: map[string]string{"user": "pass"},
+			err:    true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().Read("/secret/data/test").Return(&api.Secret{Data: map[string]interface{}{
+				"data": map[string]interface{}{"user": "admin", "pass": "secret"},
+			}}, nil)
+
+			data, err := kv.NewClient("", kv.WithLogicalClient(m)).ReadSecretRenamed("test", tc.rename, tc.opts...)
+			if (err != nil) != tc.err {
+				t.Fatalf("err: got %v, want error %t", err, tc.err)
+			}
+			if !reflect.DeepEqual(data, tc.data) {
+				t.Fatalf("data: got %v, want %v", data, tc.data)
+			}
+		})
+	}
+}
+
+func TestMultiClient_Read(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Read("/team-a/data/test").Return(&api.Secret{Data: map[string]interface{}{
+		"data": map[string]interface{}{"team": "a"},
+	}}, nil)
+	m.EXPECT().Read("/team-b/data/test").Return(&api.Secret{Data: map[string]interface{}{
+		"data": map[string]interface{}{"team": "b"},
+	}}, nil)
+
+	c := kv.NewMultiClient(map[string]string{"a": "/team-a", "b": "/team-b"}, kv.WithLogicalClient(m))
+	if got, want := c.ListMounts(), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("mounts: got %v, want %v", got, want)
+	}
+	for _, name := range []string{"a", "b"} {
+		secret, err := c.Read(name, "test")
+		if err != nil {
+			t.Fatalf("err: got %v, want nil", err)
+		}
+		if got := secret.Data["team"]; got != name {
+			t.Fatalf("team: got %v, want %v", got, name)
+		}
+	}
+	if _, err := c.Read("c", "test"); !errors.Is(err, kv.ErrUnknownMount) {
+		t.Fatalf("err: got %v, want %v", err, kv.ErrUnknownMount)
+	}
+}
+
+func TestMultiClient_Mount(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+
+	c := kv.NewMultiClient(map[string]string{"a": "/team-a"}, kv.WithLogicalClient(m), kv.WithDryRun(true))
+	a, err := c.Mount("a")
+	if err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if again, err := c.Mount("a"); err != nil || again != a {
+		t.Fatalf("second Mount: got %p, %v, want %p, nil", again, err, a)
+	}
+	if err := a.DeleteSecretLatest("test"); err != nil {
+		t.Fatalf("DeleteSecretLatest: err: got %v, want nil", err)
+	}
+	want := []kv.Action{{Op: "DeleteSecretLatest", Path: "/team-a/data/test"}}
+	if got := a.PlannedActions(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("planned actions: got %+v, want %+v", got, want)
+	}
+}
+
+func TestClient_ReadSecretVersion(t *testing.T) {
+	tt := []struct {
+		name   string
+		secret *api.Secret
+		err    error
+	}{
+		{
+			name:   "ErrSecretNotFound",
+			secret: nil,
+			err:    kv.ErrSecretNotFound,
+		},
+		{
+			name: "ErrSecretDeleted",
+			secret: &api.Secret{Data: map[string]interface{}{
+				"data": nil,
+				"metadata": map[string]interface{}{
+					"created_time":  "2020-09-01T12:00:00Z",
+					"deletion_time": "2020-09-02T12:00:00Z",
+					"destroyed":     false,
+					"version":       json.Number("1"),
+				},
+			}},
+			err: kv.ErrSecretNotFound,
+		},
+		{
+			name: "ErrVersionDestroyed",
+			secret: &api.Secret{Data: map[string]interface{}{
+				"data": nil,
+				"metadata": map[string]interface{}{
+					"created_time":  "2020-09-01T12:00:00Z",
+					"deletion_time": "",
+					"destroyed":     true,
+					"version":       json.Number("1"),
+				},
+			}},
+			err: kv.ErrVersionDestroyed,
+		},
+		{
+			name: "OK",
+			secret: &api.Secret{Data: map[string]interface{}{
+				"data":     map[string]interface{}{"foo": "bar"},
+				"metadata": map[string]interface{}{"version": json.Number("1")},
+			}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().ReadWithData("/secret/data/test", map[string][]string{"version": {"1"}}).Return(tc.secret, nil)
+
+			secret, err := kv.NewClient("", kv.WithLogicalClient(m)).ReadSecretVersion("test", 1)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("err: got %v, want %v", err, tc.err)
+			}
+			if destroyed := errors.Is(err, kv.ErrVersionDestroyed); destroyed != (tc.err == kv.ErrVersionDestroyed) {
+				t.Fatalf("err: got %v, want destroyed %t", err, !destroyed)
+			}
+			if tc.err == nil && secret.Metadata.Version != 1 {
+				t.Fatalf("version: got %d, want 1", secret.Metadata.Version)
+			}
+		})
+	}
+}
+
+func TestSecret_Redacted(t *testing.T) {
+	secret := kv.Secret{Data: map[string]interface{}{
+		"password": "hunter2",
+		"port":     json.Number("5432"),
+		"db": map[string]interface{}{
+			"hosts": []interface{}{"a", "b"},
+			"tls":   map[string]interface{}{"key": "secret-key"},
+		},
+	}}
+	tt := []struct {
+		name string
+		opts []kv.RedactOption
+		want map[string]interface{}
+	}{
+		{
+			name: "Default",
+			want: map[string]interface{}{
+				"password": "***",
+				"port":     "***",
+				"db": map[string]interface{}{
+					"hosts": []interface{}{"***", "***"},
+					"tls":   map[string]interface{}{"key": "***"},
+				},
+			},
+		},
+		{
+			name: "PlaceholderRevealLength",
+			opts: []kv.RedactOption{kv.RedactPlaceholder("x"), kv.RevealLength()},
+			want: map[string]interface{}{
+				"password": "x(7)",
+				"port":     "x",
+				"db": map[string]interface{}{
+					"hosts": []interface{}{"x(1)", "x(1)"},
+					"tls":   map[string]interface{}{"key": "x(10)"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := secret.Redacted(tc.opts...); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("redacted: got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewClient_WithNamespace(t *testing.T) {
+	var namespaces []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		namespaces = append(namespaces, r.Header.Get("X-Vault-Namespace"))
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprint(w, `{"data":{"data":{"foo":"bar"},"metadata":{"version":1}}}`)
+		default:
+			fmt.Fprint(w, `{"data":{"version":2}}`)
+		}
+	}))
+	defer srv.Close()
+	setenv(t, "VAULT_ADDR", srv.URL)
+
+	c := kv.NewClient("", kv.WithNamespace("team-a"))
+	if _, err := c.ReadSecretLatest("test"); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if _, err := c.WriteSecretLatest("test", map[string]interface{}{"foo": "baz"}); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if want := []string{"team-a", "team-a"}; !reflect.DeepEqual(namespaces, want) {
+		t.Fatalf("namespaces: got %v, want %v", namespaces, want)
+	}
+}
+
+func TestNewClient_WithRawPaths(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Read("/secret/app").Return(&api.Secret{Data: map[string]interface{}{
+		"data":     map[string]interface{}{"foo": "bar"},
+		"metadata": map[string]interface{}{"version": json.Number("1")},
+	}}, nil)
+	m.EXPECT().Write("/secret/app", gomock.Any()).Return(&api.Secret{Data: map[string]interface{}{
+		"version": json.Number("2"),
+	}}, nil)
+	m.EXPECT().Read("/secret/metadata/app").Return(&api.Secret{Data: map[string]interface{}{
+		"current_version": json.Number("2"),
+	}}, nil)
+
+	c := kv.NewClient("", kv.WithLogicalClient(m), kv.WithRawPaths())
+	if _, err := c.ReadSecretLatest("app"); err != nil {
+		t.Fatalf("read: err: got %v, want nil", err)
+	}
+	if _, err := c.WriteSecretLatest("app", map[string]interface{}{"foo": "baz"}); err != nil {
+		t.Fatalf("write: err: got %v, want nil", err)
+	}
+	if _, err := c.ReadSecretMetadata("metadata/app"); err != nil {
+		t.Fatalf("metadata: err: got %v, want nil", err)
+	}
+	if got, want := c.DataPath("app"), "secret/app"; got != want {
+		t.Fatalf("DataPath: got %q, want %q", got, want)
+	}
+}
+
+func TestNewClient_WithRequestIDFunc(t *testing.T) {
+	var ids []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get("X-Request-Id"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"data":{"foo":"bar"},"metadata":{"version":1}}}`)
+	}))
+	defer srv.Close()
+	setenv(t, "VAULT_ADDR", srv.URL)
+
+	var n int
+	requestID := kv.WithRequestIDFunc(func() string {
+		n++
+		return fmt.Sprintf("req-%d", n)
+	})
+	c := kv.NewClient("", requestID)
+	for i := 0; i < 2; i++ {
+		if _, err := c.ReadSecretLatest("test"); err != nil {
+			t.Fatalf("err: got %v, want nil", err)
+		}
+	}
+	if want := []string{"req-1", "req-2"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("request IDs: got %v, want %v", ids, want)
+	}
+
+	apiClient, err := api.NewClient(api.DefaultConfig())
+	if err != nil {
+		t.Fatalf("api.NewClient: err: got %v, want nil", err)
+	}
+	if _, err := kv.NewClient("", kv.WithAPIClient(apiClient), requestID).ReadSecretLatest("test"); err == nil {
+		t.Fatal("WithAPIClient: err: got nil, want error")
+	}
+	setenv(t, "VAULT_ADDR", "unix:///tmp/vault.sock")
+	if _, err := kv.NewClient("", requestID).ReadSecretLatest("test"); err == nil {
+		t.Fatal("unix socket: err: got nil, want error")
+	}
+}
+
+func TestNewClient_WithUserAgent(t *testing.T) {
+	var agents []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents = append(agents, r.Header.Get("User-Agent"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"data":{"foo":"bar"},"metadata":{"version":1}}}`)
+	}))
+	defer srv.Close()
+	setenv(t, "VAULT_ADDR", srv.URL)
+
+	if _, err := kv.NewClient("", kv.WithUserAgent("billing-service/1.2")).ReadSecretLatest("test"); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	apiClient, err := api.NewClient(api.DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kv.NewClient("", kv.WithAPIClient(apiClient), kv.WithUserAgent("billing-service/1.2")).ReadSecretLatest("test"); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if want := []string{"billing-service/1.2", "billing-service/1.2"}; !reflect.DeepEqual(agents, want) {
+		t.Fatalf("user agents: got %v, want %v", agents, want)
+	}
+	if got := apiClient.Headers().Get("User-Agent"); got != "" {
+		t.Fatalf("WithAPIClient client modified: User-Agent %q", got)
+	}
+}
+
+func TestClient_Close(t *testing.T) {
+	closed := make(chan struct{}, 1)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"data":{"foo":"bar"},"metadata":{"version":1}}}`)
+	}))
+	srv.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			closed <- struct{}{}
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+	setenv(t, "VAULT_ADDR", srv.URL)
+
+	c := kv.NewClient("")
+	if _, err := c.ReadSecretLatest("test"); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: err: got %v, want nil", err)
+	}
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("idle connection was not closed")
+	}
+	if _, err := c.ReadSecretLatest("test"); err != nil {
+		t.Fatalf("read after Close: err: got %v, want nil", err)
+	}
+
+	var nilClient *kv.Client
+	if err := nilClient.Close(); err != nil {
+		t.Fatalf("nil Close: err: got %v, want nil", err)
+	}
+	if err := kv.NewClient("", kv.WithLogicalClient(vaultmock.NewLogicalClient(gomock.NewController(t)))).Close(); err != nil {
+		t.Fatalf("logical client Close: err: got %v, want nil", err)
+	}
+}
+
+// closeCountingTransport counts the calls to CloseIdleConnections.
+type closeCountingTransport struct {
+	http.RoundTripper
+	closes int
+}
+
+func (t *closeCountingTransport) CloseIdleConnections() {
+	t.closes++
+}
+
+func TestClient_Close_BeforeFirstUse(t *testing.T) {
+	tr := &closeCountingTransport{RoundTripper: http.DefaultTransport}
+	c := kv.NewClient("", kv.WithHTTPClient(&http.Client{Transport: tr}))
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: err: got %v, want nil", err)
+	}
+	if tr.closes != 0 {
+		t.Fatalf("closes: got %d, want 0", tr.closes)
+	}
+}
+
+func setenv(t *testing.T, key, value string) {
+	t.Helper()
+	prev, ok := os.LookupEnv(key)
+	if err := os.Setenv(key, value); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, prev)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+func TestClient_WaitForState(t *testing.T) {
+	metadata := func(destroyed bool) *api.Secret {
+		return &api.Secret{Data: map[string]interface{}{
+			"current_version": json.Number("1"),
+			"versions": map[string]interface{}{
+				"1": map[string]interface{}{
+					"created_time":  "2020-09-01T12:00:00Z",
+					"deletion_time": "",
+					"destroyed":     destroyed,
+					"version":       json.Number("1"),
+				},
+			},
+		}}
+	}
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	gomock.InOrder(
+		m.EXPECT().Read("/secret/metadata/test").Return(metadata(false), nil),
+		m.EXPECT().Read("/secret/metadata/test").Return(nil, errors.New("transient")),
+		m.EXPECT().Read("/secret/metadata/test").Return(metadata(true), nil),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := kv.NewClient("", kv.WithLogicalClient(m)).WaitForState(ctx, "test", 1, kv.StateDestroyed, time.Millisecond)
+	if err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+}
+
+func TestClient_WaitForState_Timeout(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Read("/secret/metadata/test").Return(nil, nil).AnyTimes()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := kv.NewClient("", kv.WithLogicalClient(m)).WaitForState(ctx, "test", 1, kv.StateDestroyed, time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err: got %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestClient_RollbackSecret(t *testing.T) {
+	tt := []struct {
+		name    string
+		secret  *api.Secret
+		version kv.SecretVersion
+		err     error
+	}{
+		{
+			name: "ErrSecretNotFound",
+			secret: &api.Secret{Data: map[string]interface{}{
+				"data":     nil,
+				"metadata": map[string]interface{}{"destroyed": true, "version": json.Number("1")},
+			}},
+			err: kv.ErrSecretNotFound,
+		},
+		{
+			name: "OK",
+			secret: &api.Secret{Data: map[string]interface{}{
+				"data":     map[string]interface{}{"foo": "old"},
+				"metadata": map[string]interface{}{"version": json.Number("1")},
+			}},
+			version: kv.SecretVersion{Version: 4},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().Read("/secret/metadata/test").Return(&api.Secret{Data: map[string]interface{}{
+				"current_version": json.Number("3"),
+			}}, nil)
+			m.EXPECT().ReadWithData("/secret/data/test", map[string][]string{"version": {"1"}}).Return(tc.secret, nil)
+			if tc.err == nil {
+				m.EXPECT().Write("/secret/data/test", map[string]interface{}{
+					"data":    map[string]interface{}{"foo": "old"},
+					"options": map[string]interface{}{"cas": 3},
+				}).Return(&api.Secret{Data: map[string]interface{}{"version": json.Number("4")}}, nil)
+			}
+
+			version, err := kv.NewClient("", kv.WithLogicalClient(m)).RollbackSecret("test", 1)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("err: got %v, want %v", err, tc.err)
+			}
+			if !reflect.DeepEqual(version, tc.version) {
+				t.Fatalf("version: got %v, want %v", version, tc.version)
+			}
+		})
+	}
+}
+
+func TestNewClient_WithNestedNormalization(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Read("/secret/data/test").Return(&api.Secret{Data: map[string]interface{}{
+		"data": map[string]interface{}{
+			"port": 5432.0,
+			"db": map[string]interface{}{
+				"pool": map[string]interface{}{
+					"max":     float64(10),
+					"timeout": 1.5,
+					"hosts":   []interface{}{"a", json.Number("2"), 3},
+				},
+			},
+		},
+	}}, nil)
+
+	secret, err := kv.NewClient("", kv.WithLogicalClient(m), kv.WithNestedNormalization()).ReadSecretLatest("test")
+	if err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	want := map[string]interface{}{
+		"port": json.Number("5432"),
+		"db": map[string]interface{}{
+			"pool": map[string]interface{}{
+				"max":     json.Number("10"),
+				"timeout": json.Number("1.5"),
+				"hosts":   []interface{}{"a", json.Number("2"), json.Number("3")},
+			},
+		},
+	}
+	if !reflect.DeepEqual(secret.Data, want) {
+		t.Fatalf("data: got %v, want %v", secret.Data, want)
+	}
+}
+
+func TestClient_PolicyPaths(t *testing.T) {
+	tt := []struct {
+		name  string
+		mount string
+		path  string
+		want  kv.PolicyPaths
+	}{
+		{
+			name: "DefaultMount",
+			path: "apps/web/db",
+			want: kv.PolicyPaths{
+				Data:     "secret/data/apps/web/db",
+				Metadata: "secret/metadata/apps/web/db",
+				Delete:   "secret/delete/apps/web/db",
+				Undelete: "secret/undelete/apps/web/db",
+				Destroy:  "secret/destroy/apps/web/db",
+				Subkeys:  "secret/subkeys/apps/web/db",
+			},
+		},
+		{
+			name:  "CustomMount",
+			mount: "/teams/kv",
+			path:  "db",
+			want: kv.PolicyPaths{
+				Data:     "teams/kv/data/db",
+				Metadata: "teams/kv/metadata/db",
+				Delete:   "teams/kv/delete/db",
+				Undelete: "teams/kv/undelete/db",
+				Destroy:  "teams/kv/destroy/db",
+				Subkeys:  "teams/kv/subkeys/db",
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := kv.NewClient(tc.mount).PolicyPaths(tc.path); got != tc.want {
+				t.Fatalf("paths: got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestClient_PolicyFor(t *testing.T) {
+	got := kv.NewClient("/teams/kv").PolicyFor([]string{"read", "list"}, "apps/web/*", "db")
+	want := `path "teams/kv/data/apps/web/*" {
+  capabilities = ["read"]
+}
+
+path "teams/kv/metadata/apps/web/*" {
+  capabilities = ["list"]
+}
+
+path "teams/kv/data/db" {
+  capabilities = ["read"]
+}
+
+path "teams/kv/metadata/db" {
+  capabilities = ["list"]
+}
+`
+	if got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if got, want := kv.NewClient("").PolicyFor([]string{"read"}, "db"), "path \"secret/data/db\" {\n  capabilities = [\"read\"]\n}\n"; got != want {
+		t.Fatalf("without list: got %q, want %q", got, want)
+	}
+}
+
+func TestClient_DataPath(t *testing.T) {
+	tt := []struct {
+		name         string
+		client       *kv.Client
+		path         string
+		wantData     string
+		wantMetadata string
+	}{
+		{
+			name:         "DefaultMount",
+			client:       kv.NewClient(""),
+			path:         "apps/db",
+			wantData:     "secret/data/apps/db",
+			wantMetadata: "secret/metadata/apps/db",
+		},
+		{
+			name:         "CustomMount",
+			client:       kv.NewClient("//teams/kv/"),
+			path:         "db",
+			wantData:     "teams/kv/data/db",
+			wantMetadata: "teams/kv/metadata/db",
+		},
+		{
+			name:         "Sub",
+			client:       kv.NewClient("").Sub("apps"),
+			path:         "db",
+			wantData:     "secret/data/apps/db",
+			wantMetadata: "secret/metadata/apps/db",
+		},
+		{
+			name:   "InvalidPath",
+			client: kv.NewClient(""),
+			path:   "../db",
+		},
+		{
+			name:   "InvalidMount",
+			client: kv.NewClient("../kv"),
+			path:   "db",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.client.DataPath(tc.path); got != tc.wantData {
+				t.Fatalf("DataPath: got %q, want %q", got, tc.wantData)
+			}
+			if got := tc.client.MetadataPath(tc.path); got != tc.wantMetadata {
+				t.Fatalf("MetadataPath: got %q, want %q", got, tc.wantMetadata)
+			}
+		})
+	}
+}
+
+func TestClient_Walk(t *testing.T) {
+	list := func(keys ...interface{}) *api.Secret {
+		return &api.Secret{Data: map[string]interface{}{"keys": keys}}
+	}
+	tt := []struct {
+		name  string
+		root  string
+		stop  string
+		paths []string
+	}{
+		{
+			name:  "Mount",
+			root:  "",
+			paths: []string{"apps/web/db", "apps/web/tls", "apps/worker", "root"},
+		},
+		{
+			name:  "Stop",
+			root:  "",
+			stop:  "apps/web/tls",
+			paths: []string{"apps/web/db", "apps/web/tls"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().List("/secret/metadata").Return(list("apps/", "root"), nil)
+			m.EXPECT().List("/secret/metadata/apps").Return(list("web/", "worker"), nil)
+			m.EXPECT().List("/secret/metadata/apps/web").Return(list("db", "tls"), nil)
+
+			stop := errors.New("stop")
+			var paths []string
+			err := kv.NewClient("", kv.WithLogicalClient(m)).Walk(tc.root, func(path string) error {
+				paths = append(paths, path)
+				if path == tc.stop {
+					return stop
+				}
+				return nil
+			})
+			if tc.stop != "" {
+				if !errors.Is(err, stop) {
+					t.Fatalf("err: got %v, want %v", err, stop)
+				}
+			} else if err != nil {
+				t.Fatalf("err: got %v, want nil", err)
+			}
+			if !reflect.DeepEqual(paths, tc.paths) {
+				t.Fatalf("paths: got %v, want %v", paths, tc.paths)
+			}
+		})
+	}
+}
+
+func TestClient_Walk_RawPaths(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	gomock.InOrder(
+		m.EXPECT().List("/secret").Return(&api.Secret{Data: map[string]interface{}{"keys": []interface{}{"app"}}}, nil),
+		m.EXPECT().List("/secret/metadata").Return(&api.Secret{Data: map[string]interface{}{"keys": []interface{}{"app"}}}, nil),
+	)
+
+	c := kv.NewClient("", kv.WithLogicalClient(m), kv.WithRawPaths())
+	for _, tc := range []struct{ root, path string }{{"", "app"}, {"metadata", "metadata/app"}} {
+		var paths []string
+		if err := c.Walk(tc.root, func(path string) error {
+			paths = append(paths, path)
+			return nil
+		}); err != nil {
+			t.Fatalf("%q: err: got %v, want nil", tc.root, err)
+		}
+		if want := []string{tc.path}; !reflect.DeepEqual(paths, want) {
+			t.Fatalf("%q: paths: got %v, want %v", tc.root, paths, want)
+		}
+	}
+}
+
+func TestClient_ReadSecrets(t *testing.T) {
+	const concurrency = 2
+	var running, max int32
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Read(gomock.Any()).DoAndReturn(func(path string) (*api.Secret, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			cur := atomic.LoadInt32(&max)
+			if n <= cur || atomic.CompareAndSwapInt32(&max, cur, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		if path == "/secret/data/fail" {
+			return nil, errors.New("error")
+		}
+		return &api.Secret{Data: map[string]interface{}{
+			"data": map[string]interface{}{"path": path},
+		}}, nil
+	}).Times(6)
+
+	paths := []string{"a", "b", "c", "fail", "d", "e", "a"}
+	secrets, err := kv.NewClient("", kv.WithLogicalClient(m), kv.WithConcurrency(concurrency)).ReadSecrets(paths)
+
+	var batchErr *vault.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("err: got %v, want *vault.BatchError", err)
+	}
+	if got, want := batchErr.Paths(), []string{"fail"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("failed paths: got %v, want %v", got, want)
+	}
+	if got := len(secrets); got != 5 {
+		t.Fatalf("secrets: got %d, want 5", got)
+	}
+	for _, p := range []string{"a", "b", "c", "d", "e"} {
+		if got, want := secrets[p].Data["path"], "/secret/data/"+p; got != want {
+			t.Fatalf("secret %s: got %v, want %v", p, got, want)
+		}
+	}
+	if max > concurrency {
+		t.Fatalf("concurrency: got %d, want at most %d", max, concurrency)
+	}
+}
+
+func TestClient_ReadSecretVersions(t *testing.T) {
+	c := kv.NewClient("", kv.WithLogicalClient(vaulttest.NewInMemoryLogical()), kv.WithConcurrency(2))
+	for i := 1; i <= 3; i++ {
+		if _, err := c.WriteSecretLatest("test", map[string]interface{}{"n": i}); err != nil {
+			t.Fatalf("WriteSecretLatest: err: got %v, want nil", err)
+		}
+	}
+	if err := c.DestroySecretVersion("test", 2); err != nil {
+		t.Fatalf("DestroySecretVersion: err: got %v, want nil", err)
+	}
+
+	secrets, err := c.ReadSecretVersions("test", 1, 2, 3, 7, 3)
+	var batchErr *vault.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("err: got %v, want *vault.BatchError", err)
+	}
+	if got, want := batchErr.Paths(), []string{"2", "7"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("failed versions: got %v, want %v", got, want)
+	}
+	for _, key := range batchErr.Paths() {
+		if !errors.Is(batchErr.Errors[key], kv.ErrSecretNotFound) {
+			t.Fatalf("version %s: err: got %v, want %v", key, batchErr.Errors[key], kv.ErrSecretNotFound)
+		}
+	}
+	if got := len(secrets); got != 2 {
+		t.Fatalf("secrets: got %d, want 2", got)
+	}
+	for _, v := range []int{1, 3} {
+		if got := secrets[v].Metadata.Version; got != v {
+			t.Fatalf("secret %d: version: got %d, want %d", v, got, v)
+		}
+		if got := fmt.Sprint(secrets[v].Data["n"]); got != fmt.Sprint(v) {
+			t.Fatalf("secret %d: data: got %v, want %d", v, got, v)
+		}
+	}
+
+	if _, err := c.ReadSecretVersions("test"); !errors.Is(err, kv.ErrNoVersions) {
+		t.Fatalf("no versions: err: got %v, want %v", err, kv.ErrNoVersions)
+	}
+}
+
+func TestClient_UpdateSecretMetadata(t *testing.T) {
+	tt := []struct {
+		name   string
+		update kv.MetadataUpdate
+		data   map[string]interface{}
+	}{
+		{name: "Empty", data: map[string]interface{}{}},
+		{
+			name:   "ExplicitZero",
+			update: kv.MetadataUpdate{MaxVersions: kv.Int(0), CASRequired: kv.Bool(false), DeleteVersionAfter: kv.Duration(0)},
+			data:   map[string]interface{}{"max_versions": 0, "cas_required": false, "delete_version_after": "0s"},
+		},
+		{
+			name: "Set",
+			update: kv.MetadataUpdate{
+				MaxVersions:        kv.Int(5),
+				CASRequired:        kv.Bool(true),
+				DeleteVersionAfter: kv.Duration(90 * time.Minute),
+				CustomMetadata:     map[string]string{"owner": "team-a"},
+			},
+			data: map[string]interface{}{
+				"max_versions":         5,
+				"cas_required":         true,
+				"delete_version_after": "1h30m0s",
+				"custom_metadata":      map[string]string{"owner": "team-a"},
+			},
+		},
+		{
+			name:   "ClearCustomMetadata",
+			update: kv.MetadataUpdate{CustomMetadata: map[string]string{}},
+			data:   map[string]interface{}{"custom_metadata": map[string]string{}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().Write("/secret/metadata/test", tc.data).Return(nil, nil)
+
+			if err := kv.NewClient("", kv.WithLogicalClient(m)).UpdateSecretMetadata("test", tc.update); err != nil {
+				t.Fatalf("err: got %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestClient_ReadSecretMetadata(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Read("/secret/metadata/test").Return(&api.Secret{Data: map[string]interface{}{
+		"cas_required":         true,
+		"created_time":         "2018-03-22T02:24:06.945319214Z",
+		"current_version":      json.Number("3"),
+		"delete_version_after": "3h25m19s",
+		"max_versions":         json.Number("0"),
+		"oldest_version":       json.Number("0"),
+		"updated_time":         "2018-03-22T02:36:43.986212308Z",
+		"custom_metadata":      map[string]interface{}{"foo": "abc"},
+		"versions": map[string]interface{}{
+			"1": map[string]interface{}{
+				"created_time":  "2018-03-22T02:24:06.945319214Z",
+				"deletion_time": "",
+				"destroyed":     false,
+			},
+			"2": map[string]interface{}{
+				"created_time":  "2018-03-22T02:36:33.954880664Z",
+				"deletion_time": "",
+				"destroyed":     true,
+			},
+			"3": map[string]interface{}{
+				"created_time":  "2018-03-22T02:36:43.986212308Z",
+				"deletion_time": "",
+				"destroyed":     false,
+			},
+		},
+	}}, nil)
+
+	md, err := kv.NewClient("", kv.WithLogicalClient(m)).ReadSecretMetadata("test")
+	if err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	updated := time.Date(2018, 3, 22, 2, 36, 43, 986212308, time.UTC)
+	want := kv.SecretMetadata{
+		CreatedTime:        time.Date(2018, 3, 22, 2, 24, 6, 945319214, time.UTC),
+		CurrentVersion:     3,
+		CASRequired:        true,
+		DeleteVersionAfter: 3*time.Hour + 25*time.Minute + 19*time.Second,
+		UpdatedTime:        updated,
+		CustomMetadata:     map[string]string{"foo": "abc"},
+		Versions: map[string]kv.SecretVersion{
+			"1": {CreatedTime: time.Date(2018, 3, 22, 2, 24, 6, 945319214, time.UTC), Version: 1},
+			"2": {CreatedTime: time.Date(2018, 3, 22, 2, 36, 33, 954880664, time.UTC), Destroyed: true, Version: 2},
+			"3": {CreatedTime: updated, Version: 3},
+		},
+	}
+	if !reflect.DeepEqual(md, want) {
+		t.Fatalf("metadata: got %+v, want %+v", md, want)
+	}
+}
+
+func TestClient_Warnings(t *testing.T) {
+	warnings := []string{"TTL capped"}
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	gomock.InOrder(
+		m.EXPECT().Read("/secret/data/test").Return(&api.Secret{
+			Warnings: warnings,
+			Data:     map[string]interface{}{"data": map[string]interface{}{"foo": "bar"}},
+		}, nil),
+		m.EXPECT().Write("/secret/data/test", gomock.Any()).Return(&api.Secret{
+			Warnings: warnings,
+			Data:     map[string]interface{}{"version": json.Number("2")},
+		}, nil),
+		m.EXPECT().JSONMergePatch(gomock.Any(), "/secret/data/test", gomock.Any()).Return(&api.Secret{Warnings: warnings}, nil),
+	)
+
+	c := kv.NewClient("", kv.WithLogicalClient(m))
+	s, err := c.ReadSecretLatest("test")
+	if err != nil {
+		t.Fatalf("ReadSecretLatest: err: got %v, want nil", err)
+	}
+	if !reflect.DeepEqual(s.Warnings, warnings) {
+		t.Fatalf("ReadSecretLatest: warnings: got %v, want %v", s.Warnings, warnings)
+	}
+	v, err := c.WriteSecretLatest("test", map[string]interface{}{"foo": "baz"})
+	if err != nil {
+		t.Fatalf("WriteSecretLatest: err: got %v, want nil", err)
+	}
+	if want := (kv.SecretVersion{Version: 2, Warnings: warnings}); !reflect.DeepEqual(v, want) {
+		t.Fatalf("WriteSecretLatest: got %+v, want %+v", v, want)
+	}
+	v, err = c.PatchSecret("test", map[string]interface{}{"foo": "qux"})
+	if err != nil {
+		t.Fatalf("PatchSecret: err: got %v, want nil", err)
+	}
+	if want := (kv.SecretVersion{Warnings: warnings}); !reflect.DeepEqual(v, want) {
+		t.Fatalf("PatchSecret: got %+v, want %+v", v, want)
+	}
+}
+
+func TestClient_ReadRaw(t *testing.T) {
+	want := &api.Secret{
+		RequestID: "abc",
+		Warnings:  []string{"warning"},
+		Data:      map[string]interface{}{"data": map[string]interface{}{"foo": "bar"}},
+	}
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	gomock.InOrder(
+		m.EXPECT().Read("/secret/data/test").Return(want, nil),
+		m.EXPECT().ReadWithData("/secret/data/test", map[string][]string{"version": {"2"}}).Return(want, nil),
+	)
+
+	c := kv.NewClient("", kv.WithLogicalClient(m))
+	secret, err := c.ReadRaw("test")
+	if err != nil {
+		t.Fatalf("ReadRaw: err: got %v, want nil", err)
+	}
+	if secret != want {
+		t.Fatalf("ReadRaw: got %+v, want %+v", secret, want)
+	}
+	secret, err = c.ReadRawVersion("test", 2)
+	if err != nil {
+		t.Fatalf("ReadRawVersion: err: got %v, want nil", err)
+	}
+	if secret != want {
+		t.Fatalf("ReadRawVersion: got %+v, want %+v", secret, want)
+	}
+}
+
+func TestClient_ReadSecretMetadataVersion(t *testing.T) {
+	tt := []struct {
+		name    string
+		version int
+		want    kv.SecretVersion
+		err     error
+	}{
+		{name: "Destroyed", version: 2, want: kv.SecretVersion{Destroyed: true, Version: 2}},
+		{name: "Latest", version: 3, want: kv.SecretVersion{Version: 3}},
+		{name: "ErrSecretNotFound", version: 4, err: kv.ErrSecretNotFound},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().Read("/secret/metadata/test").Return(&api.Secret{Data: map[string]interface{}{
+				"current_version": json.Number("3"),
+				"versions": map[string]interface{}{
+					"2": map[string]interface{}{"destroyed": true},
+					"3": map[string]interface{}{"destroyed": false},
+				},
+			}}, nil)
+
+			v, err := kv.NewClient("", kv.WithLogicalClient(m)).ReadSecretMetadataVersion("test", tc.version)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("err: got %v, want %v", err, tc.err)
+			}
+			if !reflect.DeepEqual(v, tc.want) {
+				t.Fatalf("version: got %+v, want %+v", v, tc.want)
+			}
+		})
+	}
+}
+
+func TestClient_ReadSecretMetadata_VersionDecoding(t *testing.T) {
+	tt := []struct {
+		name    string
+		version interface{}
+		err     bool
+	}{
+		{name: "JSONNumber", version: json.Number("3")},
+		{name: "Float", version: float64(3)},
+		{name: "String", version: "3"},
+		{name: "ErrFraction", version: 3.5, err: true},
+		{name: "ErrString", version: "three", err: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().Read("/secret/metadata/test").Return(&api.Secret{Data: map[string]interface{}{
+				"current_version": tc.version,
+				"oldest_version":  tc.version,
+				"versions": map[string]interface{}{
+					"3": map[string]interface{}{"destroyed": false},
+				},
+			}}, nil)
+
+			md, err := kv.NewClient("", kv.WithLogicalClient(m)).ReadSecretMetadata("test")
+			if (err != nil) != tc.err {
+				t.Fatalf("err: got %v, want error %t", err, tc.err)
+			}
+			if tc.err {
+				return
+			}
+			if md.CurrentVersion != 3 || md.OldestVersion != 3 || md.Versions["3"].Version != 3 {
+				t.Fatalf("metadata: got %+v, want versions 3", md)
+			}
+		})
+	}
+}
+
+func TestClient_ReadSecretVersion_VersionDecoding(t *testing.T) {
+	for _, version := range []interface{}{float64(2), "2"} {
+		m := vaultmock.NewLogicalClient(gomock.NewController(t))
+		m.EXPECT().Read("/secret/data/test").Return(&api.Secret{Data: map[string]interface{}{
+			"data":     map[string]interface{}{"foo": "bar"},
+			"metadata": map[string]interface{}{"version": version},
+		}}, nil)
+
+		secret, err := kv.NewClient("", kv.WithLogicalClient(m)).ReadSecretLatest("test")
+		if err != nil {
+			t.Fatalf("err: got %v, want nil", err)
+		}
+		if secret.Metadata.Version != 2 {
+			t.Fatalf("version %T: got %d, want 2", version, secret.Metadata.Version)
+		}
+	}
+}
+
+func TestClient_WriteSecret(t *testing.T) {
+	tt := []struct {
+		name string
+		opts kv.WriteOptions
+		data map[string]interface{}
+	}{
+		{
+			name: "NoCAS",
+			data: map[string]interface{}{"data": map[string]interface{}{"foo": "bar"}},
+		},
+		{
+			name: "CASZero",
+			opts: kv.WriteOptions{CAS: kv.Int(0)},
+			data: map[string]interface{}{
+				"data":    map[string]interface{}{"foo": "bar"},
+				"options": map[string]interface{}{"cas": 0},
+			},
+		},
+		{
+			name: "CAS",
+			opts: kv.WriteOptions{CAS: kv.Int(4)},
+			data: map[string]interface{}{
+				"data":    map[string]interface{}{"foo": "bar"},
+				"options": map[string]interface{}{"cas": 4},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().Write("/secret/data/test", tc.data).Return(&api.Secret{Data: map[string]interface{}{
+				"version": json.Number("5"),
+			}}, nil)
+
+			v, err := kv.NewClient("", kv.WithLogicalClient(m)).WriteSecret("test", map[string]interface{}{"foo": "bar"}, tc.opts)
+			if err != nil {
+				t.Fatalf("err: got %v, want nil", err)
+			}
+			if v.Version != 5 {
+				t.Fatalf("version: got %d, want 5", v.Version)
+			}
+		})
+	}
+}
+
+func TestClient_UpdateSecret(t *testing.T) {
+	latest := func(version int, count string) *api.Secret {
+		return &api.Secret{Data: map[string]interface{}{
+			"data":     map[string]interface{}{"count": count},
+			"metadata": map[string]interface{}{"version": json.Number(fmt.Sprint(version))},
+		}}
+	}
+	mismatch := &api.ResponseError{
+		StatusCode: http.StatusBadRequest,
+		Errors:     []string{"check-and-set parameter did not match the current version"},
+	}
+	written := &api.Secret{Data: map[string]interface{}{"version": json.Number("3")}}
+	increment := func(cur map[string]interface{}) (map[string]interface{}, error) {
+		if cur == nil {
+			return map[string]interface{}{"count": "1"}, nil
+		}
+		return map[string]interface{}{"count": cur["count"].(string) + "+1"}, nil
+	}
+	write := func(cas int, count string) map[string]interface{} {
+		return map[string]interface{}{
+			"data":    map[string]interface{}{"count": count},
+			"options": map[string]interface{}{"cas": cas},
+		}
+	}
+	errMutate := errors.New("mutate failed")
+
+	tt := []struct {
+		name    string
+		mutate  func(cur map[string]interface{}) (map[string]interface{}, error)
+		expect  func(m *vaultmock.LogicalClient)
+		version int
+		err     error
+	}{
+		{
+			name:   "RetryOnConflict",
+			mutate: increment,
+			expect: func(m *vaultmock.LogicalClient) {
+				gomock.InOrder(
+					m.EXPECT().Read("/secret/data/test").Return(latest(1, "1"), nil),
+					m.EXPECT().Write("/secret/data/test", write(1, "1+1")).Return(nil, mismatch),
+					m.EXPECT().Read("/secret/data/test").Return(latest(2, "2"), nil),
+					m.EXPECT().Write("/secret/data/test", write(2, "2+1")).Return(written, nil),
+				)
+			},
+			version: 3,
+		},
+		{
+			name:   "Create",
+			mutate: increment,
+			expect: func(m *vaultmock.LogicalClient) {
+				gomock.InOrder(
+					m.EXPECT().Read("/secret/data/test").Return(nil, nil),
+					m.EXPECT().Read("/secret/metadata/test").Return(nil, nil),
+					m.EXPECT().Write("/secret/data/test", write(0, "1")).Return(written, nil),
+				)
+			},
+			version: 3,
+		},
+		{
+			name:   "ErrCASMismatch",
+			mutate: increment,
+			expect: func(m *vaultmock.LogicalClient) {
+				m.EXPECT().Read("/secret/data/test").Return(latest(1, "1"), nil).Times(4)
+				m.EXPECT().Write("/secret/data/test", write(1, "1+1")).Return(nil, mismatch).Times(4)
+			},
+			err: kv.ErrCASMismatch,
+		},
+		{
+			name: "ErrMutate",
+			mutate: func(cur map[string]interface{}) (map[string]interface{}, error) {
+				return nil, errMutate
+			},
+			expect: func(m *vaultmock.LogicalClient) {
+				m.EXPECT().Read("/secret/data/test").Return(latest(1, "1"), nil)
+			},
+			err: errMutate,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			tc.expect(m)
+
+			v, err := kv.NewClient("", kv.WithLogicalClient(m)).UpdateSecret("test", tc.mutate)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("err: got %v, want %v", err, tc.err)
+			}
+			if v.Version != tc.version {
+				t.Fatalf("version: got %d, want %d", v.Version, tc.version)
+			}
+		})
+	}
+}
+
+func TestClient_DiffVersions(t *testing.T) {
+	version := func(n int, data map[string]interface{}) *api.Secret {
+		return &api.Secret{Data: map[string]interface{}{
+			"data":     data,
+			"metadata": map[string]interface{}{"version": json.Number(fmt.Sprint(n))},
+		}}
+	}
+	v1 := version(1, map[string]interface{}{"user": "admin", "password": "hunter2", "port": json.Number("5432")})
+	v2 := version(2, map[string]interface{}{"user": "admin", "password": "correct horse", "host": "db"})
+
+	tt := []struct {
+		name string
+		opts []kv.DiffOption
+		diff kv.Diff
+	}{
+		{
+			name: "ValuesHidden",
+			diff: kv.Diff{Added: []string{"host"}, Removed: []string{"port"}, Changed: []string{"password"}},
+		},
+		{
+			name: "ShowValues",
+			opts: []kv.DiffOption{kv.ShowValues()},
+			diff: kv.Diff{
+				Added:   []string{"host"},
+				Removed: []string{"port"},
+				Changed: []string{"password"},
+				Old:     map[string]interface{}{"password": "hunter2", "port": json.Number("5432")},
+				New:     map[string]interface{}{"password": "correct horse", "host": "db"},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().ReadWithData("/secret/data/test", map[string][]string{"version": {"1"}}).Return(v1, nil)
+			m.EXPECT().ReadWithData("/secret/data/test", map[string][]string{"version": {"2"}}).Return(v2, nil)
+
+			diff, err := kv.NewClient("", kv.WithLogicalClient(m)).DiffVersions("test", 1, 2, tc.opts...)
+			if err != nil {
+				t.Fatalf("err: got %v, want nil", err)
+			}
+			if !reflect.DeepEqual(diff, tc.diff) {
+				t.Fatalf("diff: got %+v, want %+v", diff, tc.diff)
+			}
+			if diff.Empty() {
+				t.Fatal("Empty: got true, want false")
+			}
+		})
+	}
+}
+
+func TestClient_PermissionDenied(t *testing.T) {
+	forbidden := &api.ResponseError{StatusCode: http.StatusForbidden, Errors: []string{"permission denied"}}
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Read("/secret/data/test").Return(nil, forbidden)
+
+	_, err := kv.NewClient("", kv.WithLogicalClient(m)).ReadSecretLatest("test")
+	if !errors.Is(err, kv.ErrPermissionDenied) || !kv.IsPermissionDenied(err) {
+		t.Fatalf("err: got %v, want %v", err, kv.ErrPermissionDenied)
+	}
+	if !errors.Is(err, forbidden) {
+		t.Fatalf("err: got %v, want to wrap %v", err, forbidden)
+	}
+}
+
+func TestClient_ExistsSecret(t *testing.T) {
+	tt := []struct {
+		name   string
+		secret *api.Secret
+		err    error
+		exists bool
+	}{
+		{name: "NotFound", secret: nil},
+		{
+			name: "Deleted",
+			secret: &api.Secret{Data: map[string]interface{}{
+				"current_version": json.Number("1"),
+				"versions": map[string]interface{}{
+					"1": map[string]interface{}{"deletion_time": "2020-09-02T12:00:00Z"},
+				},
+			}},
+			exists: true,
+		},
+		{name: "ErrPermissionDenied", err: errors.New("permission denied")},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().Read("/secret/metadata/test").Return(tc.secret, tc.err)
+
+			exists, err := kv.NewClient("", kv.WithLogicalClient(m)).ExistsSecret("test")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("err: got %v, want %v", err, tc.err)
+			}
+			if exists != tc.exists {
+				t.Fatalf("exists: got %t, want %t", exists, tc.exists)
+			}
+		})
+	}
+}
+
+func TestClient_ReadSecretLatestInto(t *testing.T) {
+	type config struct {
+		Host    string `mapstructure:"host"`
+		Port    int    `mapstructure:"port"`
+		Enabled bool   `mapstructure:"enabled"`
+	}
+	tt := []struct {
+		name   string
+		data   map[string]interface{}
+		opts   []vault.DecodeOption
+		config config
+		err    bool
+	}{
+		{
+			name:   "OK",
+			data:   map[string]interface{}{"host": "db", "port": json.Number("5432"), "enabled": true},
+			config: config{Host: "db", Port: 5432, Enabled: true},
+		},
+		{
+			name: "ErrDecode",
+			data: map[string]interface{}{"host": "db", "enabled": "true"},
+			err:  true,
+		},
+		{
+			name:   "WeaklyTypedInput",
+			data:   map[string]interface{}{"host": "db", "port": "5432", "enabled": "true"},
+			opts:   []vault.DecodeOption{vault.WeaklyTypedInput()},
+			config: config{Host: "db", Port: 5432, Enabled: true},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().Read("/secret/data/test").Return(&api.Secret{Data: map[string]interface{}{
+				"data": tc.data,
+			}}, nil)
+
+			var got config
+			err := kv.NewClient("", kv.WithLogicalClient(m)).ReadSecretLatestInto("test", &got, tc.opts...)
+			if (err != nil) != tc.err {
+				t.Fatalf("err: got %v, want error %t", err, tc.err)
+			}
+			if !tc.err && got != tc.config {
+				t.Fatalf("config: got %+v, want %+v", got, tc.config)
+			}
+		})
+	}
+}
+
+func TestClient_ReadSecretLatestInto_ErrSecretNotFound(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Read("/secret/data/test").Return(nil, nil)
+
+	var out struct{}
+	err := kv.NewClient("", kv.WithLogicalClient(m)).ReadSecretLatestInto("test", &out)
+	if !errors.Is(err, kv.ErrSecretNotFound) {
+		t.Fatalf("err: got %v, want %v", err, kv.ErrSecretNotFound)
+	}
+}
+
+func TestClient_UnwrapSecret(t *testing.T) {
+	tt := []struct {
+		name   string
+		token  string
+		secret *api.Secret
+		err    error
+		data   map[string]interface{}
+	}{
+		{
+			name:  "ErrEmptyToken",
+			token: "",
+		},
+		{
+			name:  "ErrUsedToken",
+			token: "s.used",
+			err: &api.ResponseError{
+				StatusCode: http.StatusBadRequest,
+				Errors:     []string{"wrapping token is not valid or does not exist"},
+			},
+		},
+		{
+			name:  "OK",
+			token: "s.token",
+			secret: &api.Secret{Data: map[string]interface{}{
+				"data":     map[string]interface{}{"foo": "bar"},
+				"metadata": map[string]interface{}{"version": json.Number("1")},
+			}},
+			data: map[string]interface{}{"foo": "bar"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			if tc.token != "" {
+				m.EXPECT().Unwrap(tc.token).Return(tc.secret, tc.err)
+			}
+
+			secret, err := kv.NewClient("", kv.WithLogicalClient(m)).UnwrapSecret(tc.token)
+			if tc.data == nil {
+				if !errors.Is(err, kv.ErrSecretNotFound) {
+					t.Fatalf("err: got %v, want %v", err, kv.ErrSecretNotFound)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err: got %v, want nil", err)
+			}
+			if !reflect.DeepEqual(secret.Data, tc.data) {
+				t.Fatalf("data: got %v, want %v", secret.Data, tc.data)
+			}
+		})
+	}
+}
+
+func TestClient_ReadSecretWrapped(t *testing.T) {
+	var wrapTTL, path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wrapTTL, path = r.Header.Get("X-Vault-Wrap-TTL"), r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"wrap_info":{"token":"s.wrapped","ttl":300}}`)
+	}))
+	defer srv.Close()
+	setenv(t, "VAULT_ADDR", srv.URL)
+
+	token, err := kv.NewClient("").ReadSecretWrapped("test", 5*time.Minute)
+	if err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if token != "s.wrapped" {
+		t.Fatalf("token: got %q, want %q", token, "s.wrapped")
+	}
+	if wrapTTL != "5m0s" {
+		t.Fatalf("wrap TTL: got %q, want %q", wrapTTL, "5m0s")
+	}
+	if path != "/v1/secret/data/test" {
+		t.Fatalf("path: got %q, want %q", path, "/v1/secret/data/test")
+	}
+
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	if _, err := kv.NewClient("", kv.WithLogicalClient(m)).ReadSecretWrapped("test", time.Minute); err == nil {
+		t.Fatal("err: got nil, want error for injected logical client")
+	}
+}
+
+func TestClient_ReadSecretWrappedInfo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"wrap_info":{"token":"s.wrapped","accessor":"acc","ttl":300,"creation_time":"2021-06-01T12:00:00Z","creation_path":"secret/data/test"}}`)
+	}))
+	defer srv.Close()
+	setenv(t, "VAULT_ADDR", srv.URL)
+
+	info, err := kv.NewClient("").ReadSecretWrappedInfo("test", 5*time.Minute)
+	if err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	want := vault.WrapInfo{
+		Token:        "s.wrapped",
+		Accessor:     "acc",
+		TTL:          5 * time.Minute,
+		CreationTime: time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC),
+		CreationPath: "secret/data/test",
+	}
+	if !reflect.DeepEqual(info, want) {
+		t.Fatalf("info: got %+v, want %+v", info, want)
+	}
+}
+
+func TestNewClient_WithRequestTimeout(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().ReadWithContext(gomock.Any(), "/secret/data/test").DoAndReturn(
+		func(ctx context.Context, path string) (*api.Secret, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+
+	c := kv.NewClient("", kv.WithLogicalClient(m), kv.WithRequestTimeout(10*time.Millisecond))
+	_, err := c.ReadSecretLatest("test")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err: got %v, want %v", err, context.DeadlineExceeded)
+	}
+	var pathErr *os.PathError
+	if !errors.As(err, &pathErr) || pathErr.Path != "/secret/data/test" {
+		t.Fatalf("err: got %v, want *os.PathError for %q", err, "/secret/data/test")
+	}
+}
+
+func TestClient_CopySecretTo(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	data := map[string]interface{}{"foo": "bar"}
+	gomock.InOrder(
+		m.EXPECT().Read("/secret/data/src").Return(&api.Secret{Data: map[string]interface{}{
+			"data":     data,
+			"metadata": map[string]interface{}{"version": json.Number("3")},
+		}}, nil),
+		m.EXPECT().Write("/other/data/dst", map[string]interface{}{"data": data}).Return(&api.Secret{Data: map[string]interface{}{
+			"version": json.Number("1"),
+		}}, nil),
+		m.EXPECT().Read("/secret/metadata/src").Return(&api.Secret{Data: map[string]interface{}{
+			"current_version": json.Number("3"),
+			"custom_metadata": map[string]interface{}{"owner": "team-a"},
+		}}, nil),
+		m.EXPECT().Write("/other/metadata/dst", map[string]interface{}{
+			"custom_metadata": map[string]interface{}{"owner": "team-a"},
+		}).Return(nil, nil),
+	)
+
+	src := kv.NewClient("", kv.WithLogicalClient(m))
+	dst := kv.NewClient("/other", kv.WithLogicalClient(m))
+	if err := src.CopySecretTo(dst, "src", "dst", kv.CopyCustomMetadata()); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+}
+
+func TestClient_MoveSecret(t *testing.T) {
+	read := &api.Secret{Data: map[string]interface{}{
+		"data":     map[string]interface{}{"foo": "bar"},
+		"metadata": map[string]interface{}{"version": json.Number("1")},
+	}}
+	tt := []struct {
+		name     string
+		opts     []kv.CopyOption
+		writeErr error
+		expect   func(m *vaultmock.LogicalClient)
+	}{
+		{
+			name: "SoftDelete",
+			expect: func(m *vaultmock.LogicalClient) {
+				m.EXPECT().Delete("/secret/data/src").Return(nil, nil)
+			},
+		},
+		{
+			name: "PurgeSource",
+			opts: []kv.CopyOption{kv.PurgeSource()},
+			expect: func(m *vaultmock.LogicalClient) {
+				m.EXPECT().Delete("/secret/metadata/src").Return(nil, nil)
+			},
+		},
+		{
+			name:     "ErrWriteKeepsSource",
+			writeErr: errors.New("permission denied"),
+			expect:   func(m *vaultmock.LogicalClient) {},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().Read("/secret/data/src").Return(read, nil)
+			m.EXPECT().Write("/secret/data/dst", gomock.Any()).Return(nil, tc.writeErr)
+			tc.expect(m)
+
+			err := kv.NewClient("", kv.WithLogicalClient(m)).MoveSecret("src", "dst", tc.opts...)
+			if !errors.Is(err, tc.writeErr) {
+				t.Fatalf("err: got %v, want %v", err, tc.writeErr)
+			}
+		})
+	}
+}
+
+type recordingObserver struct {
+	ops   []string
+	panic bool
+}
+
+func (o *recordingObserver) BeforeRequest(op, path string) {
+	o.ops = append(o.ops, "before "+op+" "+path)
+	if o.panic {
+		panic("observer bug")
+	}
+}
+
+func (o *recordingObserver) AfterRequest(op, path string, err error, dur time.Duration) {
+	o.ops = append(o.ops, fmt.Sprintf("after %s %s %v", op, path, err))
+	if o.panic {
+		panic("observer bug")
+	}
+}
+
+func TestNewClient_WithObserver(t *testing.T) {
+	for _, panics := range []bool{false, true} {
+		m := vaultmock.NewLogicalClient(gomock.NewController(t))
+		m.EXPECT().Delete("/secret/data/test").Return(nil, errors.New("permission denied"))
+
+		obs := &recordingObserver{panic: panics}
+		err := kv.NewClient("", kv.WithLogicalClient(m), kv.WithObserver(obs)).DeleteSecretLatest("test")
+		if err == nil {
+			t.Fatal("err: got nil, want error")
+		}
+		want := []string{"before Delete /secret/data/test", "after Delete /secret/data/test permission denied"}
+		if !reflect.DeepEqual(obs.ops, want) {
+			t.Fatalf("ops: got %q, want %q", obs.ops, want)
+		}
+	}
+}
+
+func TestNewClient_WithLatencyObserver(t *testing.T) {
+	const delay = 20 * time.Millisecond
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Read("/secret/data/test").DoAndReturn(func(string) (*api.Secret, error) {
+		time.Sleep(delay)
+		return &api.Secret{Data: map[string]interface{}{
+			"data":     map[string]interface{}{"key": "value"},
+			"metadata": map[string]interface{}{"version": json.Number("1")},
+		}}, nil
+	})
+
+	var got []string
+	var dur time.Duration
+	obs := vault.LatencyObserver(func(op, path string, err error, d time.Duration) {
+		got = append(got, fmt.Sprintf("%s %s %v", op, path, err))
+		dur = d
+	})
+	if _, err := kv.NewClient("", kv.WithLogicalClient(m), kv.WithObserver(obs)).ReadSecretLatest("test"); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if want := []string{"Read /secret/data/test <nil>"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("requests: got %q, want %q", got, want)
+	}
+	if dur < delay {
+		t.Fatalf("dur: got %v, want at least %v", dur, delay)
+	}
+}
+
+type recordingLogger struct {
+	fields []interface{}
+}
+
+func (l *recordingLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.fields = append(l.fields, msg)
+	l.fields = append(l.fields, keysAndValues...)
+}
+
+func (l *recordingLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.Debug(msg, keysAndValues...)
+}
+
+func (l *recordingLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.Debug(msg, keysAndValues...)
+}
+
+func TestNewClient_WithLogger(t *testing.T) {
+	data := map[string]interface{}{"username": "admin", "password": "hunter2"}
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Write("/secret/data/test", gomock.Any()).Return(&api.Secret{Data: map[string]interface{}{
+		"version": json.Number("4"),
+	}}, nil)
+	m.EXPECT().ReadWithData("/secret/data/test", map[string][]string{"version": {"4"}}).Return(&api.Secret{Data: map[string]interface{}{
+		"data":     data,
+		"metadata": map[string]interface{}{"version": json.Number("4")},
+	}}, nil)
+	m.EXPECT().Read("/secret/data/missing").Return(nil, errors.New("permission denied"))
+	m.EXPECT().Read("/tenants/a/data/test").Return(nil, nil)
+
+	logger := &recordingLogger{}
+	c := kv.NewClient("", kv.WithLogicalClient(m), kv.WithLogger(logger))
+	if _, err := c.WriteSecretLatest("test", data); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if _, err := c.ReadSecretVersion("test", 4); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if _, err := c.ReadSecretLatest("missing"); err == nil {
+		t.Fatal("err: got nil, want error")
+	}
+	if _, err := c.ForMount("/tenants/a/").ReadSecretLatest("test"); !errors.Is(err, vault.ErrSecretNotFound) {
+		t.Fatalf("err: got %v, want %v", err, vault.ErrSecretNotFound)
+	}
+
+	var mounts []interface{}
+	for i, f := range logger.fields {
+		if f == "mount" && i+1 < len(logger.fields) {
+			mounts = append(mounts, logger.fields[i+1])
+		}
+	}
+	if want := []interface{}{"secret", "secret", "secret", "tenants/a"}; !reflect.DeepEqual(mounts, want) {
+		t.Fatalf("mounts: got %v, want %v", mounts, want)
+	}
+	logged := fmt.Sprint(logger.fields...)
+	for _, v := range data {
+		if strings.Contains(logged, v.(string)) {
+			t.Fatalf("logged fields contain secret value %q: %v", v, logger.fields)
+		}
+	}
+	for _, want := range []string{"/secret/data/test", "/secret/data/missing", "permission denied"} {
+		if !strings.Contains(logged, want) {
+			t.Fatalf("logged fields: got %v, want %q", logger.fields, want)
+		}
+	}
+}
+
+func TestNewClient_WithTracerProvider(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().ReadWithDataWithContext(gomock.Any(), "/tenants/a/data/test", map[string][]string{"version": {"2"}}).Return(&api.Secret{Data: map[string]interface{}{
+		"data":     map[string]interface{}{"password": "hunter2"},
+		"metadata": map[string]interface{}{"version": json.Number("2")},
+	}}, nil)
+
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	c := kv.NewClient("", kv.WithLogicalClient(m), kv.WithTracerProvider(tp))
+	if _, err := c.ForMount("tenants/a").ReadSecretVersion("test", 2); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("spans: got %d, want 1", len(spans))
+	}
+	want := map[attribute.Key]string{
+		vaultotel.OperationKey: "Read",
+		vaultotel.MountKey:     "tenants/a",
+		vaultotel.PathKey:      "/tenants/a/data/test",
+		vaultotel.VersionKey:   "2",
+	}
+	got := make(map[attribute.Key]string)
+	for _, attr := range spans[0].Attributes() {
+		got[attr.Key] = attr.Value.Emit()
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("attributes: got %v, want %v", got, want)
+	}
+}
+
+func TestNewClient_MountPath(t *testing.T) {
+	tt := []struct {
+		mount string
+		err   bool
+	}{
+		{mount: "my-kv"},
+		{mount: "/my-kv"},
+		{mount: "my-kv/"},
+		{mount: "/my-kv/"},
+		{mount: "//my-kv//"},
+		{mount: "/", err: true},
+		{mount: "my-kv/..", err: true},
+		{mount: "../my-kv", err: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.mount, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			if !tc.err {
+				m.EXPECT().Read("/my-kv/data/test").Return(&api.Secret{Data: map[string]interface{}{
+					"data":     map[string]interface{}{"foo": "bar"},
+					"metadata": map[string]interface{}{"version": json.Number("1")},
+				}}, nil)
+			}
+
+			_, err := kv.NewClient(tc.mount, kv.WithLogicalClient(m)).ReadSecretLatest("test")
+			if gotErr := err != nil; gotErr != tc.err {
+				t.Fatalf("err: got %v, want error %t", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestClient_InvalidSecretPath(t *testing.T) {
+	paths := []string{
+		"../metadata/foo",
+		"foo/../../metadata/foo",
+		"foo/./bar",
+		"..",
+		"/foo",
+		"//metadata/foo",
+	}
+
+	// The mock has no expectations, so any request fails the test.
+	c := kv.NewClient("", kv.WithLogicalClient(vaultmock.NewLogicalClient(gomock.NewController(t))))
+	for _, path := range paths {
+		if _, err := c.ReadSecretLatest(path); !errors.Is(err, kv.ErrInvalidSecretPath) {
+			t.Errorf("ReadSecretLatest(%q): err: got %v, want %v", path, err, kv.ErrInvalidSecretPath)
+		}
+		if _, err := c.WriteSecretLatest(path, map[string]interface{}{"foo": "bar"}); !errors.Is(err, kv.ErrInvalidSecretPath) {
+			t.Errorf("WriteSecretLatest(%q): err: got %v, want %v", path, err, kv.ErrInvalidSecretPath)
+		}
+		if _, err := c.ReadSecretMetadata(path); !errors.Is(err, kv.ErrInvalidSecretPath) {
+			t.Errorf("ReadSecretMetadata(%q): err: got %v, want %v", path, err, kv.ErrInvalidSecretPath)
+		}
+	}
+}
+
+func TestSecretMetadata_Versions(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	md := kv.SecretMetadata{Versions: map[string]kv.SecretVersion{
+		"1":  {Destroyed: true},
+		"2":  {DeletionTime: past, Destroyed: true},
+		"3":  {DeletionTime: past},
+		"4":  {},
+		"10": {DeletionTime: future},
+		"12": {},
+	}}
+
+	if got, want := md.LiveVersions(), []int{4, 10, 12}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LiveVersions: got %v, want %v", got, want)
+	}
+	if got, want := md.DeletedVersions(), []int{3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DeletedVersions: got %v, want %v", got, want)
+	}
+	if got, want := md.DestroyedVersions(), []int{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DestroyedVersions: got %v, want %v", got, want)
+	}
+	if v, ok := md.Version(3); !ok || v.Version != 3 || !v.DeletionTime.Equal(past) {
+		t.Errorf("Version(3): got %+v, %t, want version 3 deleted at %v", v, ok, past)
+	}
+	if _, ok := md.Version(5); ok {
+		t.Error("Version(5): got true, want false")
+	}
+}
+
+func TestSecretMetadata_SortedVersions(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Read("/secret/metadata/test").Return(&api.Secret{Data: map[string]interface{}{
+		"current_version": json.Number("12"),
+		"versions": map[string]interface{}{
+			"12": map[string]interface{}{"destroyed": false},
+			"2":  map[string]interface{}{"destroyed": true},
+			"10": map[string]interface{}{"destroyed": false},
+			"9":  map[string]interface{}{"destroyed": false},
+		},
+	}}, nil)
+
+	md, err := kv.NewClient("", kv.WithLogicalClient(m)).ReadSecretMetadata("test")
+	if err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	var got []int
+	for _, v := range md.SortedVersions() {
+		got = append(got, v.Version)
+	}
+	if want := []int{2, 9, 10, 12}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("versions: got %v, want %v", got, want)
+	}
+	if v := md.Versions["10"]; v.Version != 10 {
+		t.Fatalf("Versions[\"10\"].Version: got %d, want 10", v.Version)
+	}
+}
+
+func TestClient_PruneVersions(t *testing.T) {
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339Nano)
+	metadata := &api.Secret{Data: map[string]interface{}{
+		"current_version": json.Number("6"),
+		"versions": map[string]interface{}{
+			"1": map[string]interface{}{"destroyed": true},
+			"2": map[string]interface{}{"deletion_time": past},
+			"3": map[string]interface{}{},
+			"4": map[string]interface{}{},
+			"5": map[string]interface{}{"deletion_time": past},
+			"6": map[string]interface{}{},
+		},
+	}}
+	tt := []struct {
+		name       string
+		keepLatest int
+		destroyed  []int
+	}{
+		{name: "KeepTwo", keepLatest: 2, destroyed: []int{2, 3}},
+		{name: "KeepAllLive", keepLatest: 3},
+		{name: "KeepMoreThanLive", keepLatest: 10},
+		{name: "KeepNoneKeepsCurrent", keepLatest: 0, destroyed: []int{2, 3, 4, 5}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().Read("/secret/metadata/test").Return(metadata, nil)
+			if tc.destroyed != nil {
+				m.EXPECT().Write("/secret/destroy/test", map[string]interface{}{"versions": tc.destroyed}).Return(nil, nil)
+			}
+
+			if err := kv.NewClient("", kv.WithLogicalClient(m)).PruneVersions("test", tc.keepLatest); err != nil {
+				t.Fatalf("err: got %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestClient_PruneOlderThan(t *testing.T) {
+	created := func(d time.Duration) string {
+		return time.Now().Add(-d).Format(time.RFC3339Nano)
+	}
+	metadata := &api.Secret{Data: map[string]interface{}{
+		"current_version": json.Number("5"),
+		"versions": map[string]interface{}{
+			"1": map[string]interface{}{"created_time": created(72 * time.Hour), "destroyed": true},
+			"2": map[string]interface{}{"created_time": created(48 * time.Hour), "deletion_time": created(time.Hour)},
+			"3": map[string]interface{}{"created_time": created(36 * time.Hour)},
+			"4": map[string]interface{}{"created_time": created(time.Hour)},
+			"5": map[string]interface{}{"created_time": created(30 * time.Hour)},
+		},
+	}}
+	tt := []struct {
+		name      string
+		age       time.Duration
+		destroyed []int
+	}{
+		{name: "OneDay", age: 24 * time.Hour, destroyed: []int{2, 3}},
+		{name: "TwoDays", age: 40 * time.Hour, destroyed: []int{2}},
+		{name: "NoneOlder", age: 100 * time.Hour},
+		{name: "ZeroKeepsCurrent", age: 0, destroyed: []int{2, 3, 4}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().Read("/secret/metadata/test").Return(metadata, nil)
+			if tc.destroyed != nil {
+				m.EXPECT().Write("/secret/destroy/test", map[string]interface{}{"versions": tc.destroyed}).Return(nil, nil)
+			}
+
+			if err := kv.NewClient("", kv.WithLogicalClient(m)).PruneOlderThan("test", tc.age); err != nil {
+				t.Fatalf("err: got %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestSecretMetadata_OldestLiveVersion(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	md := kv.SecretMetadata{
+		OldestVersion: 1,
+		Versions: map[string]kv.SecretVersion{
+			"1": {Destroyed: true},
+			"2": {DeletionTime: past},
+			"3": {},
+			"4": {},
+		},
+	}
+	if v, ok := md.OldestLiveVersion(); !ok || v != 3 {
+		t.Fatalf("got %d, %t, want 3, true", v, ok)
+	}
+	md.Versions = map[string]kv.SecretVersion{"1": {Destroyed: true}}
+	if v, ok := md.OldestLiveVersion(); ok {
+		t.Fatalf("no live versions: got %d, %t, want 0, false", v, ok)
+	}
+}
+
+func TestNewClient_WithDryRun(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Read("/secret/metadata/test").Return(&api.Secret{Data: map[string]interface{}{
+		"current_version": json.Number("3"),
+		"versions": map[string]interface{}{
+			"1": map[string]interface{}{},
+			"2": map[string]interface{}{},
+			"3": map[string]interface{}{},
+		},
+	}}, nil)
+
+	c := kv.NewClient("", kv.WithLogicalClient(m), kv.WithDryRun(true))
+	if err := c.PruneVersions("test", 1); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if err := c.DeleteSecretMetadata("test"); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+
+	want := []kv.Action{
+		{Op: "DestroySecretVersion", Path: "/secret/destroy/test", Versions: []int{1, 2}},
+		{Op: "DeleteSecretMetadata", Path: "/secret/metadata/test"},
+	}
+	if got := c.PlannedActions(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("planned actions: got %+v, want %+v", got, want)
+	}
+	c.ClearPlannedActions()
+	if got := c.PlannedActions(); len(got) != 0 {
+		t.Fatalf("planned actions: got %+v, want none", got)
+	}
+}
+
+func TestNewClient_WithDryRun_Composite(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+
+	c := kv.NewClient("", kv.WithLogicalClient(m), kv.WithDryRun(true))
+	if err := c.MoveSecret("src", "dst"); !errors.Is(err, kv.ErrDryRunUnsupported) {
+		t.Fatalf("MoveSecret: err: got %v, want %v", err, kv.ErrDryRunUnsupported)
+	}
+	err := c.WriteSecrets(map[string]map[string]interface{}{"test": {"foo": "bar"}}, kv.AllOrNothing())
+	if !errors.Is(err, kv.ErrDryRunUnsupported) {
+		t.Fatalf("WriteSecrets: err: got %v, want %v", err, kv.ErrDryRunUnsupported)
+	}
+	if got := c.PlannedActions(); len(got) != 0 {
+		t.Fatalf("planned actions: got %+v, want none", got)
+	}
+}
+
+func TestClient_WriteSecrets(t *testing.T) {
+	written := func(version string) *api.Secret {
+		return &api.Secret{Data: map[string]interface{}{"version": json.Number(version)}}
+	}
+	secrets := map[string]map[string]interface{}{
+		"new":          {"foo": "bar"},
+		"updated":      {"foo": "baz"},
+		"write-denied": {"foo": "qux"},
+	}
+	tt := []struct {
+		name      string
+		opts      []kv.BatchOption
+		rollback  func(m *vaultmock.LogicalClient)
+		succeeded []string
+	}{
+		{
+			name:      "Partial",
+			rollback:  func(m *vaultmock.LogicalClient) {},
+			succeeded: []string{"new", "updated"},
+		},
+		{
+			name: "AllOrNothing",
+			opts: []kv.BatchOption{kv.AllOrNothing()},
+			rollback: func(m *vaultmock.LogicalClient) {
+				m.EXPECT().Delete("/secret/metadata/new").Return(nil, nil)
+				m.EXPECT().Read("/secret/metadata/updated").Return(&api.Secret{Data: map[string]interface{}{
+					"current_version": json.Number("3"),
+				}}, nil)
+				m.EXPECT().ReadWithData("/secret/data/updated", map[string][]string{"version": {"2"}}).Return(&api.Secret{Data: map[string]interface{}{
+					"data":     map[string]interface{}{"foo": "old"},
+					"metadata": map[string]interface{}{"version": json.Number("2")},
+				}}, nil)
+				m.EXPECT().Write("/secret/data/updated", map[string]interface{}{
+					"data":    map[string]interface{}{"foo": "old"},
+					"options": map[string]interface{}{"cas": 3},
+				}).Return(written("4"), nil)
+			},
+			succeeded: []string{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().Write("/secret/data/new", map[string]interface{}{"data": secrets["new"]}).Return(written("1"), nil)
+			m.EXPECT().Write("/secret/data/updated", map[string]interface{}{"data": secrets["updated"]}).Return(written("3"), nil)
+			m.EXPECT().Write("/secret/data/write-denied", gomock.Any()).Return(nil, errors.New("permission denied"))
+			tc.rollback(m)
+
+			c := kv.NewClient("", kv.WithLogicalClient(m), kv.WithConcurrency(1))
+			err := c.WriteSecrets(secrets, tc.opts...)
+			var batchErr *vault.BatchError
+			if !errors.As(err, &batchErr) {
+				t.Fatalf("err: got %v, want *vault.BatchError", err)
+			}
+			if got := batchErr.Paths(); !reflect.DeepEqual(got, []string{"write-denied"}) {
+				t.Fatalf("failed paths: got %v, want [write-denied]", got)
+			}
+			if !reflect.DeepEqual(batchErr.Succeeded, tc.succeeded) {
+				t.Fatalf("succeeded: got %v, want %v", batchErr.Succeeded, tc.succeeded)
+			}
+		})
+	}
+}
+
+func TestClient_WriteSecrets_StopsAtFirstFailure(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	gomock.InOrder(
+		m.EXPECT().Write("/secret/data/a", gomock.Any()).Return(&api.Secret{Data: map[string]interface{}{"version": json.Number("1")}}, nil),
+		m.EXPECT().Write("/secret/data/b", gomock.Any()).Return(nil, errors.New("permission denied")),
+		m.EXPECT().Delete("/secret/metadata/a").Return(nil, nil),
+	)
+
+	c := kv.NewClient("", kv.WithLogicalClient(m), kv.WithConcurrency(1))
+	err := c.WriteSecrets(map[string]map[string]interface{}{
+		"a": {"foo": "bar"},
+		"b": {"foo": "bar"},
+		"c": {"foo": "bar"},
+	}, kv.AllOrNothing())
+	var batchErr *vault.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("err: got %v, want *vault.BatchError", err)
+	}
+	if got := batchErr.Paths(); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Fatalf("failed paths: got %v, want [b]", got)
+	}
+	if len(batchErr.Succeeded) != 0 {
+		t.Fatalf("succeeded: got %v, want none", batchErr.Succeeded)
+	}
+}
+
+func TestClient_ExportSecret(t *testing.T) {
+	created := "2020-09-01T12:00:00Z"
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Read("/secret/data/src").Return(&api.Secret{Data: map[string]interface{}{
+		"data": map[string]interface{}{"user": "admin", "port": json.Number("5432")},
+		"metadata": map[string]interface{}{
+			"created_time":  created,
+			"deletion_time": "",
+			"destroyed":     false,
+			"version":       json.Number("3"),
+		},
+	}}, nil)
+	m.EXPECT().Write("/secret/data/dst", map[string]interface{}{
+		"data": map[string]interface{}{"user": "admin", "port": json.Number("5432")},
+	}).Return(&api.Secret{Data: map[string]interface{}{"version": json.Number("1")}}, nil)
+
+	c := kv.NewClient("", kv.WithLogicalClient(m))
+	raw, err := c.ExportSecret("src")
+	if err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	want := `{
+  "data": {
+    "port": 5432,
+    "user": "admin"
+  },
+  "version": 3,
+  "created_time": "2020-09-01T12:00:00Z"
+}`
+	if string(raw) != want {
+		t.Fatalf("export: got %s, want %s", raw, want)
+	}
+
+	var secret kv.Secret
+	if err := json.Unmarshal(raw, &secret); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if secret.Metadata.Version != 3 || secret.Metadata.CreatedTime.Format(time.RFC3339) != created || !secret.Metadata.DeletionTime.IsZero() {
+		t.Fatalf("metadata: got %+v, want version 3 created at %s", secret.Metadata, created)
+	}
+
+	v, err := c.ImportSecret("dst", raw)
+	if err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if v.Version != 1 {
+		t.Fatalf("version: got %d, want 1", v.Version)
+	}
+}
+
+func TestClient_BackupTree(t *testing.T) {
+	list := func(keys ...interface{}) *api.Secret {
+		return &api.Secret{Data: map[string]interface{}{"keys": keys}}
+	}
+	read := func(data map[string]interface{}) *api.Secret {
+		return &api.Secret{Data: map[string]interface{}{
+			"data":     data,
+			"metadata": map[string]interface{}{"version": json.Number("2")},
+		}}
+	}
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().List("/secret/metadata/apps").Return(list("db", "web/"), nil)
+	m.EXPECT().List("/secret/metadata/apps/web").Return(list("tls"), nil)
+	m.EXPECT().Read("/secret/data/apps/db").Return(read(map[string]interface{}{"password": "hunter2"}), nil)
+	m.EXPECT().Read("/secret/metadata/apps/db").Return(&api.Secret{Data: map[string]interface{}{
+		"custom_metadata": map[string]interface{}{"owner": "team-a"},
+	}}, nil)
+	m.EXPECT().Read("/secret/data/apps/web/tls").Return(read(map[string]interface{}{"cert": "pem"}), nil)
+	m.EXPECT().Read("/secret/metadata/apps/web/tls").Return(&api.Secret{Data: map[string]interface{}{}}, nil)
+
+	m.EXPECT().Write("/secret/data/restored/db", map[string]interface{}{
+		"data": map[string]interface{}{"password": "hunter2"},
+	}).Return(nil, nil)
+	m.EXPECT().Write("/secret/metadata/restored/db", map[string]interface{}{
+		"custom_metadata": map[string]interface{}{"owner": "team-a"},
+	}).Return(nil, nil)
+	m.EXPECT().Write("/secret/data/restored/web/tls", map[string]interface{}{
+		"data": map[string]interface{}{"cert": "pem"},
+	}).Return(nil, nil)
+
+	c := kv.NewClient("", kv.WithLogicalClient(m))
+	var buf bytes.Buffer
+	if err := c.BackupTree("apps", &buf); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if err := c.RestoreTree("restored", &buf); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+}
+
+func TestClient_RestoreTree_PathTraversal(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	b := []byte(`{"secret":{"data":{"foo":"bar"}}}`)
+	if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "../escaped.json", Mode: 0600, Size: int64(len(b))}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	c := kv.NewClient("", kv.WithLogicalClient(vaultmock.NewLogicalClient(gomock.NewController(t))))
+	if err := c.RestoreTree("restored", &buf); !errors.Is(err, kv.ErrInvalidSecretPath) {
+		t.Fatalf("err: got %v, want %v", err, kv.ErrInvalidSecretPath)
+	}
+}
+
+func TestClient_ListSecretsFunc(t *testing.T) {
+	errStop := errors.New("stop")
+	tt := []struct {
+		name   string
+		secret *api.Secret
+		stopAt string
+		keys   []string
+		err    error
+		// wantErr is set if an error other than err is returned.
+		wantErr bool
+	}{
+		{
+			name:   "All",
+			secret: &api.Secret{Data: map[string]interface{}{"keys": []interface{}{"a", "b/", "c"}}},
+			keys:   []string{"a", "b/", "c"},
+		},
+		{
+			name:   "StopEarly",
+			secret: &api.Secret{Data: map[string]interface{}{"keys": []interface{}{"a", "b/", "c"}}},
+			stopAt: "b/",
+			keys:   []string{"a", "b/"},
+			err:    errStop,
+		},
+		{
+			name:   "StringSlice",
+			secret: &api.Secret{Data: map[string]interface{}{"keys": []string{"a", "b/"}}},
+			keys:   []string{"a", "b/"},
+		},
+		{
+			name:    "InvalidKey",
+			secret:  &api.Secret{Data: map[string]interface{}{"keys": []interface{}{"a", map[string]interface{}{}, "c"}}},
+			keys:    []string{"a"},
+			wantErr: true,
+		},
+		{name: "Empty"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().List("/secret/metadata/test").Return(tc.secret, nil)
+
+			var keys []string
+			err := kv.NewClient("", kv.WithLogicalClient(m)).ListSecretsFunc("test", func(key string) error {
+				keys = append(keys, key)
+				if key == tc.stopAt {
+					return errStop
+				}
+				return nil
+			})
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("err: got nil, want error")
+				}
+			} else if !errors.Is(err, tc.err) {
+				t.Fatalf("err: got %v, want %v", err, tc.err)
+			}
+			if !reflect.DeepEqual(keys, tc.keys) {
+				t.Fatalf("keys: got %v, want %v", keys, tc.keys)
+			}
+		})
+	}
+}
+
+func TestClient_ForMount(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	gomock.InOrder(
+		m.EXPECT().Write("/tenants/a/data/test", gomock.Any()).Return(nil, nil),
+		m.EXPECT().Write("/tenants/b/data/test", gomock.Any()).Return(nil, nil),
+		m.EXPECT().Write("/secret/data/test", gomock.Any()).Return(nil, nil),
+	)
+
+	c := kv.NewClient("", kv.WithLogicalClient(m))
+	for _, mount := range []string{"tenants/a", "/tenants/b/"} {
+		if _, err := c.ForMount(mount).WriteSecretLatest("test", nil); err != nil {
+			t.Fatalf("%s: err: got %v, want nil", mount, err)
+		}
+	}
+	if _, err := c.WriteSecretLatest("test", nil); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if _, err := c.ForMount("..").WriteSecretLatest("test", nil); err == nil {
+		t.Fatal("invalid mount: err: got nil, want error")
+	}
+}
+
+func TestClient_Sub(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	gomock.InOrder(
+		m.EXPECT().Write("/secret/data/apps/myservice/db", gomock.Any()).Return(nil, nil),
+		m.EXPECT().Read("/secret/metadata/apps/myservice/db/replica").Return(nil, nil),
+		m.EXPECT().List("/secret/metadata/apps/myservice").Return(&api.Secret{Data: map[string]interface{}{
+			"keys": []interface{}{"db"},
+		}}, nil),
+	)
+
+	sub := kv.NewClient("", kv.WithLogicalClient(m)).Sub("apps/myservice/")
+	if _, err := sub.WriteSecretLatest("db", nil); err != nil {
+		t.Fatalf("WriteSecretLatest: err: got %v, want nil", err)
+	}
+	if _, err := sub.Sub("db").ReadSecretMetadata("replica"); err != nil {
+		t.Fatalf("nested Sub: err: got %v, want nil", err)
+	}
+	var paths []string
+	if err := sub.Walk("", func(path string) error {
+		paths = append(paths, path)
+		return nil
+	}); err != nil {
+		t.Fatalf("Walk: err: got %v, want nil", err)
+	}
+	if want := []string{"db"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("Walk: got %v, want %v", paths, want)
+	}
+	if got, want := sub.PolicyPaths("db").Data, "secret/data/apps/myservice/db"; got != want {
+		t.Fatalf("PolicyPaths: got %q, want %q", got, want)
+	}
+	if _, err := sub.Sub("../other").ReadSecretLatest("db"); !errors.Is(err, kv.ErrInvalidSecretPath) {
+		t.Fatalf("invalid prefix: err: got %v, want %v", err, kv.ErrInvalidSecretPath)
+	}
+}
+
+func TestClient_WriteSecretIfChanged(t *testing.T) {
+	stored := &api.Secret{Data: map[string]interface{}{
+		"data":     map[string]interface{}{"port": json.Number("5432")},
+		"metadata": map[string]interface{}{"version": json.Number("2")},
+	}}
+	tt := []struct {
+		name    string
+		current *api.Secret
+		data    map[string]interface{}
+		written bool
+		version int
+	}{
+		{name: "Unchanged", current: stored, data: map[string]interface{}{"port": 5432}, version: 2},
+		{name: "Changed", current: stored, data: map[string]interface{}{"port": 5433}, written: true, version: 3},
+		{name: "NotFound", data: map[string]interface{}{"port": 5432}, written: true, version: 3},
+		{
+			name: "LatestDeleted",
+			current: &api.Secret{Data: map[string]interface{}{
+				"data": nil,
+				"metadata": map[string]interface{}{
+					"deletion_time": "2020-09-02T12:00:00Z",
+					"version":       json.Number("2"),
+				},
+			}},
+			data:    map[string]interface{}{"port": 5432},
+			written: true,
+			version: 3,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().Read("/secret/data/test").Return(tc.current, nil)
+			if tc.written {
+				m.EXPECT().Write("/secret/data/test", map[string]interface{}{"data": tc.data}).Return(&api.Secret{Data: map[string]interface{}{
+					"version": json.Number("3"),
+				}}, nil)
+			}
+
+			v, written, err := kv.NewClient("", kv.WithLogicalClient(m)).WriteSecretIfChanged("test", tc.data)
+			if err != nil {
+				t.Fatalf("err: got %v, want nil", err)
+			}
+			if written != tc.written || v.Version != tc.version {
+				t.Fatalf("got written %t, version %d, want written %t, version %d", written, v.Version, tc.written, tc.version)
+			}
+		})
+	}
+}
+
+func TestClient_WithFieldCipher(t *testing.T) {
+	block, err := aes.NewCipher(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := map[string]interface{}{
+		"user":     "admin",
+		"password": "hunter2",
+		"pin":      1234,
+	}
+
+	var stored map[string]interface{}
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Write("/secret/data/test", gomock.Any()).DoAndReturn(func(path string, d map[string]interface{}) (*api.Secret, error) {
+		stored = d["data"].(map[string]interface{})
+		return nil, nil
+	}).Times(2)
+	read := func(data map[string]interface{}) *api.Secret {
+		return &api.Secret{Data: map[string]interface{}{
+			"data":     data,
+			"metadata": map[string]interface{}{"version": json.Number("1")},
+		}}
+	}
+
+	c := kv.NewClient("", kv.WithLogicalClient(m), kv.WithFieldCipher(aead, "password", "pin"))
+	if _, err := c.WriteSecretLatest("test", data); err != nil {
+		t.Fatalf("write: err: got %v, want nil", err)
+	}
+	first := stored
+	if first["user"] != "admin" {
+		t.Fatalf("user: got %v, want plaintext admin", first["user"])
+	}
+	for _, field := range []string{"password", "pin"} {
+		s, _ := first[field].(string)
+		if !strings.HasPrefix(s, "aead:v1:") || strings.Contains(s, "hunter2") {
+			t.Fatalf("%s: got %v, want encrypted value", field, first[field])
+		}
+	}
+	if _, err := c.WriteSecretLatest("test", data); err != nil {
+		t.Fatalf("write: err: got %v, want nil", err)
+	}
+	if stored["password"] == first["password"] {
+		t.Fatal("password: got the same ciphertext for two writes, want a random nonce")
+	}
+
+	m.EXPECT().Read("/secret/data/test").Return(read(first), nil)
+	secret, err := c.ReadSecretLatest("test")
+	if err != nil {
+		t.Fatalf("read: err: got %v, want nil", err)
+	}
+	want := map[string]interface{}{"user": "admin", "password": "hunter2", "pin": json.Number("1234")}
+	if !reflect.DeepEqual(secret.Data, want) {
+		t.Fatalf("data: got %v, want %v", secret.Data, want)
+	}
+
+	swapped := map[string]interface{}{"user": "admin", "password": first["pin"], "pin": first["password"]}
+	m.EXPECT().Read("/secret/data/test").Return(read(swapped), nil)
+	if _, err := c.ReadSecretLatest("test"); err == nil {
+		t.Fatal("swapped fields: err: got nil, want error")
+	}
+
+	m.EXPECT().Read("/secret/data/other").Return(read(first), nil)
+	if _, err := c.ReadSecretLatest("other"); err == nil {
+		t.Fatal("copied to another secret: err: got nil, want error")
+	}
+
+	m.EXPECT().Write("sys/wrapping/lookup", map[string]interface{}{"token": "s.wrapped"}).Return(&api.Secret{Data: map[string]interface{}{
+		"creation_path": "secret/data/test",
+	}}, nil)
+	m.EXPECT().Unwrap("s.wrapped").Return(read(first), nil)
+	if secret, err := c.UnwrapSecret("s.wrapped"); err != nil || !reflect.DeepEqual(secret.Data, want) {
+		t.Fatalf("unwrap: got %v, %v, want %v, nil", secret.Data, err, want)
+	}
+
+	m.EXPECT().Read("/secret/data/test").Return(read(data), nil)
+	if _, err := c.ReadSecretLatest("test"); !errors.Is(err, kv.ErrFieldNotEncrypted) {
+		t.Fatalf("plaintext: err: got %v, want %v", err, kv.ErrFieldNotEncrypted)
+	}
+}
+
+func TestClient_DeleteSecretVersionQuery(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().DeleteWithData("/secret/data/test", map[string][]string{"versions": {"1", "3"}}).Return(nil, nil)
+	m.EXPECT().DeleteWithData("/secret/data/test", gomock.Any()).Return(nil, errors.New("permission denied"))
+
+	c := kv.NewClient("", kv.WithLogicalClient(m))
+	if err := c.DeleteSecretVersionQuery("test", 1, 3); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	var pathErr *os.PathError
+	if err := c.DeleteSecretVersionQuery("test", 2); !errors.As(err, &pathErr) || pathErr.Op != "DeleteSecretVersionQuery" {
+		t.Fatalf("err: got %v, want *os.PathError with op DeleteSecretVersionQuery", err)
+	}
+}
+
+func TestClient_NoVersions(t *testing.T) {
+	c := kv.NewClient("", kv.WithLogicalClient(vaultmock.NewLogicalClient(gomock.NewController(t))))
+	for name, fn := range map[string]func(path string, version ...int) error{
+		"DeleteSecretVersion":      c.DeleteSecretVersion,
+		"DeleteSecretVersionQuery": c.DeleteSecretVersionQuery,
+		"UndeleteSecretVersion":    c.UndeleteSecretVersion,
+		"DestroySecretVersion":     c.DestroySecretVersion,
+	} {
+		if err := fn("test"); !errors.Is(err, kv.ErrNoVersions) {
+			t.Errorf("%s: err: got %v, want %v", name, err, kv.ErrNoVersions)
+		}
+	}
+}
+
+func TestClient_VersionsEmptyPath(t *testing.T) {
+	c := kv.NewClient("", kv.WithLogicalClient(vaultmock.NewLogicalClient(gomock.NewController(t))))
+	for name, fn := range map[string]func(path string, version ...int) error{
+		"DeleteSecretVersion":      c.DeleteSecretVersion,
+		"DeleteSecretVersionQuery": c.DeleteSecretVersionQuery,
+		"UndeleteSecretVersion":    c.UndeleteSecretVersion,
+		"DestroySecretVersion":     c.DestroySecretVersion,
+	} {
+		if err := fn("", 1); err == nil {
+			t.Errorf("%s: err: got nil, want error", name)
+		}
+		if err := fn("../metadata/foo", 1); !errors.Is(err, kv.ErrInvalidSecretPath) {
+			t.Errorf("%s: err: got %v, want %v", name, err, kv.ErrInvalidSecretPath)
+		}
+	}
+}
+
+func TestSecretVersion_State(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	tt := []struct {
+		name    string
+		version kv.SecretVersion
+		state   kv.State
+	}{
+		{name: "Live", version: kv.SecretVersion{}, state: kv.StateLive},
+		{name: "ScheduledForDeletion", version: kv.SecretVersion{DeletionTime: future}, state: kv.StateLive},
+		{name: "Deleted", version: kv.SecretVersion{DeletionTime: past}, state: kv.StateDeleted},
+		{name: "Destroyed", version: kv.SecretVersion{Destroyed: true}, state: kv.StateDestroyed},
+		{name: "DeletedAndDestroyed", version: kv.SecretVersion{DeletionTime: past, Destroyed: true}, state: kv.StateDestroyed},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v := tc.version
+			if got := v.State(); got != tc.state {
+				t.Fatalf("State: got %v, want %v", got, tc.state)
+			}
+			if v.IsLive() != (tc.state == kv.StateLive) || v.IsDeleted() != (tc.state == kv.StateDeleted) || v.IsDestroyed() != (tc.state == kv.StateDestroyed) {
+				t.Fatalf("IsLive, IsDeleted, IsDestroyed: got %t, %t, %t, want state %v", v.IsLive(), v.IsDeleted(), v.IsDestroyed(), tc.state)
+			}
+		})
+	}
+}
+
+func TestClient_ConcurrentFirstUse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"data":{"foo":"bar"},"metadata":{"version":1}}}`)
+	}))
+	defer srv.Close()
+	setenv(t, "VAULT_ADDR", srv.URL)
+
+	c := kv.NewClient("")
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.ReadSecretLatest("test"); err != nil {
+				t.Errorf("err: got %v, want nil", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestClient_WithHTTPClient(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"data":{"foo":"bar"},"metadata":{"version":1}}}`)
+	}))
+	defer srv.Close()
+	setenv(t, "VAULT_ADDR", srv.URL)
+
+	s, err := kv.NewClient("", kv.WithHTTPClient(srv.Client())).ReadSecretLatest("test")
+	if err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if s.Data["foo"] != "bar" {
+		t.Fatalf("data: got %v, want foo=bar", s.Data)
+	}
+	if _, err := kv.NewClient("").ReadSecretLatest("test"); err == nil {
+		t.Fatal("default HTTP client: err: got nil, want certificate error")
+	}
+}
+
+func TestNewClient_WithStandbyRetry(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	gomock.InOrder(
+		m.EXPECT().Write("/secret/data/test", gomock.Any()).Return(nil, &api.ResponseError{StatusCode: http.StatusPreconditionFailed}),
+		m.EXPECT().Write("/secret/data/test", gomock.Any()).Return(&api.Secret{Data: map[string]interface{}{"version": json.Number("1")}}, nil),
+	)
+
+	c := kv.NewClient("", kv.WithLogicalClient(m), kv.WithStandbyRetry(1, time.Millisecond))
+	v, err := c.WriteSecretLatest("test", map[string]interface{}{"foo": "bar"})
+	if err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if v.Version != 1 {
+		t.Fatalf("version: got %d, want 1", v.Version)
+	}
+}
+
+func TestClient_EnvFile(t *testing.T) {
+	c := kv.NewClient("/secret", kv.WithLogicalClient(vaulttest.NewInMemoryLogical()))
+
+	v, err := c.ReadEnvFile("app", strings.NewReader("export DB_PASSWORD='hunter2'\nPORT=5432\n"))
+	if err != nil {
+		t.Fatalf("ReadEnvFile: got %v, want nil", err)
+	}
+	if v.Version != 1 {
+		t.Fatalf("ReadEnvFile: got version %d, want 1", v.Version)
+	}
+	var buf bytes.Buffer
+	if err := c.WriteEnvFile("app", &buf); err != nil {
+		t.Fatalf("WriteEnvFile: got %v, want nil", err)
+	}
+	if got, want := buf.String(), "DB_PASSWORD='hunter2'\nPORT='5432'\n"; got != want {
+		t.Fatalf("WriteEnvFile: got %q, want %q", got, want)
+	}
+
+	if _, err := c.ReadEnvFile("app", strings.NewReader("KEY='value")); err == nil {
+		t.Fatal("ReadEnvFile with invalid file: got nil, want error")
+	}
+	if err := c.WriteEnvFile("missing", &buf); !errors.Is(err, kv.ErrSecretNotFound) {
+		t.Fatalf("WriteEnvFile of missing secret: got %v, want %v", err, kv.ErrSecretNotFound)
+	}
+}
+
+func TestClient_ReadSecretWithLease(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Read("/secret/data/test").Return(&api.Secret{
+		LeaseID:       "secret/data/test/abc",
+		LeaseDuration: 60,
+		Renewable:     true,
+		Data: map[string]interface{}{
+			"data":     map[string]interface{}{"foo": "bar"},
+			"metadata": map[string]interface{}{"version": json.Number("1")},
+		},
+	}, nil)
+	m.EXPECT().Read("/secret/data/plain").Return(&api.Secret{Data: map[string]interface{}{
+		"data": map[string]interface{}{"foo": "bar"},
+	}}, nil)
+
+	c := kv.NewClient("", kv.WithLogicalClient(m))
+	s, lease, err := c.ReadSecretWithLease("test")
+	if err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if s.Data["foo"] != "bar" || s.Metadata.Version != 1 {
+		t.Fatalf("secret: got %+v, want foo=bar at version 1", s)
+	}
+	if want := (vault.Lease{ID: "secret/data/test/abc", Duration: time.Minute, Renewable: true}); lease != want {
+		t.Fatalf("lease: got %+v, want %+v", lease, want)
+	}
+	if _, lease, err := c.ReadSecretWithLease("plain"); err != nil || !lease.IsZero() {
+		t.Fatalf("no lease: got %+v, %v, want zero lease, nil", lease, err)
+	}
+}
+
+func TestClient_WriteSecretCASRequired(t *testing.T) {
+	casRequired := &api.ResponseError{
+		StatusCode: http.StatusBadRequest,
+		Errors:     []string{"check-and-set parameter required for this call"},
+	}
+	written := &api.Secret{Data: map[string]interface{}{"version": json.Number("4")}}
+	data := map[string]interface{}{"foo": "bar"}
+
+	tt := []struct {
+		name    string
+		opts    []kv.Option
+		expect  func(m *vaultmock.LogicalClient)
+		want    int
+		wantErr error
+	}{
+		{
+			name: "ErrCASRequired",
+			expect: func(m *vaultmock.LogicalClient) {
+				m.EXPECT().Write("/secret/data/test", map[string]interface{}{"data": data}).Return(nil, casRequired)
+			},
+			wantErr: kv.ErrCASRequired,
+		},
+		{
+			name: "AutoCAS",
+			opts: []kv.Option{kv.WithAutoCASCurrentVersion(true)},
+			expect: func(m *vaultmock.LogicalClient) {
+				gomock.InOrder(
+					m.EXPECT().Write("/secret/data/test", map[string]interface{}{"data": data}).Return(nil, casRequired),
+					m.EXPECT().Read("/secret/metadata/test").Return(&api.Secret{Data: map[string]interface{}{
+						"current_version": json.Number("3"),
+					}}, nil),
+					m.EXPECT().Write("/secret/data/test", map[string]interface{}{
+						"data":    data,
+						"options": map[string]interface{}{"cas": 3},
+					}).Return(written, nil),
+				)
+			},
+			want: 4,
+		},
+		{
+			name: "AutoCASMismatch",
+			opts: []kv.Option{kv.WithAutoCASCurrentVersion(true)},
+			expect: func(m *vaultmock.LogicalClient) {
+				gomock.InOrder(
+					m.EXPECT().Write("/secret/data/test", gomock.Any()).Return(nil, casRequired),
+					m.EXPECT().Read("/secret/metadata/test").Return(nil, nil),
+					m.EXPECT().Write("/secret/data/test", map[string]interface{}{
+						"data":    data,
+						"options": map[string]interface{}{"cas": 0},
+					}).Return(nil, &api.ResponseError{
+						StatusCode: http.StatusBadRequest,
+						Errors:     []string{"check-and-set parameter did not match the current version"},
+					}),
+				)
+			},
+			wantErr: &api.ResponseError{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			tc.expect(m)
+
+			c := kv.NewClient("", append(tc.opts, kv.WithLogicalClient(m))...)
+			v, err := c.WriteSecretLatest("test", data)
+			switch want := tc.wantErr.(type) {
+			case nil:
+				if err != nil || v.Version != tc.want {
+					t.Fatalf("got %+v, %v, want version %d, nil", v, err, tc.want)
+				}
+			case *api.ResponseError:
+				if !errors.As(err, &want) || errors.Is(err, kv.ErrCASRequired) {
+					t.Fatalf("err: got %v, want a Vault response error", err)
+				}
+			default:
+				if !errors.Is(err, want) {
+					t.Fatalf("err: got %v, want %v", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_DeleteTree(t *testing.T) {
+	c := kv.NewClient("", kv.WithLogicalClient(vaulttest.NewInMemoryLogical()))
+	for _, path := range []string{"apps/web/db", "apps/web/tls/cert", "apps/worker", "other"} {
+		if _, err := c.WriteSecretLatest(path, map[string]interface{}{"foo": "bar"}); err != nil {
+			t.Fatalf("WriteSecretLatest(%s): err: got %v, want nil", path, err)
+		}
+	}
+
+	if err := c.DeleteTree("apps/web", false); err != nil {
+		t.Fatalf("soft delete: err: got %v, want nil", err)
+	}
+	for _, path := range []string{"apps/web/db", "apps/web/tls/cert"} {
+		if _, err := c.ReadSecretLatest(path); !errors.Is(err, kv.ErrSecretNotFound) {
+			t.Fatalf("read %s: err: got %v, want %v", path, err, kv.ErrSecretNotFound)
+		}
+		if md, err := c.ReadSecretMetadata(path); err != nil || md.CurrentVersion != 1 {
+			t.Fatalf("metadata %s: got %+v, %v, want version 1 kept", path, md, err)
+		}
+	}
+
+	if err := c.DeleteTree("/apps/", true); err != nil {
+		t.Fatalf("destroy: err: got %v, want nil", err)
+	}
+	for _, path := range []string{"apps/web/db", "apps/web/tls/cert", "apps/worker"} {
+		if exists, err := c.ExistsSecret(path); err != nil || exists {
+			t.Fatalf("ExistsSecret(%s): got %t, %v, want false, nil", path, exists, err)
+		}
+	}
+	if _, err := c.ReadSecretLatest("other"); err != nil {
+		t.Fatalf("read other: err: got %v, want nil", err)
+	}
+	if err := c.DeleteTree("/", true); err == nil {
+		t.Fatal("empty root: err: got nil, want error")
+	}
+}
+
+func TestClient_DeleteTreeErrors(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().List("/secret/metadata/apps").Return(&api.Secret{Data: map[string]interface{}{
+		"keys": []interface{}{"a", "b"},
+	}}, nil)
+	m.EXPECT().Delete("/secret/data/apps/a").Return(nil, nil)
+	m.EXPECT().Delete("/secret/data/apps/b").Return(nil, errors.New("boom"))
+
+	err := kv.NewClient("", kv.WithLogicalClient(m)).DeleteTree("apps", false)
+	var batchErr *vault.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("err: got %v, want *vault.BatchError", err)
+	}
+	if got, want := batchErr.Paths(), []string{"apps/b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("failed paths: got %v, want %v", got, want)
+	}
+	if got, want := batchErr.Succeeded, []string{"apps/a"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("succeeded paths: got %v, want %v", got, want)
+	}
+}
+
+func TestNewClient_WithCircuitBreaker(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().ReadWithContext(gomock.Any(), "/secret/data/test").Return(nil, &api.ResponseError{StatusCode: http.StatusInternalServerError})
+
+	b := vault.NewCircuitBreaker(1, time.Hour)
+	c := kv.NewClient("", kv.WithLogicalClient(m), kv.WithCircuitBreaker(b), kv.WithRequestTimeout(time.Second))
+	if _, err := c.ReadSecretLatest("test"); err == nil || errors.Is(err, vault.ErrCircuitOpen) {
+		t.Fatalf("first read: err: got %v, want Vault error", err)
+	}
+	if _, err := c.ForMount("other").ReadSecretLatest("test"); !errors.Is(err, vault.ErrCircuitOpen) {
+		t.Fatalf("second read: err: got %v, want %v", err, vault.ErrCircuitOpen)
+	}
+	if got := b.State(); got != vault.CircuitOpen {
+		t.Fatalf("state: got %v, want %v", got, vault.CircuitOpen)
+	}
+}
+
+func TestNewClient_WithWriteValidator(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Write("/secret/data/app/db", gomock.Any()).Return(nil, nil)
+	m.EXPECT().JSONMergePatch(gomock.Any(), "/secret/data/app/db", gomock.Any()).Return(nil, nil)
+
+	var validated []string
+	c := kv.NewClient("", kv.WithLogicalClient(m), kv.WithWriteValidator(func(path string, data map[string]interface{}) error {
+		validated = append(validated, path)
+		return vault.RequireKeys("password")(path, data)
+	}))
+	if _, err := c.WriteSecretLatest("app/db", map[string]interface{}{"password": "hunter2"}); err != nil {
+		t.Fatalf("valid write: err: got %v, want nil", err)
+	}
+	_, err := c.ForMount("").WriteSecretVersion("app/db", 1, map[string]interface{}{"user": "admin"})
+	var pathErr *os.PathError
+	if !errors.As(err, &pathErr) || pathErr.Op != "WriteSecretVersion" {
+		t.Fatalf("invalid write: err: got %v, want *os.PathError", err)
+	}
+	if _, err := c.PatchSecret("app/db", map[string]interface{}{"user": "admin"}); err != nil {
+		t.Fatalf("patch: err: got %v, want nil", err)
+	}
+	if want := []string{"app/db", "app/db"}; !reflect.DeepEqual(validated, want) {
+		t.Fatalf("validated paths: got %v, want %v", validated, want)
+	}
+}
+
+func TestClient_ListSecretsSorted(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().List("/secret/metadata/apps").Return(&api.Secret{Data: map[string]interface{}{
+		"keys": []interface{}{"web", "web/", "db", "cache/", "db"},
+	}}, nil)
+	m.EXPECT().List("/secret/metadata/empty").Return(nil, nil)
+
+	c := kv.NewClient("", kv.WithLogicalClient(m))
+	keys, err := c.ListSecretsSorted("apps")
+	if err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+	if want := []string{"cache/", "web/", "db", "web"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("keys: got %v, want %v", keys, want)
+	}
+	if keys, err := c.ListSecretsSorted("empty"); err != nil || keys != nil {
+		t.Fatalf("empty: got %v, %v, want nil, nil", keys, err)
+	}
+}
+
+func TestNewClient_WithTokenRenewer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("X-Vault-Token") != "new" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"errors":["permission denied"]}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"data":{"foo":"bar"},"metadata":{"version":1}}}`)
+	}))
+	defer srv.Close()
+	setenv(t, "VAULT_ADDR", srv.URL)
+	setenv(t, "VAULT_TOKEN", "old")
+
+	logins := 0
+	c := kv.NewClient("", kv.WithTokenRenewer(func() (string, error) {
+		logins++
+		return "new", nil
+	}))
+	for i := 0; i < 2; i++ {
+		s, err := c.ForMount("").ReadSecretLatest("test")
+		if err != nil {
+			t.Fatalf("read %d: err: got %v, want nil", i, err)
+		}
+		if s.Data["foo"] != "bar" {
+			t.Fatalf("read %d: data: got %v, want foo=bar", i, s.Data)
+		}
+	}
+	if logins != 1 {
+		t.Fatalf("logins: got %d, want 1", logins)
+	}
+}
+
+func TestClient_ReadSecretAsOf(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	c := kv.NewClient("", kv.WithLogicalClient(vaulttest.NewInMemoryLogical(vaulttest.WithClock(func() time.Time { return now }))))
+	for i, pw := range []string{"a", "b", "c"} {
+		now = start.Add(time.Duration(i+1) * time.Hour)
+		if _, err := c.WriteSecretLatest("db", map[string]interface{}{"password": pw}); err != nil {
+			t.Fatalf("WriteSecretLatest: err: got %v, want nil", err)
+		}
+	}
+	now = start.Add(4 * time.Hour)
+	if err := c.DeleteSecretLatest("db"); err != nil {
+		t.Fatalf("DeleteSecretLatest: err: got %v, want nil", err)
+	}
+	if err := c.DestroySecretVersion("db", 1); err != nil {
+		t.Fatalf("DestroySecretVersion: err: got %v, want nil", err)
+	}
+
+	tt := []struct {
+		name    string
+		at      time.Duration
+		want    string
+		wantErr error
+	}{
+		{name: "BeforeCreation", at: 30 * time.Minute, wantErr: kv.ErrSecretNotFound},
+		{name: "Destroyed", at: 90 * time.Minute, wantErr: kv.ErrVersionDestroyed},
+		{name: "ExactCreation", at: 2 * time.Hour, want: "b"},
+		{name: "BetweenVersions", at: 150 * time.Minute, want: "b"},
+		{name: "DeletedAfter", at: 3 * time.Hour, wantErr: kv.ErrSecretNotFound},
+		{name: "DeletedAt", at: 5 * time.Hour, wantErr: kv.ErrSecretNotFound},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := c.ReadSecretAsOf("db", start.Add(tc.at))
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("err: got %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil || s.Data["password"] != tc.want {
+				t.Fatalf("got %v, %v, want password %s, nil", s.Data, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewClient_WithRejectEmptyWrites(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Write("/secret/data/test", map[string]interface{}{"data": map[string]interface{}{}}).Return(nil, nil)
+
+	if _, err := kv.NewClient("", kv.WithLogicalClient(m)).WriteSecretLatest("test", map[string]interface{}{}); err != nil {
+		t.Fatalf("permissive: err: got %v, want nil", err)
+	}
+	c := kv.NewClient("", kv.WithLogicalClient(m), kv.WithRejectEmptyWrites(true))
+	if _, err := c.WriteSecretVersion("test", 1, nil); !errors.Is(err, kv.ErrEmptyData) {
+		t.Fatalf("empty write: err: got %v, want %v", err, kv.ErrEmptyData)
+	}
+	if _, err := c.ForMount("").PatchSecret("test", map[string]interface{}{}); !errors.Is(err, kv.ErrEmptyData) {
+		t.Fatalf("empty patch: err: got %v, want %v", err, kv.ErrEmptyData)
+	}
+}
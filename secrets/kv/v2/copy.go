@@ -0,0 +1,118 @@
+package kv
+
+import "fmt"
+
+// CopyOption configures how a secret is copied.
+type CopyOption func(*copyConfig)
+
+type copyConfig struct {
+	customMetadata bool
+	purgeSource    bool
+}
+
+// CopyCustomMetadata also copies the custom metadata of the source secret to
+// the destination secret.
+func CopyCustomMetadata() CopyOption {
+	return func(cfg *copyConfig) {
+		cfg.customMetadata = true
+	}
+}
+
+// PurgeSource makes MoveSecret permanently delete the metadata and all versions
+// of the source secret, instead of soft deleting its latest version. It has no
+// effect on CopySecret and CopySecretTo.
+func PurgeSource() CopyOption {
+	return func(cfg *copyConfig) {
+		cfg.purgeSource = true
+	}
+}
+
+// CopySecret copies the latest secret version at the src path to the dst path
+// using the DefaultClient.
+func CopySecret(src, dst string, opts ...CopyOption) error {
+	return DefaultClient.CopySecret(src, dst, opts...)
+}
+
+// CopySecretTo copies the latest secret version at the src path using the
+// DefaultClient to the dst path using the dstClient.
+func CopySecretTo(dstClient *Client, src, dst string, opts ...CopyOption) error {
+	return DefaultClient.CopySecretTo(dstClient, src, dst, opts...)
+}
+
+// MoveSecret moves the latest secret version at the src path to the dst path
+// using the DefaultClient.
+func MoveSecret(src, dst string, opts ...CopyOption) error {
+	return DefaultClient.MoveSecret(src, dst, opts...)
+}
+
+// CopySecret copies the data of the latest secret version at the src path to
+// the dst path. See CopySecretTo for details.
+func (c *Client) CopySecret(src, dst string, opts ...CopyOption) error {
+	return c.CopySecretTo(c, src, dst, opts...)
+}
+
+// CopySecretTo copies the data of the latest secret version at the src path to
+// the dst path of dstClient, which may use a different mount or Vault server.
+// If the source secret does not exist, ErrSecretNotFound is returned.
+//
+// Existing data at the dst path is overwritten by writing a new version. The
+// write is made without a check-and-set version, so it fails if the
+// destination secret or engine requires CAS. The source metadata, such as the
+// version history, is not copied, except for the custom metadata when the
+// CopyCustomMetadata option is given.
+func (c *Client) CopySecretTo(dstClient *Client, src, dst string, opts ...CopyOption) error {
+	var cfg copyConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	secret, err := c.ReadSecretLatest(src)
+	if err != nil {
+		return err
+	}
+	if _, err := dstClient.WriteSecretLatest(dst, secret.Data); err != nil {
+		return err
+	}
+	if !cfg.customMetadata {
+		return nil
+	}
+	md, err := c.ReadSecretMetadata(src)
+	if err != nil {
+		return err
+	}
+	if len(md.CustomMetadata) == 0 {
+		return nil
+	}
+	return dstClient.WriteSecretMetadata(dst, SecretConfig{CustomMetadata: md.CustomMetadata})
+}
+
+// MoveSecret copies the latest secret version at the src path to the dst path
+// as CopySecret does, then deletes the source secret. By default, only the
+// latest version of the source is soft deleted, so it can be undeleted; use the
+// PurgeSource option to permanently delete the source metadata and all of its
+// versions.
+//
+// The source is deleted only after the copy succeeds. If the copy fails, the
+// source is left intact and the copy error is returned. In dry-run mode,
+// MoveSecret returns ErrDryRunUnsupported without copying the secret.
+func (c *Client) MoveSecret(src, dst string, opts ...CopyOption) error {
+	if c.dryRun {
+		return ErrDryRunUnsupported
+	}
+	var cfg copyConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if err := c.CopySecret(src, dst, opts...); err != nil {
+		return err
+	}
+	var err error
+	if cfg.purgeSource {
+		err = c.DeleteSecretMetadata(src)
+	} else {
+		err = c.DeleteSecretLatest(src)
+	}
+	if err != nil {
+		return fmt.Errorf("kv2: secret copied to %q but source not deleted: %w", dst, err)
+	}
+	return nil
+}
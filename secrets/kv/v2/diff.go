@@ -0,0 +1,110 @@
+package kv
+
+import (
+	"reflect"
+	"sort"
+)
+
+// DiffOption configures how secret versions are compared.
+type DiffOption func(*diffConfig)
+
+type diffConfig struct {
+	showValues bool
+}
+
+// ShowValues makes DiffVersions report the values of the differing keys in
+// Diff.Old and Diff.New. By default, only the key names are reported, so a
+// Diff can be logged without exposing secret data.
+func ShowValues() DiffOption {
+	return func(cfg *diffConfig) {
+		cfg.showValues = true
+	}
+}
+
+// Diff reports the keys that differ between two secret versions.
+type Diff struct {
+	// The sorted keys that are only in the newer version.
+	Added []string
+
+	// The sorted keys that are only in the older version.
+	Removed []string
+
+	// The sorted keys that are in both versions with different values.
+	Changed []string
+
+	// The values of the removed and changed keys in the older version. Only
+	// set with the ShowValues option.
+	Old map[string]interface{}
+
+	// The values of the added and changed keys in the newer version. Only set
+	// with the ShowValues option.
+	New map[string]interface{}
+}
+
+// Empty reports whether the versions have the same data.
+func (d Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffVersions compares the secret versions a and b at the specified path
+// using the DefaultClient.
+func DiffVersions(path string, a, b int, opts ...DiffOption) (Diff, error) {
+	return DefaultClient.DiffVersions(path, a, b, opts...)
+}
+
+// DiffVersions compares the data of the secret versions a and b at the
+// specified path, reporting the changes from a to b. If either version was
+// deleted or destroyed, ErrSecretNotFound is returned.
+func (c *Client) DiffVersions(path string, a, b int, opts ...DiffOption) (Diff, error) {
+	var cfg diffConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	older, err := c.ReadSecretVersion(path, a)
+	if err != nil {
+		return Diff{}, err
+	}
+	newer, err := c.ReadSecretVersion(path, b)
+	if err != nil {
+		return Diff{}, err
+	}
+	return diffData(older.Data, newer.Data, cfg.showValues), nil
+}
+
+func diffData(older, newer map[string]interface{}, showValues bool) Diff {
+	var d Diff
+	if showValues {
+		d.Old = make(map[string]interface{})
+		d.New = make(map[string]interface{})
+	}
+	for k, ov := range older {
+		nv, ok := newer[k]
+		switch {
+		case !ok:
+			d.Removed = append(d.Removed, k)
+		case !reflect.DeepEqual(ov, nv):
+			d.Changed = append(d.Changed, k)
+		default:
+			continue
+		}
+		if showValues {
+			d.Old[k] = ov
+			if ok {
+				d.New[k] = nv
+			}
+		}
+	}
+	for k, nv := range newer {
+		if _, ok := older[k]; ok {
+			continue
+		}
+		d.Added = append(d.Added, k)
+		if showValues {
+			d.New[k] = nv
+		}
+	}
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	sort.Strings(d.Changed)
+	return d
+}
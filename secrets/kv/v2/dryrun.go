@@ -0,0 +1,51 @@
+package kv
+
+import "errors"
+
+// ErrDryRunUnsupported is returned in dry-run mode by operations that write a
+// secret before making a destructive request, such as MoveSecret, since
+// skipping only the destructive request would leave the write in Vault.
+var ErrDryRunUnsupported = errors.New("kv2: operation not supported in dry-run mode")
+
+// Action is a destructive request that a Client in dry-run mode skipped.
+type Action struct {
+	// The Client method that would have made the request.
+	Op string
+
+	// The Vault API path of the request.
+	Path string
+
+	// The secret versions the request would have deleted or destroyed, if the
+	// operation applies to specific versions.
+	Versions []int
+}
+
+// PlannedActions returns the destructive requests skipped by the Client in
+// dry-run mode, in the order the operations were called.
+func (c *Client) PlannedActions() []Action {
+	c.dryRunMu.Lock()
+	defer c.dryRunMu.Unlock()
+	return append([]Action(nil), c.planned...)
+}
+
+// ClearPlannedActions discards the planned actions recorded by the Client.
+func (c *Client) ClearPlannedActions() {
+	c.dryRunMu.Lock()
+	c.planned = nil
+	c.dryRunMu.Unlock()
+}
+
+// skipDryRun records the action and reports whether it must be skipped
+// because the Client is in dry-run mode.
+func (c *Client) skipDryRun(a Action) bool {
+	if !c.dryRun {
+		return false
+	}
+	if c.core.Logger != nil {
+		c.core.Logger.Info("dry run: skipped destructive request", "op", a.Op, "path", a.Path, "versions", a.Versions)
+	}
+	c.dryRunMu.Lock()
+	c.planned = append(c.planned, a)
+	c.dryRunMu.Unlock()
+	return true
+}
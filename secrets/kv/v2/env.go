@@ -0,0 +1,51 @@
+package kv
+
+import (
+	"io"
+	"os"
+
+	"github.com/mwalto7/vault"
+)
+
+// WriteEnvFile reads the latest secret version at the specified path using
+// the DefaultClient and writes its data to w as a .env file.
+func WriteEnvFile(path string, w io.Writer) error {
+	return DefaultClient.WriteEnvFile(path, w)
+}
+
+// ReadEnvFile parses the .env file read from r and writes its variables as the
+// latest secret version at the specified path using the DefaultClient.
+func ReadEnvFile(path string, r io.Reader) (SecretVersion, error) {
+	return DefaultClient.ReadEnvFile(path, r)
+}
+
+// WriteEnvFile reads the latest secret version at the specified path and
+// writes its data to w as KEY='value' lines that can be sourced by a shell,
+// such as DB_PASSWORD='hunter2' for the "password" key of the "db" object.
+// See vault.WriteEnvFile for how the variables are named and quoted.
+//
+//    f, err := os.Create(".env")
+//    ...
+//    err = kv.WriteEnvFile("app/config", f)
+func (c *Client) WriteEnvFile(path string, w io.Writer) error {
+	secret, err := c.ReadSecretLatest(path)
+	if err != nil {
+		return err
+	}
+	if err := vault.WriteEnvFile(w, secret.Data); err != nil {
+		return &os.PathError{Op: "WriteEnvFile", Path: path, Err: err}
+	}
+	return nil
+}
+
+// ReadEnvFile parses the .env file read from r and writes its variables as the
+// latest secret version at the specified path, with a string value for each
+// variable named as in the file. See vault.ParseEnvFile for the supported
+// syntax.
+func (c *Client) ReadEnvFile(path string, r io.Reader) (SecretVersion, error) {
+	data, err := vault.ParseEnvFile(r)
+	if err != nil {
+		return SecretVersion{}, &os.PathError{Op: "ReadEnvFile", Path: path, Err: err}
+	}
+	return c.WriteSecretLatest(path, data)
+}
@@ -0,0 +1,204 @@
+package kv
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// secretVersionJSON is the JSON format of a SecretVersion, which omits the
+// zero timestamps and the destroyed flag if it is not set.
+type secretVersionJSON struct {
+	Version      int        `json:"version,omitempty"`
+	CreatedTime  *time.Time `json:"created_time,omitempty"`
+	DeletionTime *time.Time `json:"deletion_time,omitempty"`
+	Destroyed    bool       `json:"destroyed,omitempty"`
+}
+
+func newSecretVersionJSON(v SecretVersion) secretVersionJSON {
+	return secretVersionJSON{
+		Version:      v.Version,
+		CreatedTime:  timePtr(v.CreatedTime),
+		DeletionTime: timePtr(v.DeletionTime),
+		Destroyed:    v.Destroyed,
+	}
+}
+
+func (v secretVersionJSON) secretVersion() SecretVersion {
+	return SecretVersion{
+		Version:      v.Version,
+		CreatedTime:  timeValue(v.CreatedTime),
+		DeletionTime: timeValue(v.DeletionTime),
+		Destroyed:    v.Destroyed,
+	}
+}
+
+// MarshalJSON encodes the secret version metadata as a JSON object with the
+// "version", "created_time", "deletion_time" and "destroyed" fields. Zero
+// timestamps and an unset destroyed flag are omitted.
+func (v SecretVersion) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newSecretVersionJSON(v))
+}
+
+// UnmarshalJSON decodes the secret version metadata from the format produced
+// by MarshalJSON.
+func (v *SecretVersion) UnmarshalJSON(b []byte) error {
+	var aux secretVersionJSON
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	*v = aux.secretVersion()
+	return nil
+}
+
+// secretJSON is the JSON format of a Secret, which flattens the version
+// metadata next to the data.
+type secretJSON struct {
+	Data map[string]interface{} `json:"data"`
+	secretVersionJSON
+}
+
+// MarshalJSON encodes the secret as a JSON object with its data under the
+// "data" field, next to the fields of its version metadata:
+//
+//	{
+//	  "data": {"username": "admin"},
+//	  "version": 3,
+//	  "created_time": "2020-09-01T12:00:00Z"
+//	}
+//
+// Zero timestamps and an unset destroyed flag are omitted.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(secretJSON{Data: s.Data, secretVersionJSON: newSecretVersionJSON(s.Metadata)})
+}
+
+// UnmarshalJSON decodes the secret from the format produced by MarshalJSON.
+// Numbers in the secret data are decoded as json.Number, like the data of
+// secrets read from Vault.
+func (s *Secret) UnmarshalJSON(b []byte) error {
+	var aux secretJSON
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&aux); err != nil {
+		return err
+	}
+	*s = Secret{Data: aux.Data, Metadata: aux.secretVersion()}
+	return nil
+}
+
+// secretMetadataJSON is the JSON format of SecretMetadata, which omits the
+// zero timestamps.
+type secretMetadataJSON struct {
+	CreatedTime        *time.Time               `json:"created_time,omitempty"`
+	CurrentVersion     int                      `json:"current_version"`
+	MaxVersions        int                      `json:"max_versions"`
+	CASRequired        bool                     `json:"cas_required,omitempty"`
+	DeleteVersionAfter string                   `json:"delete_version_after,omitempty"`
+	OldestVersion      int                      `json:"oldest_version"`
+	UpdatedTime        *time.Time               `json:"updated_time,omitempty"`
+	CustomMetadata     map[string]string        `json:"custom_metadata,omitempty"`
+	Versions           map[string]SecretVersion `json:"versions,omitempty"`
+}
+
+// MarshalJSON encodes the secret metadata as a JSON object with the field
+// names used by Vault. Zero timestamps and durations, a false cas_required and
+// empty custom metadata are omitted.
+func (m SecretMetadata) MarshalJSON() ([]byte, error) {
+	var deleteAfter string
+	if m.DeleteVersionAfter != 0 {
+		deleteAfter = m.DeleteVersionAfter.String()
+	}
+	return json.Marshal(secretMetadataJSON{
+		CreatedTime:        timePtr(m.CreatedTime),
+		CurrentVersion:     m.CurrentVersion,
+		MaxVersions:        m.MaxVersions,
+		CASRequired:        m.CASRequired,
+		DeleteVersionAfter: deleteAfter,
+		OldestVersion:      m.OldestVersion,
+		UpdatedTime:        timePtr(m.UpdatedTime),
+		CustomMetadata:     m.CustomMetadata,
+		Versions:           m.Versions,
+	})
+}
+
+// UnmarshalJSON decodes the secret metadata from the format produced by
+// MarshalJSON.
+func (m *SecretMetadata) UnmarshalJSON(b []byte) error {
+	var aux secretMetadataJSON
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	var deleteAfter time.Duration
+	if aux.DeleteVersionAfter != "" {
+		d, err := time.ParseDuration(aux.DeleteVersionAfter)
+		if err != nil {
+			return err
+		}
+		deleteAfter = d
+	}
+	*m = SecretMetadata{
+		CreatedTime:        timeValue(aux.CreatedTime),
+		CurrentVersion:     aux.CurrentVersion,
+		MaxVersions:        aux.MaxVersions,
+		CASRequired:        aux.CASRequired,
+		DeleteVersionAfter: deleteAfter,
+		OldestVersion:      aux.OldestVersion,
+		UpdatedTime:        timeValue(aux.UpdatedTime),
+		CustomMetadata:     aux.CustomMetadata,
+		Versions:           aux.Versions,
+	}
+	return nil
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func timeValue(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+// ExportSecret reads the latest secret version at the specified path using
+// the DefaultClient and encodes it as JSON.
+func ExportSecret(path string) ([]byte, error) {
+	return DefaultClient.ExportSecret(path)
+}
+
+// ImportSecret writes the secret data of the JSON-encoded secret to the
+// specified path using the DefaultClient.
+func ImportSecret(path string, raw []byte) (SecretVersion, error) {
+	return DefaultClient.ImportSecret(path, raw)
+}
+
+// ExportSecret reads the latest secret version at the specified path and
+// returns it encoded as indented JSON in the format of Secret.MarshalJSON. If
+// no data is stored at the path, ErrSecretNotFound is returned.
+func (c *Client) ExportSecret(path string) ([]byte, error) {
+	secret, err := c.ReadSecretLatest(path)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(secret, "", "  ")
+}
+
+// ImportSecret decodes a secret exported with ExportSecret and writes its data
+// as the latest secret version at the specified path. The version metadata of
+// the exported secret is ignored, since Vault assigns the version and
+// timestamps of the written version.
+func (c *Client) ImportSecret(path string, raw []byte) (SecretVersion, error) {
+	var secret Secret
+	if err := json.Unmarshal(raw, &secret); err != nil {
+		return SecretVersion{}, err
+	}
+	if len(secret.Data) == 0 {
+		return SecretVersion{}, errors.New("kv2: imported secret has no data")
+	}
+	return c.WriteSecretLatest(path, secret.Data)
+}
@@ -0,0 +1,61 @@
+package kv
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// k8sSecret is the minimal Kubernetes core/v1 Secret manifest.
+type k8sSecret struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   k8sObjectMeta     `json:"metadata"`
+	Type       string            `json:"type"`
+	Data       map[string][]byte `json:"data"`
+}
+
+type k8sObjectMeta struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ToK8sSecret reads the latest secret version at the specified path using the
+// DefaultClient and encodes it as a Kubernetes Secret manifest.
+func ToK8sSecret(path, name, namespace string) ([]byte, error) {
+	return DefaultClient.ToK8sSecret(path, name, namespace)
+}
+
+// ToK8sSecret reads the latest secret version at the specified path and
+// encodes it as a Kubernetes core/v1 Secret manifest with the given name and
+// namespace. The manifest is JSON, which is also valid YAML.
+//
+// String values are stored as-is and all other values are JSON-encoded before
+// being base64-encoded into the manifest data.
+func (c *Client) ToK8sSecret(path, name, namespace string) ([]byte, error) {
+	if name == "" {
+		return nil, errors.New("kv2: kubernetes secret name is empty")
+	}
+	secret, err := c.ReadSecretLatest(path)
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string][]byte, len(secret.Data))
+	for k, v := range secret.Data {
+		if s, ok := v.(string); ok {
+			data[k] = []byte(s)
+			continue
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		data[k] = b
+	}
+	return json.MarshalIndent(k8sSecret{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata:   k8sObjectMeta{Name: name, Namespace: namespace},
+		Type:       "Opaque",
+		Data:       data,
+	}, "", "  ")
+}
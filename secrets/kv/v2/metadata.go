@@ -0,0 +1,264 @@
+package kv
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Version returns the metadata of the secret version n, and whether the
+// version exists.
+func (m SecretMetadata) Version(n int) (SecretVersion, bool) {
+	v, ok := m.Versions[strconv.Itoa(n)]
+	if ok {
+		v.Version = n
+	}
+	return v, ok
+}
+
+// State returns the current state of the secret version. A version that was
+// deleted and then destroyed is StateDestroyed, and a version scheduled for
+// deletion is StateLive until its deletion time has passed.
+func (v SecretVersion) State() State {
+	return v.stateAt(time.Now())
+}
+
+// IsLive reports whether the data of the secret version can be read.
+func (v SecretVersion) IsLive() bool {
+	return v.State() == StateLive
+}
+
+// IsDeleted reports whether the secret version was soft deleted and can be
+// restored. A version that was deleted and then destroyed is not deleted, but
+// destroyed.
+func (v SecretVersion) IsDeleted() bool {
+	return v.State() == StateDeleted
+}
+
+// IsDestroyed reports whether the data of the secret version was permanently
+// deleted, whether or not it was soft deleted first.
+func (v SecretVersion) IsDestroyed() bool {
+	return v.Destroyed
+}
+
+func (v SecretVersion) stateAt(now time.Time) State {
+	switch {
+	case v.Destroyed:
+		return StateDestroyed
+	case !v.DeletionTime.IsZero() && !v.DeletionTime.After(now):
+		return StateDeleted
+	default:
+		return StateLive
+	}
+}
+
+// LiveVersions returns the secret versions whose data can be read, in
+// ascending order. Versions scheduled for deletion in the future are live.
+func (m SecretMetadata) LiveVersions() []int {
+	return m.versionsIn(StateLive)
+}
+
+// OldestLiveVersion returns the oldest secret version whose data can be read,
+// and whether there is one. Unlike OldestVersion, it skips the versions that
+// were deleted or destroyed.
+func (m SecretMetadata) OldestLiveVersion() (int, bool) {
+	live := m.LiveVersions()
+	if len(live) == 0 {
+		return 0, false
+	}
+	return live[0], true
+}
+
+// DeletedVersions returns the soft deleted secret versions, in ascending
+// order. Versions that were deleted and then destroyed are not included.
+func (m SecretMetadata) DeletedVersions() []int {
+	return m.versionsIn(StateDeleted)
+}
+
+// DestroyedVersions returns the destroyed secret versions, in ascending order.
+func (m SecretMetadata) DestroyedVersions() []int {
+	return m.versionsIn(StateDestroyed)
+}
+
+// SortedVersions returns the metadata of all secret versions, in ascending
+// order of version number.
+func (m SecretMetadata) SortedVersions() []SecretVersion {
+	versions := make([]SecretVersion, 0, len(m.Versions))
+	for k, v := range m.Versions {
+		n, err := strconv.Atoi(k)
+		if err != nil {
+			continue
+		}
+		v.Version = n
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version < versions[j].Version
+	})
+	return versions
+}
+
+// VersionAt returns the metadata of the secret version that was the current
+// version at the time t, which is the newest version created at or before t,
+// and whether there is one. The version is returned even if it was deleted or
+// destroyed at t; use its State to check.
+func (m SecretMetadata) VersionAt(t time.Time) (SecretVersion, bool) {
+	versions := m.SortedVersions()
+	for i := len(versions) - 1; i >= 0; i-- {
+		if !versions[i].CreatedTime.After(t) {
+			return versions[i], true
+		}
+	}
+	return SecretVersion{}, false
+}
+
+func (m SecretMetadata) versionsIn(state State) []int {
+	now := time.Now()
+	var versions []int
+	for k := range m.Versions {
+		n, err := strconv.Atoi(k)
+		if err != nil {
+			continue
+		}
+		if metadataState(m, n, now) == state {
+			versions = append(versions, n)
+		}
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// MetadataUpdate is a partial update of the configuration of a secret. Unlike
+// SecretConfig, a nil field leaves the setting unchanged, so a setting can be
+// reset to its zero value, such as turning off CAS with CASRequired set to
+// Bool(false).
+type MetadataUpdate struct {
+	// The maximum allowed number of secret versions to keep. Zero uses the
+	// engine default.
+	MaxVersions *int
+
+	// Specifies if CAS is required for writes to the secret.
+	CASRequired *bool
+
+	// The duration after which to delete secret versions. Zero keeps versions
+	// until deleted explicitly.
+	DeleteVersionAfter *time.Duration
+
+	// The user-provided key-value metadata of the secret, which replaces the
+	// existing custom metadata. A non-nil empty map removes all custom metadata.
+	CustomMetadata map[string]string
+}
+
+// Bool returns a pointer to b, for setting MetadataUpdate fields.
+func Bool(b bool) *bool { return &b }
+
+// Int returns a pointer to i, for setting MetadataUpdate and WriteOptions
+// fields.
+func Int(i int) *int { return &i }
+
+// Duration returns a pointer to d, for setting MetadataUpdate fields.
+func Duration(d time.Duration) *time.Duration { return &d }
+
+// UpdateSecretMetadata updates the set fields of the secret configuration at
+// the specified path using the DefaultClient.
+//
+// See https://www.vaultproject.io/api-docs/secret/kv/kv-v2#update-metadata.
+func UpdateSecretMetadata(path string, update MetadataUpdate) error {
+	return DefaultClient.UpdateSecretMetadata(path, update)
+}
+
+// UpdateSecretMetadata updates the set fields of the secret configuration at
+// the specified path, leaving the other settings unchanged.
+//
+// See https://www.vaultproject.io/api-docs/secret/kv/kv-v2#update-metadata.
+func (c *Client) UpdateSecretMetadata(path string, update MetadataUpdate) error {
+	path, err := c.secretPath(path, true)
+	if err != nil {
+		return err
+	}
+	client, err := c.vaultClient()
+	if err != nil {
+		return err
+	}
+	data := make(map[string]interface{})
+	if update.MaxVersions != nil {
+		data["max_versions"] = *update.MaxVersions
+	}
+	if update.CASRequired != nil {
+		data["cas_required"] = *update.CASRequired
+	}
+	if update.DeleteVersionAfter != nil {
+		data["delete_version_after"] = update.DeleteVersionAfter.String()
+	}
+	if update.CustomMetadata != nil {
+		data["custom_metadata"] = update.CustomMetadata
+	}
+	if _, err := client.Write(path, data); err != nil {
+		return &os.PathError{Op: "UpdateSecretMetadata", Path: path, Err: err}
+	}
+	return nil
+}
+
+// ReadSecretMetadataVersion returns the metadata of the secret version at the
+// specified path using the DefaultClient.
+//
+// See https://www.vaultproject.io/api-docs/secret/kv/kv-v2#read-secret-metadata.
+func ReadSecretMetadataVersion(path string, version int) (SecretVersion, error) {
+	return DefaultClient.ReadSecretMetadataVersion(path, version)
+}
+
+// ReadSecretMetadataVersion returns the metadata of the secret version at the
+// specified path. The metadata of the secret is read and indexed by version,
+// so the metadata of a deleted or destroyed version is returned too. If the
+// version is not in the metadata, ErrSecretNotFound is returned.
+//
+// See https://www.vaultproject.io/api-docs/secret/kv/kv-v2#read-secret-metadata.
+func (c *Client) ReadSecretMetadataVersion(path string, version int) (SecretVersion, error) {
+	mdPath, err := c.secretPath(path, true)
+	if err != nil {
+		return SecretVersion{}, err
+	}
+	md, err := c.ReadSecretMetadata(path)
+	if err != nil {
+		return SecretVersion{}, err
+	}
+	v, ok := md.Version(version)
+	if !ok {
+		return SecretVersion{}, &os.PathError{Op: "ReadSecretMetadataVersion", Path: mdPath, Err: ErrSecretNotFound}
+	}
+	return v, nil
+}
+
+// ReadSecretAsOf reads the secret version at the specified path that was
+// current at the time t using the DefaultClient.
+func ReadSecretAsOf(path string, t time.Time) (Secret, error) {
+	return DefaultClient.ReadSecretAsOf(path, t)
+}
+
+// ReadSecretAsOf reads the secret version at the specified path that was
+// current at the time t, for point-in-time debugging and audits. The secret
+// metadata is read to find the version with VersionAt, and the version is then
+// read. If no version was live at t, because the secret did not exist yet or
+// its current version was deleted, ErrSecretNotFound is returned; if the
+// version was destroyed since, or deleted after t, reading it returns
+// ErrVersionDestroyed or ErrSecretNotFound.
+//
+// Versions older than the maximum number of versions kept by Vault are not in
+// the metadata, so a time before the oldest kept version returns
+// ErrSecretNotFound.
+func (c *Client) ReadSecretAsOf(path string, t time.Time) (Secret, error) {
+	mdPath, err := c.secretPath(path, true)
+	if err != nil {
+		return Secret{}, err
+	}
+	md, err := c.ReadSecretMetadata(path)
+	if err != nil {
+		return Secret{}, err
+	}
+	v, ok := md.VersionAt(t)
+	if !ok || v.stateAt(t) == StateDeleted {
+		return Secret{}, &os.PathError{Op: "ReadSecretAsOf", Path: mdPath, Err: ErrSecretNotFound}
+	}
+	return c.ReadSecretVersion(path, v.Version)
+}
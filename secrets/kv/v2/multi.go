@@ -0,0 +1,78 @@
+package kv
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrUnknownMount is returned when a MultiClient has no mount registered with
+// the requested name.
+var ErrUnknownMount = errors.New("kv2: unknown mount")
+
+// MultiClient is an API client for several KVv2 secrets engines, each
+// registered under a name. All mounts share the same underlying Vault client.
+type MultiClient struct {
+	base   *Client
+	mounts map[string]string
+
+	mu      sync.Mutex
+	clients map[string]*Client
+}
+
+// NewMultiClient creates a new MultiClient for the given mapping of mount name
+// to the path the secrets engine is mounted at in Vault. The client of every
+// mount is configured with the given options, and a single Vault client is
+// created from them on first use and shared by every mount, as with ForMount.
+func NewMultiClient(mounts map[string]string, opts ...Option) *MultiClient {
+	m := &MultiClient{
+		base:    NewClient("", opts...),
+		mounts:  make(map[string]string, len(mounts)),
+		clients: make(map[string]*Client, len(mounts)),
+	}
+	for name, path := range mounts {
+		m.mounts[name] = path
+	}
+	return m
+}
+
+// ListMounts returns the sorted names of the registered mounts.
+func (m *MultiClient) ListMounts() []string {
+	names := make([]string, 0, len(m.mounts))
+	for name := range m.mounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Mount returns the client for the mount registered with the given name. If
+// the shared Vault client could not be created, that error is returned.
+func (m *MultiClient) Mount(name string) (*Client, error) {
+	path, ok := m.mounts[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownMount, name)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.clients[name]
+	if !ok {
+		c = m.base.ForMount(path)
+		m.clients[name] = c
+	}
+	if _, err := c.core.Logical(c.mountPath); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Read reads the latest secret version at the specified path of the mount
+// registered with the given name.
+func (m *MultiClient) Read(name, path string) (Secret, error) {
+	c, err := m.Mount(name)
+	if err != nil {
+		return Secret{}, err
+	}
+	return c.ReadSecretLatest(path)
+}
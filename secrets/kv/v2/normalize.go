@@ -0,0 +1,50 @@
+package kv
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// normalize recursively converts all numbers in v to json.Number.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			out[k] = normalize(val)
+		}
+		return out
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			if s, ok := k.(string); ok {
+				out[s] = normalize(val)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			out[i] = normalize(val)
+		}
+		return out
+	case float64:
+		return json.Number(strconv.FormatFloat(v, 'f', -1, 64))
+	case float32:
+		return json.Number(strconv.FormatFloat(float64(v), 'f', -1, 32))
+	case int:
+		return json.Number(strconv.Itoa(v))
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10))
+	case int32:
+		return json.Number(strconv.FormatInt(int64(v), 10))
+	case uint:
+		return json.Number(strconv.FormatUint(uint64(v), 10))
+	case uint64:
+		return json.Number(strconv.FormatUint(v, 10))
+	case uint32:
+		return json.Number(strconv.FormatUint(uint64(v), 10))
+	default:
+		return v
+	}
+}
@@ -0,0 +1,281 @@
+package kv
+
+import (
+	"context"
+	"crypto/cipher"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/mwalto7/vault"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithLogicalClient sets the Vault client used to make requests. If not set,
+// a client is created from the default Vault API configuration on first use.
+func WithLogicalClient(client vault.LogicalClient) Option {
+	return func(c *Client) {
+		c.core.LogicalClient = client
+	}
+}
+
+// WithAPIClient sets the Vault API client used to make requests. Unlike a
+// client set with WithLogicalClient, it is also used for features that need the
+// full API client, such as response wrapping and the namespace set with
+// WithNamespace, which is applied to a clone so client is not modified. If
+// both are set, requests are made with the WithLogicalClient client.
+func WithAPIClient(client *api.Client) Option {
+	return func(c *Client) {
+		c.core.APIClient = client
+	}
+}
+
+// WithDefaultMountPath sets the mount path used when the Client is created
+// with an empty mount path. Defaults to "/secret".
+func WithDefaultMountPath(path string) Option {
+	return func(c *Client) {
+		c.defaultMountPath = path
+	}
+}
+
+// WithHTTPClient sets the HTTP client of the Vault client the Client creates
+// on first use, such as one with a TLS configuration trusting a private CA or
+// presenting a client certificate. The rest of the configuration is read from
+// the default Vault API configuration, but its TLS settings, such as
+// VAULT_CACERT, only apply to the default HTTP client. It has no effect if a
+// client is set with WithLogicalClient or WithAPIClient.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.core.HTTPClient = client
+	}
+}
+
+// WithNamespace sets the Vault Enterprise namespace requests are made in. The
+// namespace is applied to the X-Vault-Namespace header of the Vault client the
+// Client creates on first use or sets with WithAPIClient; it has no effect on a
+// client set with WithLogicalClient.
+func WithNamespace(namespace string) Option {
+	return func(c *Client) {
+		c.core.Namespace = namespace
+	}
+}
+
+// WithRequestIDFunc sets the X-Request-Id header of each request made by the
+// Client to the ID returned by fn, to correlate the request across the logs of
+// a gateway in front of Vault and the Vault audit log. fn is called for every
+// request, including retries, so it typically generates a new ID each time; if
+// it returns the empty string, the header is not set.
+//
+// The header is set by the transport of the HTTP client of the Vault client the
+// Client creates on first use. Requests fail if a client is also set with
+// WithLogicalClient or WithAPIClient, whose HTTP client belongs to the caller;
+// wrap its transport with vault.RequestIDTransport instead. Requests also fail
+// if the Vault address is a unix socket.
+func WithRequestIDFunc(fn func() string) Option {
+	return func(c *Client) {
+		c.core.RequestID = func(context.Context) string { return fn() }
+	}
+}
+
+// WithUserAgent sets the User-Agent header of the requests made by the Client,
+// such as a service name to attribute requests to in Vault's audit logs. Like
+// the namespace set with WithNamespace, it is applied to the Vault client the
+// Client creates on first use or sets with WithAPIClient; it has no effect on a
+// client set with WithLogicalClient.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.core.UserAgent = ua
+	}
+}
+
+// WithNestedNormalization normalizes the data of read secrets so values have
+// consistent types at any depth: every number is a json.Number, every object
+// is a map[string]interface{} and every array is a []interface{}.
+func WithNestedNormalization() Option {
+	return func(c *Client) {
+		c.normalize = true
+	}
+}
+
+// WithRawPaths makes the Client use secret paths as the full path after the
+// mount, without inserting the "data", "metadata" or other endpoint segment,
+// for example for a mount behind a router that already injects the "data"
+// segment. Callers then choose the endpoint of each request themselves, such
+// as ReadSecretMetadata("metadata/app") for the metadata of the "app" secret.
+//
+// Operations that make requests to several endpoints of the same path, such as
+// UpdateSecret, ReadSecretAsOf and WithAutoCASCurrentVersion writes, send
+// them all to the same raw path and do not work as documented; use them only
+// with the default paths. Walk lists raw paths, including its root, so walk
+// the "metadata" path for the secrets of the mount. PolicyFor and PolicyPaths
+// are not affected.
+func WithRawPaths() Option {
+	return func(c *Client) {
+		c.rawPaths = true
+	}
+}
+
+// WithConcurrency sets the maximum number of concurrent requests made by the
+// batch operations of the Client, such as ReadSecrets. Defaults to 8.
+func WithConcurrency(n int) Option {
+	return func(c *Client) {
+		c.concurrency = n
+	}
+}
+
+// WithRequestTimeout bounds each request made by the Client by the timeout d.
+// Requests that do not complete in time return an error wrapping
+// context.DeadlineExceeded. By default, requests have no timeout.
+func WithRequestTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.core.Timeout = d
+	}
+}
+
+// WithStandbyRetry retries each request made by the Client that is rejected
+// by a Vault Enterprise performance standby node, up to retries times with
+// backoff between attempts. Each attempt is bounded by the request timeout.
+// Retrying is off by default, since it adds latency to requests that fail.
+// See vault.StandbyRetryClient.
+func WithStandbyRetry(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.core.StandbyRetries = retries
+		c.core.StandbyBackoff = backoff
+	}
+}
+
+// WithRejectEmptyWrites sets whether writes and patches of nil or empty data
+// fail with ErrEmptyData instead of storing an empty secret, which is almost
+// always a bug. Empty writes are allowed by default.
+func WithRejectEmptyWrites(reject bool) Option {
+	return func(c *Client) {
+		c.rejectEmpty = reject
+	}
+}
+
+// WithWriteValidator sets a validator that is called with the secret path and
+// data before every full write of a secret, including the writes of
+// operations such as WriteSecrets and UpdateSecret. Patches are not
+// validated. Use vault.RequireKeys to require keys in every secret. A non-nil
+// error aborts the write and is returned wrapped in an *os.PathError.
+func WithWriteValidator(validate vault.WriteValidator) Option {
+	return func(c *Client) {
+		c.validator = validate
+	}
+}
+
+// WithTokenRenewer sets a login function that returns a new Vault token, such
+// as by logging in with the AppRole or Kubernetes auth method. A request that
+// is denied, such as because the token expired, is retried once after setting
+// the new token on the Vault API client; a request denied again, or within 5
+// seconds of a login, is not retried, so genuine permission errors cause at
+// most one login every 5 seconds. The token is only renewed for the Vault API
+// client created by the Client or set with WithAPIClient. See
+// vault.TokenRenewingClient.
+func WithTokenRenewer(login func() (string, error)) Option {
+	return func(c *Client) {
+		c.core.Login = login
+	}
+}
+
+// WithCircuitBreaker makes the requests of the Client through the circuit
+// breaker b, which fails them with vault.ErrCircuitOpen without making them
+// after Vault failed too many consecutive requests. Share b between the
+// clients of the same Vault server, so that they stop and probe Vault
+// together. Each attempt of a retried request is made through the breaker.
+// See vault.CircuitBreaker.
+func WithCircuitBreaker(b *vault.CircuitBreaker) Option {
+	return func(c *Client) {
+		c.core.Breaker = b
+	}
+}
+
+// WithObserver sets an Observer that is notified before and after each request
+// the Client makes to Vault. By default, requests are not observed.
+func WithObserver(obs vault.Observer) Option {
+	return func(c *Client) {
+		c.core.Observer = obs
+	}
+}
+
+// WithLogger sets a Logger that each request the Client makes to Vault is
+// logged to. The operation, mount, path and version of requests are logged,
+// but never the secret data. By default, requests are not logged.
+func WithLogger(logger vault.Logger) Option {
+	return func(c *Client) {
+		c.core.Logger = logger
+	}
+}
+
+// WithTracerProvider makes the Client create an OpenTelemetry span with tp for
+// each request it makes to Vault, carrying the operation, mount, path and
+// version of the request, but never the secret data. Unlike wrapping the Vault
+// client with vaultotel.NewClient and WithLogicalClient, it keeps the other
+// options of the Client in effect. By default, requests are not traced. See
+// the vaultotel package.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *Client) {
+		c.core.TracerProvider = tp
+	}
+}
+
+// WithDryRun sets whether the Client runs in dry-run mode. In dry-run mode, the
+// destructive operations of the Client, DeleteSecretLatest,
+// DeleteSecretVersion, DeleteSecretVersionQuery, DestroySecretVersion and
+// DeleteSecretMetadata, including as part of operations such as PruneVersions
+// and DeleteTree, return without making their request to Vault. The skipped
+// requests are recorded and can be inspected with PlannedActions, for example
+// to ask for confirmation before running the operations for real. Other
+// requests, such as reads and writes, are made as usual. Operations that write
+// a secret before a destructive request, MoveSecret and WriteSecrets with the
+// AllOrNothing option, return ErrDryRunUnsupported instead.
+func WithDryRun(dryRun bool) Option {
+	return func(c *Client) {
+		c.dryRun = dryRun
+	}
+}
+
+// WithCASRetries sets the number of times UpdateSecret retries its update when
+// the secret was changed concurrently. Defaults to 3.
+func WithCASRetries(n int) Option {
+	return func(c *Client) {
+		c.casRetries = n
+	}
+}
+
+// WithAutoCASCurrentVersion sets whether writes without a check-and-set
+// version, such as WriteSecretLatest, are retried with the current version of
+// the secret as the check-and-set version when the secrets engine or the
+// secret metadata requires one. The current version is read from the secret
+// metadata, so the retried write fails with a check-and-set mismatch if the
+// secret is written concurrently. Otherwise, such writes return
+// ErrCASRequired.
+func WithAutoCASCurrentVersion(auto bool) Option {
+	return func(c *Client) {
+		c.autoCAS = auto
+	}
+}
+
+// WithFieldCipher encrypts the listed top-level fields of the secret data
+// with aead before they are written to Vault, and decrypts them when they are
+// read, so their plaintext values are never stored in Vault.
+//
+// Each value is encoded as JSON and sealed with a random nonce, using the path
+// of the secret relative to the mount and the field name as additional data,
+// so ciphertexts cannot be swapped between fields or copied to another secret.
+// The nonce and ciphertext are stored together as a base64 string prefixed
+// with "aead:v1:". Reading a secret whose listed field is not encrypted
+// returns ErrFieldNotEncrypted. UnwrapSecret looks up the path of a wrapped
+// secret with the "sys/wrapping/lookup" endpoint before unwrapping it.
+func WithFieldCipher(aead cipher.AEAD, fields ...string) Option {
+	return func(c *Client) {
+		f := &fieldCipher{aead: aead, fields: make(map[string]bool, len(fields))}
+		for _, field := range fields {
+			f.fields[field] = true
+		}
+		c.cipher = f
+	}
+}
@@ -0,0 +1,104 @@
+package kv
+
+import (
+	"strings"
+
+	"github.com/mwalto7/vault"
+)
+
+// PolicyPaths are the Vault API paths of every KVv2 endpoint for a secret, as
+// they must appear in a Vault policy.
+type PolicyPaths struct {
+	// The path used to read and write the secret data.
+	Data string
+
+	// The path used to read and write the secret metadata.
+	Metadata string
+
+	// The path used to soft delete secret versions.
+	Delete string
+
+	// The path used to restore soft deleted secret versions.
+	Undelete string
+
+	// The path used to permanently delete secret versions.
+	Destroy string
+
+	// The path used to read the secret subkeys.
+	Subkeys string
+}
+
+// PolicyPaths returns the Vault API paths a policy must grant for the secret
+// at the specified path.
+func (c *Client) PolicyPaths(path string) PolicyPaths {
+	return PolicyPaths{
+		Data:     c.policyPath("data", path),
+		Metadata: c.policyPath("metadata", path),
+		Delete:   c.policyPath("delete", path),
+		Undelete: c.policyPath("undelete", path),
+		Destroy:  c.policyPath("destroy", path),
+		Subkeys:  c.policyPath("subkeys", path),
+	}
+}
+
+// PolicyFor returns a Vault policy in HCL that grants the capabilities on the
+// secrets at the specified paths, which may use the policy "*" and "+"
+// wildcards. The capabilities are granted on the data path of each secret,
+// except "list", which is granted on the metadata path, since KVv2 lists keys
+// through the metadata endpoint:
+//
+//    policy := c.PolicyFor([]string{"read", "list"}, "apps/web/*")
+//
+// Grant the capabilities of the other endpoints, such as reading the secret
+// metadata or destroying versions, with PolicyPaths and vault.FormatPolicy.
+func (c *Client) PolicyFor(capabilities []string, paths ...string) string {
+	var data, metadata []string
+	for _, capability := range capabilities {
+		if capability == "list" {
+			metadata = append(metadata, capability)
+		} else {
+			data = append(data, capability)
+		}
+	}
+	var rules []vault.PolicyRule
+	for _, path := range paths {
+		p := c.PolicyPaths(path)
+		rules = append(rules,
+			vault.PolicyRule{Path: p.Data, Capabilities: data},
+			vault.PolicyRule{Path: p.Metadata, Capabilities: metadata},
+		)
+	}
+	return vault.FormatPolicy(rules...)
+}
+
+// DataPath returns the Vault API path of the data endpoint that the Client
+// reads and writes for the secret at the specified path, such as
+// "secret/data/apps/db", joined exactly as the Client joins it. If the path is
+// invalid, so that every request for it fails, the empty string is returned.
+func (c *Client) DataPath(path string) string {
+	return c.apiPath("data", path)
+}
+
+// MetadataPath returns the Vault API path of the metadata endpoint of the
+// secret at the specified path, such as "secret/metadata/apps/db". If the path
+// is invalid, the empty string is returned. See DataPath.
+func (c *Client) MetadataPath(path string) string {
+	return c.apiPath("metadata", path)
+}
+
+// apiPath returns the endpoint path of the secret without the leading slash,
+// or the empty string if the mount or secret path is invalid.
+func (c *Client) apiPath(endpoint, path string) string {
+	if c.mountErr != nil {
+		return ""
+	}
+	p, err := c.endpointPath(endpoint, path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(p, "/")
+}
+
+func (c *Client) policyPath(endpoint, path string) string {
+	return strings.TrimPrefix(pathJoin(c.mountPath, endpoint, c.prefix, path), "/")
+}
@@ -0,0 +1,100 @@
+package kv
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// PruneVersions destroys the old versions of the secret at the specified path
+// using the DefaultClient.
+func PruneVersions(path string, keepLatest int) error {
+	return DefaultClient.PruneVersions(path, keepLatest)
+}
+
+// PruneOlderThan destroys the versions of the secret at the specified path
+// created longer than age ago using the DefaultClient.
+func PruneOlderThan(path string, age time.Duration) error {
+	return DefaultClient.PruneOlderThan(path, age)
+}
+
+// PruneVersions permanently destroys the versions of the secret at the
+// specified path that are older than its newest keepLatest live versions,
+// including older soft deleted versions. If the secret has no more than
+// keepLatest live versions, nothing is destroyed.
+//
+// The current version of the secret is never destroyed, even if keepLatest is
+// zero.
+func (c *Client) PruneVersions(path string, keepLatest int) error {
+	if keepLatest < 0 {
+		return errors.New("kv2: keepLatest must not be negative")
+	}
+	md, err := c.ReadSecretMetadata(path)
+	if err != nil {
+		return err
+	}
+	versions := pruneVersions(md, keepLatest)
+	if len(versions) == 0 {
+		return nil
+	}
+	return c.DestroySecretVersion(path, versions...)
+}
+
+// pruneVersions returns the versions in the metadata that PruneVersions
+// destroys, in ascending order.
+func pruneVersions(md SecretMetadata, keepLatest int) []int {
+	live := md.LiveVersions()
+	if len(live) <= keepLatest {
+		return nil
+	}
+	// Versions older than the oldest kept live version are destroyed, or all
+	// versions if none are kept.
+	var oldestKept int
+	if keepLatest > 0 {
+		oldestKept = live[len(live)-keepLatest]
+	}
+	var versions []int
+	for k, v := range md.Versions {
+		n, err := strconv.Atoi(k)
+		if err != nil || v.Destroyed || n == md.CurrentVersion || (keepLatest > 0 && n >= oldestKept) {
+			continue
+		}
+		versions = append(versions, n)
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// PruneOlderThan permanently destroys the versions of the secret at the
+// specified path that were created longer than age ago, including soft deleted
+// versions, to enforce a time-based retention policy.
+//
+// The current version of the secret is never destroyed, however old it is.
+func (c *Client) PruneOlderThan(path string, age time.Duration) error {
+	if age < 0 {
+		return errors.New("kv2: age must not be negative")
+	}
+	md, err := c.ReadSecretMetadata(path)
+	if err != nil {
+		return err
+	}
+	versions := pruneOlderThan(md, time.Now().Add(-age))
+	if len(versions) == 0 {
+		return nil
+	}
+	return c.DestroySecretVersion(path, versions...)
+}
+
+// pruneOlderThan returns the versions in the metadata that PruneOlderThan
+// destroys for versions created before cutoff, in ascending order.
+func pruneOlderThan(md SecretMetadata, cutoff time.Time) []int {
+	var versions []int
+	for _, v := range md.SortedVersions() {
+		if v.Destroyed || v.Version == md.CurrentVersion || !v.CreatedTime.Before(cutoff) {
+			continue
+		}
+		versions = append(versions, v.Version)
+	}
+	return versions
+}
@@ -0,0 +1,59 @@
+package kv
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// ReadRaw reads the latest secret version at the specified path using the
+// DefaultClient and returns the Vault response untouched.
+func ReadRaw(path string) (*api.Secret, error) {
+	return DefaultClient.ReadRaw(path)
+}
+
+// ReadRawVersion reads the secret version at the specified path using the
+// DefaultClient and returns the Vault response untouched.
+func ReadRawVersion(path string, version int) (*api.Secret, error) {
+	return DefaultClient.ReadRawVersion(path, version)
+}
+
+// ReadRaw reads the latest secret version at the specified path and returns
+// the Vault response untouched, for access to the fields that Secret does not
+// expose, such as the request ID, lease and warnings. See ReadRawVersion.
+func (c *Client) ReadRaw(path string) (*api.Secret, error) {
+	return c.ReadRawVersion(path, -1)
+}
+
+// ReadRawVersion reads the secret version at the specified path and returns
+// the Vault response untouched. If the version is negative, the latest secret
+// version is read.
+//
+// The response data is the KVv2 envelope: the secret data is nested under the
+// "data" key and the version metadata under the "metadata" key, unlike the
+// KVv1 response data, which is the secret data itself. Fields encrypted with
+// WithFieldCipher are not decrypted. If no secret is stored at the path, nil
+// is returned; a deleted or destroyed version is returned with nil data.
+//
+// See https://www.vaultproject.io/api-docs/secret/kv/kv-v2#read-secret-version.
+func (c *Client) ReadRawVersion(path string, version int) (*api.Secret, error) {
+	path, err := c.secretPath(path, false)
+	if err != nil {
+		return nil, err
+	}
+	client, err := c.vaultClient()
+	if err != nil {
+		return nil, err
+	}
+	var secret *api.Secret
+	if version > -1 {
+		secret, err = client.ReadWithData(path, map[string][]string{"version": {strconv.Itoa(version)}})
+	} else {
+		secret, err = client.Read(path)
+	}
+	if err != nil {
+		return nil, &os.PathError{Op: "ReadRawVersion", Path: path, Err: err}
+	}
+	return secret, nil
+}
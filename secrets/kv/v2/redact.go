@@ -0,0 +1,45 @@
+package kv
+
+import "github.com/mwalto7/vault"
+
+// DefaultRedactPlaceholder is the placeholder that replaces redacted values.
+const DefaultRedactPlaceholder = vault.RedactedValue
+
+// RedactOption configures how secret data is redacted.
+type RedactOption = vault.RedactOption
+
+// RedactPlaceholder sets the placeholder that replaces redacted values.
+// Defaults to DefaultRedactPlaceholder.
+func RedactPlaceholder(placeholder string) RedactOption {
+	return vault.RedactPlaceholder(placeholder)
+}
+
+// RevealLength appends the length of redacted string values to the
+// placeholder, e.g. "***(12)".
+func RevealLength() RedactOption {
+	return vault.RevealLength()
+}
+
+// Redacted returns a copy of the secret data with every value replaced by a
+// placeholder, like vault.RedactData. Nested maps and slices are redacted
+// recursively, so the keys and structure of the secret are preserved.
+func (s Secret) Redacted(opts ...RedactOption) map[string]interface{} {
+	return vault.RedactData(s.Data, opts...)
+}
+
+// ReadSecretRedacted reads the latest secret version at the specified path
+// using the DefaultClient and returns it along with a redacted copy of its
+// data.
+func ReadSecretRedacted(path string, opts ...RedactOption) (Secret, map[string]interface{}, error) {
+	return DefaultClient.ReadSecretRedacted(path, opts...)
+}
+
+// ReadSecretRedacted reads the latest secret version at the specified path and
+// returns it along with a redacted copy of its data that is safe to log.
+func (c *Client) ReadSecretRedacted(path string, opts ...RedactOption) (Secret, map[string]interface{}, error) {
+	secret, err := c.ReadSecretLatest(path)
+	if err != nil {
+		return Secret{}, nil, err
+	}
+	return secret, secret.Redacted(opts...), nil
+}
@@ -0,0 +1,71 @@
+package kv
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RenameOption configures how secret keys are renamed.
+type RenameOption func(*renameConfig)
+
+type renameConfig struct {
+	dropUnmapped bool
+}
+
+// DropUnmapped drops the keys that are not present in the rename mapping
+// instead of passing them through unchanged.
+func DropUnmapped() RenameOption {
+	return func(cfg *renameConfig) {
+		cfg.dropUnmapped = true
+	}
+}
+
+// ReadSecretRenamed reads the latest secret version at the specified path
+// using the DefaultClient and renames its keys.
+func ReadSecretRenamed(path string, rename map[string]string, opts ...RenameOption) (map[string]interface{}, error) {
+	return DefaultClient.ReadSecretRenamed(path, rename, opts...)
+}
+
+// ReadSecretRenamed reads the latest secret version at the specified path and
+// returns its data with the keys renamed according to the given mapping of
+// source key to target key. Keys not present in the mapping are passed through
+// unchanged unless the DropUnmapped option is given.
+//
+// An error is returned if two keys are renamed to the same target key.
+func (c *Client) ReadSecretRenamed(path string, rename map[string]string, opts ...RenameOption) (map[string]interface{}, error) {
+	secret, err := c.ReadSecretLatest(path)
+	if err != nil {
+		return nil, err
+	}
+	return renameKeys(secret.Data, rename, opts...)
+}
+
+func renameKeys(data map[string]interface{}, rename map[string]string, opts ...RenameOption) (map[string]interface{}, error) {
+	var cfg renameConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make(map[string]interface{}, len(data))
+	from := make(map[string]string, len(data))
+	for _, k := range keys {
+		target, ok := rename[k]
+		if !ok {
+			if cfg.dropUnmapped {
+				continue
+			}
+			target = k
+		}
+		if src, ok := from[target]; ok {
+			return nil, fmt.Errorf("kv2: keys %q and %q are both renamed to %q", src, k, target)
+		}
+		from[target] = k
+		out[target] = data[k]
+	}
+	return out, nil
+}
@@ -0,0 +1,31 @@
+package kv
+
+import "errors"
+
+// RollbackSecret restores the secret version at the specified path using the
+// DefaultClient.
+func RollbackSecret(path string, version int) (SecretVersion, error) {
+	return DefaultClient.RollbackSecret(path, version)
+}
+
+// RollbackSecret restores the secret version at the specified path by writing
+// its data as a new version, so the secret history is preserved. If the
+// version was deleted or destroyed, ErrSecretNotFound is returned.
+//
+// The write uses the current version of the secret for CAS, so it succeeds
+// regardless of whether the engine requires CAS, and fails if the secret was
+// updated concurrently.
+func (c *Client) RollbackSecret(path string, version int) (SecretVersion, error) {
+	if version < 1 {
+		return SecretVersion{}, errors.New("kv2: version must be positive")
+	}
+	md, err := c.ReadSecretMetadata(path)
+	if err != nil {
+		return SecretVersion{}, err
+	}
+	secret, err := c.ReadSecretVersion(path, version)
+	if err != nil {
+		return SecretVersion{}, err
+	}
+	return c.WriteSecretVersion(path, md.CurrentVersion, secret.Data)
+}
@@ -0,0 +1,33 @@
+package kv
+
+import "github.com/mwalto7/vault"
+
+var _ vault.SecretStore = (*Client)(nil)
+
+// Read reads the data of the latest secret version at the specified path.
+func (c *Client) Read(path string) (map[string]interface{}, error) {
+	secret, err := c.ReadSecretLatest(path)
+	if err != nil {
+		return nil, err
+	}
+	return secret.Data, nil
+}
+
+// Write creates a new secret version at the specified path, like
+// WriteSecretLatest.
+func (c *Client) Write(path string, data map[string]interface{}) error {
+	_, err := c.WriteSecretLatest(path, data)
+	return err
+}
+
+// Delete soft deletes the latest secret version at the specified path, like
+// DeleteSecretLatest.
+func (c *Client) Delete(path string) error {
+	return c.DeleteSecretLatest(path)
+}
+
+// List lists the secret keys at the specified path. It is equivalent to
+// ListSecrets.
+func (c *Client) List(path string) ([]string, error) {
+	return c.ListSecrets(path)
+}
@@ -0,0 +1,100 @@
+package kv
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// ErrCASMismatch is returned when a secret was changed concurrently, so its
+// current version did not match the check-and-set version of a write.
+var ErrCASMismatch = errors.New("kv2: check-and-set version mismatch")
+
+// ErrCASRequired is returned when a secret was written without a check-and-set
+// version, but the secrets engine or the secret metadata requires one. Write
+// the secret with WriteSecretVersion, or create the Client with
+// WithAutoCASCurrentVersion.
+var ErrCASRequired = errors.New("kv2: check-and-set version required, write with WriteSecretVersion")
+
+// UpdateSecret updates the latest secret version at the specified path with
+// mutate using the DefaultClient.
+func UpdateSecret(path string, mutate func(cur map[string]interface{}) (map[string]interface{}, error)) (SecretVersion, error) {
+	return DefaultClient.UpdateSecret(path, mutate)
+}
+
+// UpdateSecret reads the latest secret version at the specified path, passes
+// its data to mutate and writes the returned data as a new version, using the
+// read version for check-and-set. If the secret was changed concurrently, the
+// update is retried with the new latest version, as many times as configured
+// with WithCASRetries. If the retries are exhausted, ErrCASMismatch is
+// returned.
+//
+// If no data is stored at the path, mutate is called with nil data and the
+// secret is written only if it is still not stored. An error returned by
+// mutate is returned as is, without writing the secret.
+func (c *Client) UpdateSecret(path string, mutate func(cur map[string]interface{}) (map[string]interface{}, error)) (SecretVersion, error) {
+	dataPath, err := c.secretPath(path, false)
+	if err != nil {
+		return SecretVersion{}, err
+	}
+	for attempt := 0; attempt <= c.casRetries; attempt++ {
+		cur, version, err := c.readForUpdate(path)
+		if err != nil {
+			return SecretVersion{}, err
+		}
+		data, err := mutate(cur)
+		if err != nil {
+			return SecretVersion{}, err
+		}
+		v, err := c.WriteSecretVersion(path, version, data)
+		if !isCASMismatch(err) {
+			return v, err
+		}
+	}
+	return SecretVersion{}, &os.PathError{Op: "UpdateSecret", Path: dataPath, Err: ErrCASMismatch}
+}
+
+// readForUpdate returns the latest secret data at the path and its version for
+// check-and-set. If the latest version is deleted or destroyed, the data is
+// nil and the version is the current version from the secret metadata.
+func (c *Client) readForUpdate(path string) (map[string]interface{}, int, error) {
+	secret, err := c.ReadSecretLatest(path)
+	if err == nil {
+		return secret.Data, secret.Metadata.Version, nil
+	}
+	if !errors.Is(err, ErrSecretNotFound) {
+		return nil, 0, err
+	}
+	md, err := c.ReadSecretMetadata(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("kv2: reading current version: %w", err)
+	}
+	return nil, md.CurrentVersion, nil
+}
+
+func isCASMismatch(err error) bool {
+	return hasBadRequestError(err, "check-and-set parameter did not match")
+}
+
+func isCASRequired(err error) bool {
+	return hasBadRequestError(err, "check-and-set parameter required")
+}
+
+// hasBadRequestError reports whether err is a Vault bad request error with a
+// message containing msg.
+func hasBadRequestError(err error, msg string) bool {
+	var respErr *api.ResponseError
+	if !errors.As(err, &respErr) || respErr.StatusCode != http.StatusBadRequest {
+		return false
+	}
+	for _, m := range respErr.Errors {
+		if strings.Contains(m, msg) {
+			return true
+		}
+	}
+	return false
+}
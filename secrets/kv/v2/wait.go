@@ -0,0 +1,105 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// State is the lifecycle state of a secret version.
+type State int
+
+const (
+	// StateAbsent means the secret version does not exist.
+	StateAbsent State = iota
+
+	// StateLive means the secret version exists and its data can be read.
+	StateLive
+
+	// StateDeleted means the secret version was soft deleted and can be
+	// restored with UndeleteSecretVersion.
+	StateDeleted
+
+	// StateDestroyed means the secret version data was permanently deleted.
+	StateDestroyed
+)
+
+func (s State) String() string {
+	switch s {
+	case StateAbsent:
+		return "absent"
+	case StateLive:
+		return "live"
+	case StateDeleted:
+		return "deleted"
+	case StateDestroyed:
+		return "destroyed"
+	default:
+		return "State(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+
+// metadataState returns the state of the secret version in the metadata at
+// the given time. A destroyed version is reported as StateDestroyed even if it
+// was also deleted, and a version scheduled for deletion is live until its
+// deletion time has passed.
+func metadataState(md SecretMetadata, version int, now time.Time) State {
+	v, ok := md.Versions[strconv.Itoa(version)]
+	if !ok {
+		return StateAbsent
+	}
+	return v.stateAt(now)
+}
+
+// maxWaitErrors is the number of consecutive failed metadata reads after which
+// WaitForState gives up.
+const maxWaitErrors = 3
+
+// WaitForState polls the metadata of the secret at the specified path using
+// the DefaultClient until the version reaches the desired state.
+func WaitForState(ctx context.Context, path string, version int, want State, poll time.Duration) error {
+	return DefaultClient.WaitForState(ctx, path, version, want, poll)
+}
+
+// WaitForState polls the metadata of the secret at the specified path every
+// poll interval until the secret version reaches the desired state, or the
+// context is done. If the poll interval is not positive, it defaults to one
+// second.
+//
+// A failed metadata read is retried on the next poll. If the reads fail
+// maxWaitErrors times in a row, the last error is returned.
+func (c *Client) WaitForState(ctx context.Context, path string, version int, want State, poll time.Duration) error {
+	if version < 1 {
+		return errors.New("kv2: version must be positive")
+	}
+	if poll <= 0 {
+		poll = time.Second
+	}
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	var errs int
+	for {
+		md, err := c.ReadSecretMetadata(path)
+		switch {
+		case err != nil:
+			if errs++; errs >= maxWaitErrors {
+				return err
+			}
+		case metadataState(md, version, time.Now()) == want:
+			return nil
+		default:
+			errs = 0
+		}
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return fmt.Errorf("kv2: waiting for %s/%d to be %s: %w (last error: %v)", path, version, want, ctx.Err(), err)
+			}
+			return fmt.Errorf("kv2: waiting for %s/%d to be %s: %w", path, version, want, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package kv
+
+import "strings"
+
+// Walk calls fn for every secret under the root path using the DefaultClient.
+func Walk(root string, fn func(path string) error) error {
+	return DefaultClient.Walk(root, fn)
+}
+
+// Walk calls fn with the path of every secret under the root path, descending
+// into every key that ends with a "/". An empty root walks the entire mount.
+// The paths passed to fn are relative to the mount, like the paths accepted by
+// the other Client methods. With WithRawPaths, the root and the paths passed
+// to fn are raw paths, so Walk("metadata", fn) walks the secrets of the mount.
+//
+// If fn returns an error, Walk stops and returns that error.
+func (c *Client) Walk(root string, fn func(path string) error) error {
+	return c.walk(strings.Trim(root, "/"), fn, make(map[string]bool))
+}
+
+func (c *Client) walk(dir string, fn func(path string) error, visited map[string]bool) error {
+	if visited[dir] {
+		return nil
+	}
+	visited[dir] = true
+	keys, err := c.listKeys(dir)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		p := pathJoin(dir, key)
+		if strings.HasSuffix(key, "/") {
+			if err := c.walk(p, fn, visited); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listKeys lists the keys at the specified path, or at the root of the mount,
+// or of the prefix of a Client returned by Sub, if the path is empty.
+func (c *Client) listKeys(path string) ([]string, error) {
+	if path != "" {
+		return c.ListSecrets(path)
+	}
+	return c.list("ListSecrets", c.endpointRoot("metadata"))
+}
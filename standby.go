@@ -0,0 +1,162 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// IsStandbyError reports whether err is a Vault error returned by a standby
+// node that could not serve the request, such as a Vault Enterprise
+// performance standby that has not caught up with the active node or could
+// not forward the request to it. Such requests were not processed, so they
+// can be retried.
+func IsStandbyError(err error) bool {
+	var respErr *api.ResponseError
+	if !errors.As(err, &respErr) {
+		return false
+	}
+	if respErr.StatusCode == http.StatusPreconditionFailed {
+		return true
+	}
+	for _, msg := range respErr.Errors {
+		if strings.Contains(msg, "node not active but active cluster node not found") ||
+			strings.Contains(msg, "performance standby") {
+			return true
+		}
+	}
+	return false
+}
+
+// StandbyRetryClient returns a LogicalClient that retries the requests made
+// with client that fail with a standby error, as reported by IsStandbyError,
+// up to retries times, waiting backoff before each retry. Redirects from
+// standby nodes to the active node are already followed by the Vault API
+// client, so only the requests that a standby node rejected are retried. If
+// retries is not positive, client is returned unchanged.
+//
+// Requests made with a context stop retrying when the context is done.
+func StandbyRetryClient(client LogicalClient, retries int, backoff time.Duration) LogicalClient {
+	if retries <= 0 {
+		return client
+	}
+	return &standbyRetryClient{client: client, retries: retries, backoff: backoff}
+}
+
+type standbyRetryClient struct {
+	client  LogicalClient
+	retries int
+	backoff time.Duration
+}
+
+func (c *standbyRetryClient) Read(path string) (*api.Secret, error) {
+	return c.do(context.Background(), func() (*api.Secret, error) {
+		return c.client.Read(path)
+	})
+}
+
+func (c *standbyRetryClient) ReadWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.do(ctx, func() (*api.Secret, error) {
+		return c.client.ReadWithContext(ctx, path)
+	})
+}
+
+func (c *standbyRetryClient) ReadWithData(path string, data map[string][]string) (*api.Secret, error) {
+	return c.do(context.Background(), func() (*api.Secret, error) {
+		return c.client.ReadWithData(path, data)
+	})
+}
+
+func (c *standbyRetryClient) ReadWithDataWithContext(ctx context.Context, path string, data map[string][]string) (*api.Secret, error) {
+	return c.do(ctx, func() (*api.Secret, error) {
+		return c.client.ReadWithDataWithContext(ctx, path, data)
+	})
+}
+
+func (c *standbyRetryClient) List(path string) (*api.Secret, error) {
+	return c.do(context.Background(), func() (*api.Secret, error) {
+		return c.client.List(path)
+	})
+}
+
+func (c *standbyRetryClient) ListWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.do(ctx, func() (*api.Secret, error) {
+		return c.client.ListWithContext(ctx, path)
+	})
+}
+
+func (c *standbyRetryClient) Write(path string, data map[string]interface{}) (*api.Secret, error) {
+	return c.do(context.Background(), func() (*api.Secret, error) {
+		return c.client.Write(path, data)
+	})
+}
+
+func (c *standbyRetryClient) WriteWithContext(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error) {
+	return c.do(ctx, func() (*api.Secret, error) {
+		return c.client.WriteWithContext(ctx, path, data)
+	})
+}
+
+func (c *standbyRetryClient) JSONMergePatch(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error) {
+	return c.do(ctx, func() (*api.Secret, error) {
+		return c.client.JSONMergePatch(ctx, path, data)
+	})
+}
+
+func (c *standbyRetryClient) Delete(path string) (*api.Secret, error) {
+	return c.do(context.Background(), func() (*api.Secret, error) {
+		return c.client.Delete(path)
+	})
+}
+
+func (c *standbyRetryClient) DeleteWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.do(ctx, func() (*api.Secret, error) {
+		return c.client.DeleteWithContext(ctx, path)
+	})
+}
+
+func (c *standbyRetryClient) DeleteWithData(path string, data map[string][]string) (*api.Secret, error) {
+	return c.do(context.Background(), func() (*api.Secret, error) {
+		return c.client.DeleteWithData(path, data)
+	})
+}
+
+func (c *standbyRetryClient) DeleteWithDataWithContext(ctx context.Context, path string, data map[string][]string) (*api.Secret, error) {
+	return c.do(ctx, func() (*api.Secret, error) {
+		return c.client.DeleteWithDataWithContext(ctx, path, data)
+	})
+}
+
+func (c *standbyRetryClient) Unwrap(wrappingToken string) (*api.Secret, error) {
+	return c.do(context.Background(), func() (*api.Secret, error) {
+		return c.client.Unwrap(wrappingToken)
+	})
+}
+
+func (c *standbyRetryClient) UnwrapWithContext(ctx context.Context, wrappingToken string) (*api.Secret, error) {
+	return c.do(ctx, func() (*api.Secret, error) {
+		return c.client.UnwrapWithContext(ctx, wrappingToken)
+	})
+}
+
+// do runs fn, running it again after the backoff while it fails with a
+// standby error and retries remain.
+func (c *standbyRetryClient) do(ctx context.Context, fn func() (*api.Secret, error)) (*api.Secret, error) {
+	for attempt := 0; ; attempt++ {
+		secret, err := fn()
+		if err == nil || attempt == c.retries || !IsStandbyError(err) {
+			return secret, err
+		}
+		t := time.NewTimer(c.backoff)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return secret, err
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package vault_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/vault/api"
+	"github.com/mwalto7/vault"
+	"github.com/mwalto7/vault/vaultmock"
+)
+
+func TestIsStandbyError(t *testing.T) {
+	tt := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "PreconditionFailed", err: &api.ResponseError{StatusCode: http.StatusPreconditionFailed}, want: true},
+		{
+			name: "ActiveNodeNotFound",
+			err:  &api.ResponseError{StatusCode: http.StatusInternalServerError, Errors: []string{"local node not active but active cluster node not found"}},
+			want: true,
+		},
+		{name: "Forbidden", err: &api.ResponseError{StatusCode: http.StatusForbidden}},
+		{name: "Other", err: errors.New("error")},
+		{name: "Nil"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := vault.IsStandbyError(tc.err); got != tc.want {
+				t.Fatalf("got %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStandbyRetryClient(t *testing.T) {
+	standby := &api.ResponseError{StatusCode: http.StatusPreconditionFailed}
+	want := &api.Secret{Data: map[string]interface{}{"version": 1}}
+
+	t.Run("RetriesStandbyErrors", func(t *testing.T) {
+		m := vaultmock.NewLogicalClient(gomock.NewController(t))
+		gomock.InOrder(
+			m.EXPECT().Write("secret/data/test", gomock.Any()).Return(nil, standby).Times(2),
+			m.EXPECT().Write("secret/data/test", gomock.Any()).Return(want, nil),
+		)
+
+		secret, err := vault.StandbyRetryClient(m, 2, time.Millisecond).Write("secret/data/test", nil)
+		if err != nil {
+			t.Fatalf("err: got %v, want nil", err)
+		}
+		if secret != want {
+			t.Fatalf("secret: got %v, want %v", secret, want)
+		}
+	})
+
+	t.Run("StopsAfterRetries", func(t *testing.T) {
+		m := vaultmock.NewLogicalClient(gomock.NewController(t))
+		m.EXPECT().Write("secret/data/test", gomock.Any()).Return(nil, standby).Times(3)
+
+		if _, err := vault.StandbyRetryClient(m, 2, time.Millisecond).Write("secret/data/test", nil); !errors.Is(err, standby) {
+			t.Fatalf("err: got %v, want %v", err, standby)
+		}
+	})
+
+	t.Run("DoesNotRetryOtherErrors", func(t *testing.T) {
+		forbidden := &api.ResponseError{StatusCode: http.StatusForbidden}
+		m := vaultmock.NewLogicalClient(gomock.NewController(t))
+		m.EXPECT().Read("secret/data/test").Return(nil, forbidden)
+
+		if _, err := vault.StandbyRetryClient(m, 2, time.Millisecond).Read("secret/data/test"); !errors.Is(err, forbidden) {
+			t.Fatalf("err: got %v, want %v", err, forbidden)
+		}
+	})
+
+	t.Run("StopsWhenContextDone", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		m := vaultmock.NewLogicalClient(gomock.NewController(t))
+		m.EXPECT().ReadWithContext(ctx, "secret/data/test").DoAndReturn(func(context.Context, string) (*api.Secret, error) {
+			cancel()
+			return nil, standby
+		})
+
+		if _, err := vault.StandbyRetryClient(m, 2, time.Hour).ReadWithContext(ctx, "secret/data/test"); !errors.Is(err, standby) {
+			t.Fatalf("err: got %v, want %v", err, standby)
+		}
+	})
+}
@@ -0,0 +1,17 @@
+package vault
+
+// SecretStore is a key-value secret store that is independent of the version
+// of the secrets engine. It is implemented by the KVv1 and KVv2 clients.
+type SecretStore interface {
+	// Read returns the data of the secret at the specified path.
+	Read(path string) (map[string]interface{}, error)
+
+	// Write creates or updates the secret at the specified path.
+	Write(path string, data map[string]interface{}) error
+
+	// Delete deletes the secret at the specified path.
+	Delete(path string) error
+
+	// List lists the secret keys at the specified path.
+	List(path string) ([]string, error)
+}
@@ -0,0 +1,232 @@
+// Package sys provides an API client for the Vault system backend endpoints
+// that manage secrets engine mounts.
+//
+// To enable a KVv2 secrets engine before writing secrets to it, for example
+// when bootstrapping a test server, use the DefaultClient:
+//
+//    // Enable a KVv2 secrets engine at "/my-kv" that keeps 5 versions.
+//    sys.EnableMount("my-kv", sys.MountOptions{Version: 2, MaxVersions: 5})
+//
+// See https://www.vaultproject.io/api-docs/system/mounts for more information
+// on the available endpoints.
+package sys
+
+import (
+	"errors"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mwalto7/vault"
+	"github.com/mwalto7/vault/internal/vaultclient"
+)
+
+const defaultMountType = "kv"
+
+// ErrEmptyPath is returned when the mount path is empty.
+var ErrEmptyPath = errors.New("sys: mount path is empty")
+
+// ErrPermissionDenied is returned when the Vault token is invalid or is not
+// permitted to make the request. Use IsPermissionDenied to also match errors
+// of clients that do not classify their errors.
+var ErrPermissionDenied = vault.ErrPermissionDenied
+
+// IsPermissionDenied reports whether err is a Vault permission denied error.
+// See vault.IsPermissionDenied.
+func IsPermissionDenied(err error) bool {
+	return vault.IsPermissionDenied(err)
+}
+
+// IsNotFound reports whether err is a Vault not found error. See
+// vault.IsNotFound.
+func IsNotFound(err error) bool {
+	return vault.IsNotFound(err)
+}
+
+// DefaultClient is a system backend API client created from the default Vault
+// API configuration on first use.
+var DefaultClient = NewClient()
+
+// EnableMount enables a secrets engine at the specified path using the
+// DefaultClient.
+//
+// See https://www.vaultproject.io/api-docs/system/mounts#enable-secrets-engine.
+func EnableMount(path string, opts MountOptions) error {
+	return DefaultClient.EnableMount(path, opts)
+}
+
+// TuneMount updates the configuration of the secrets engine mounted at the
+// specified path using the DefaultClient.
+//
+// See https://www.vaultproject.io/api-docs/system/mounts#tune-mount-configuration.
+func TuneMount(path string, opts TuneOptions) error {
+	return DefaultClient.TuneMount(path, opts)
+}
+
+// MountOptions configures a secrets engine enabled with EnableMount.
+type MountOptions struct {
+	// The type of the secrets engine. Defaults to "kv".
+	Type string
+
+	// The human-friendly description of the mount.
+	Description string
+
+	// The version of the KV secrets engine, 1 or 2. Zero uses the Vault
+	// default, which is version 1.
+	Version int
+
+	// The maximum number of versions to keep per secret of a KVv2 secrets
+	// engine. Zero uses the engine default. It is set with the engine
+	// configuration after the mount is enabled, and only applies when Version
+	// is 2.
+	MaxVersions int
+
+	// The default lease duration of the mount. Zero uses the system default.
+	DefaultLeaseTTL time.Duration
+
+	// The maximum lease duration of the mount. Zero uses the system default.
+	MaxLeaseTTL time.Duration
+}
+
+// TuneOptions configures the settings updated with TuneMount. Zero fields
+// leave the settings unchanged.
+type TuneOptions struct {
+	// The human-friendly description of the mount.
+	Description string
+
+	// The default lease duration of the mount.
+	DefaultLeaseTTL time.Duration
+
+	// The maximum lease duration of the mount.
+	MaxLeaseTTL time.Duration
+}
+
+// Client is an API client for the Vault system backend mount endpoints.
+//
+// See https://www.vaultproject.io/api-docs/system/mounts.
+type Client struct {
+	core *vaultclient.Client
+}
+
+// NewClient creates a new system backend API client configured with the given
+// options.
+func NewClient(opts ...Option) *Client {
+	c := &Client{core: &vaultclient.Client{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// EnableMount enables a secrets engine at the specified path. For a KVv2
+// secrets engine with MaxVersions set, the engine configuration is written
+// after the mount is enabled.
+//
+// See https://www.vaultproject.io/api-docs/system/mounts#enable-secrets-engine.
+func (c *Client) EnableMount(path string, opts MountOptions) error {
+	mount, err := mountPath(path)
+	if err != nil {
+		return err
+	}
+	client, err := c.vaultClient()
+	if err != nil {
+		return err
+	}
+	typ := opts.Type
+	if typ == "" {
+		typ = defaultMountType
+	}
+	data := map[string]interface{}{"type": typ}
+	if opts.Description != "" {
+		data["description"] = opts.Description
+	}
+	if cfg := leaseConfig(opts.DefaultLeaseTTL, opts.MaxLeaseTTL); len(cfg) > 0 {
+		data["config"] = cfg
+	}
+	if opts.Version > 0 {
+		data["options"] = map[string]interface{}{"version": strconv.Itoa(opts.Version)}
+	}
+	endpoint := pathJoin("sys/mounts", mount)
+	if _, err := client.Write(endpoint, data); err != nil {
+		return &os.PathError{Op: "EnableMount", Path: endpoint, Err: err}
+	}
+	if opts.Version != 2 || opts.MaxVersions == 0 {
+		return nil
+	}
+	endpoint = pathJoin(mount, "config")
+	if _, err := client.Write(endpoint, map[string]interface{}{"max_versions": opts.MaxVersions}); err != nil {
+		return &os.PathError{Op: "EnableMount", Path: endpoint, Err: err}
+	}
+	return nil
+}
+
+// TuneMount updates the configuration of the secrets engine mounted at the
+// specified path.
+//
+// See https://www.vaultproject.io/api-docs/system/mounts#tune-mount-configuration.
+func (c *Client) TuneMount(path string, opts TuneOptions) error {
+	mount, err := mountPath(path)
+	if err != nil {
+		return err
+	}
+	client, err := c.vaultClient()
+	if err != nil {
+		return err
+	}
+	data := leaseConfig(opts.DefaultLeaseTTL, opts.MaxLeaseTTL)
+	if opts.Description != "" {
+		data["description"] = opts.Description
+	}
+	endpoint := pathJoin("sys/mounts", mount, "tune")
+	if _, err := client.Write(endpoint, data); err != nil {
+		return &os.PathError{Op: "TuneMount", Path: endpoint, Err: err}
+	}
+	return nil
+}
+
+// leaseConfig returns the lease settings of a mount configuration, omitting
+// zero durations.
+func leaseConfig(defaultTTL, maxTTL time.Duration) map[string]interface{} {
+	cfg := make(map[string]interface{})
+	if defaultTTL > 0 {
+		cfg["default_lease_ttl"] = defaultTTL.String()
+	}
+	if maxTTL > 0 {
+		cfg["max_lease_ttl"] = maxTTL.String()
+	}
+	return cfg
+}
+
+var pathJoin = path.Join
+
+// mountPath returns the mount path without leading and trailing slashes.
+func mountPath(path string) (string, error) {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return "", ErrEmptyPath
+	}
+	return path, nil
+}
+
+func (c *Client) vaultClient() (vault.LogicalClient, error) {
+	return c.core.Logical("")
+}
+
+// Close closes the idle connections of the HTTP client the Client makes
+// requests with, if the Client owns it: the client set with WithHTTPClient or
+// the default HTTP client of a Vault client created by the Client. A client set
+// with WithLogicalClient is owned by the caller and left unchanged. Close is
+// safe to call on a nil Client and more than once, and the Client remains
+// usable afterwards, opening new connections as needed.
+//
+// Close does not create the Vault client, so it does nothing before the first
+// request.
+func (c *Client) Close() error {
+	if c == nil {
+		return nil
+	}
+	c.core.Close()
+	return nil
+}
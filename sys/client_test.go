@@ -0,0 +1,99 @@
+package sys_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mwalto7/vault/sys"
+	"github.com/mwalto7/vault/vaultmock"
+)
+
+func TestClient_EnableMount(t *testing.T) {
+	tt := []struct {
+		name   string
+		path   string
+		opts   sys.MountOptions
+		expect func(m *vaultmock.LogicalClient)
+		err    error
+	}{
+		{
+			name: "Defaults",
+			path: "kv",
+			expect: func(m *vaultmock.LogicalClient) {
+				m.EXPECT().Write("sys/mounts/kv", map[string]interface{}{"type": "kv"}).Return(nil, nil)
+			},
+		},
+		{
+			name: "KVv2",
+			path: "/my-kv/",
+			opts: sys.MountOptions{
+				Description:     "app secrets",
+				Version:         2,
+				MaxVersions:     5,
+				DefaultLeaseTTL: time.Hour,
+				MaxLeaseTTL:     24 * time.Hour,
+			},
+			expect: func(m *vaultmock.LogicalClient) {
+				gomock.InOrder(
+					m.EXPECT().Write("sys/mounts/my-kv", map[string]interface{}{
+						"type":        "kv",
+						"description": "app secrets",
+						"config":      map[string]interface{}{"default_lease_ttl": "1h0m0s", "max_lease_ttl": "24h0m0s"},
+						"options":     map[string]interface{}{"version": "2"},
+					}).Return(nil, nil),
+					m.EXPECT().Write("my-kv/config", map[string]interface{}{"max_versions": 5}).Return(nil, nil),
+				)
+			},
+		},
+		{
+			name: "KVv1IgnoresMaxVersions",
+			path: "old",
+			opts: sys.MountOptions{Version: 1, MaxVersions: 5},
+			expect: func(m *vaultmock.LogicalClient) {
+				m.EXPECT().Write("sys/mounts/old", map[string]interface{}{
+					"type":    "kv",
+					"options": map[string]interface{}{"version": "1"},
+				}).Return(nil, nil)
+			},
+		},
+		{
+			name:   "ErrEmptyPath",
+			path:   "/",
+			expect: func(m *vaultmock.LogicalClient) {},
+			err:    sys.ErrEmptyPath,
+		},
+		{
+			name: "ErrAlreadyMounted",
+			path: "kv",
+			expect: func(m *vaultmock.LogicalClient) {
+				m.EXPECT().Write("sys/mounts/kv", gomock.Any()).Return(nil, errAlreadyMounted)
+			},
+			err: errAlreadyMounted,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			tc.expect(m)
+
+			err := sys.NewClient(sys.WithLogicalClient(m)).EnableMount(tc.path, tc.opts)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("err: got %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
+
+var errAlreadyMounted = errors.New("path is already in use")
+
+func TestClient_TuneMount(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().Write("sys/mounts/kv/tune", map[string]interface{}{"max_lease_ttl": "48h0m0s"}).Return(nil, nil)
+
+	if err := sys.NewClient(sys.WithLogicalClient(m)).TuneMount("kv", sys.TuneOptions{MaxLeaseTTL: 48 * time.Hour}); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+}
@@ -0,0 +1,116 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// TimeoutClient returns a LogicalClient that bounds each request made with
+// client by the timeout d. Requests that do not complete in time return an
+// error wrapping context.DeadlineExceeded. If d is not positive, client is
+// returned unchanged.
+//
+// Requests made with a context are bounded by the earlier of the context
+// deadline and the timeout.
+func TimeoutClient(client LogicalClient, d time.Duration) LogicalClient {
+	if d <= 0 {
+		return client
+	}
+	return &timeoutClient{client: client, timeout: d}
+}
+
+type timeoutClient struct {
+	client  LogicalClient
+	timeout time.Duration
+}
+
+func (c *timeoutClient) Read(path string) (*api.Secret, error) {
+	return c.ReadWithContext(context.Background(), path)
+}
+
+func (c *timeoutClient) ReadWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.do(ctx, func(ctx context.Context) (*api.Secret, error) {
+		return c.client.ReadWithContext(ctx, path)
+	})
+}
+
+func (c *timeoutClient) ReadWithData(path string, data map[string][]string) (*api.Secret, error) {
+	return c.ReadWithDataWithContext(context.Background(), path, data)
+}
+
+func (c *timeoutClient) ReadWithDataWithContext(ctx context.Context, path string, data map[string][]string) (*api.Secret, error) {
+	return c.do(ctx, func(ctx context.Context) (*api.Secret, error) {
+		return c.client.ReadWithDataWithContext(ctx, path, data)
+	})
+}
+
+func (c *timeoutClient) List(path string) (*api.Secret, error) {
+	return c.ListWithContext(context.Background(), path)
+}
+
+func (c *timeoutClient) ListWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.do(ctx, func(ctx context.Context) (*api.Secret, error) {
+		return c.client.ListWithContext(ctx, path)
+	})
+}
+
+func (c *timeoutClient) Write(path string, data map[string]interface{}) (*api.Secret, error) {
+	return c.WriteWithContext(context.Background(), path, data)
+}
+
+func (c *timeoutClient) WriteWithContext(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error) {
+	return c.do(ctx, func(ctx context.Context) (*api.Secret, error) {
+		return c.client.WriteWithContext(ctx, path, data)
+	})
+}
+
+func (c *timeoutClient) JSONMergePatch(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error) {
+	return c.do(ctx, func(ctx context.Context) (*api.Secret, error) {
+		return c.client.JSONMergePatch(ctx, path, data)
+	})
+}
+
+func (c *timeoutClient) Delete(path string) (*api.Secret, error) {
+	return c.DeleteWithContext(context.Background(), path)
+}
+
+func (c *timeoutClient) DeleteWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.do(ctx, func(ctx context.Context) (*api.Secret, error) {
+		return c.client.DeleteWithContext(ctx, path)
+	})
+}
+
+func (c *timeoutClient) DeleteWithData(path string, data map[string][]string) (*api.Secret, error) {
+	return c.DeleteWithDataWithContext(context.Background(), path, data)
+}
+
+func (c *timeoutClient) DeleteWithDataWithContext(ctx context.Context, path string, data map[string][]string) (*api.Secret, error) {
+	return c.do(ctx, func(ctx context.Context) (*api.Secret, error) {
+		return c.client.DeleteWithDataWithContext(ctx, path, data)
+	})
+}
+
+func (c *timeoutClient) Unwrap(wrappingToken string) (*api.Secret, error) {
+	return c.UnwrapWithContext(context.Background(), wrappingToken)
+}
+
+func (c *timeoutClient) UnwrapWithContext(ctx context.Context, wrappingToken string) (*api.Secret, error) {
+	return c.do(ctx, func(ctx context.Context) (*api.Secret, error) {
+		return c.client.UnwrapWithContext(ctx, wrappingToken)
+	})
+}
+
+// do runs fn with the timeout. The timeout is reported in the error only if it
+// fired, not if the context of the caller was done first.
+func (c *timeoutClient) do(parent context.Context, fn func(context.Context) (*api.Secret, error)) (*api.Secret, error) {
+	ctx, cancel := context.WithTimeout(parent, c.timeout)
+	defer cancel()
+	secret, err := fn(ctx)
+	if err != nil && ctx.Err() == context.DeadlineExceeded && parent.Err() == nil {
+		return nil, fmt.Errorf("vault: request timed out after %s: %w", c.timeout, ctx.Err())
+	}
+	return secret, err
+}
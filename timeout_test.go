@@ -0,0 +1,47 @@
+package vault_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/vault/api"
+	"github.com/mwalto7/vault"
+	"github.com/mwalto7/vault/vaultmock"
+)
+
+func TestTimeoutClient(t *testing.T) {
+	tt := []struct {
+		name     string
+		timeout  time.Duration
+		deadline time.Duration
+		timedOut bool
+	}{
+		{name: "Timeout", timeout: 10 * time.Millisecond, deadline: time.Hour, timedOut: true},
+		{name: "ContextDeadline", timeout: time.Hour, deadline: 10 * time.Millisecond},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := vaultmock.NewLogicalClient(gomock.NewController(t))
+			m.EXPECT().ReadWithContext(gomock.Any(), "secret/test").DoAndReturn(
+				func(ctx context.Context, path string) (*api.Secret, error) {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				})
+
+			ctx, cancel := context.WithTimeout(context.Background(), tc.deadline)
+			defer cancel()
+			_, err := vault.TimeoutClient(m, tc.timeout).ReadWithContext(ctx, "secret/test")
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("err: got %v, want %v", err, context.DeadlineExceeded)
+			}
+			if got := strings.Contains(err.Error(), "timed out after"); got != tc.timedOut {
+				t.Fatalf("err: got %q, want timeout reported: %t", err, tc.timedOut)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// TokenRenewer obtains a new Vault token for an API client when its token is
+// rejected, such as by logging in again with the AppRole or Kubernetes auth
+// method, so long-running processes survive the expiry of their token. A
+// TokenRenewer is safe for concurrent use: requests rejected with the same
+// token wait for a single login. It does not log in again within
+// minRenewInterval of a successful login, since a token that was just issued
+// is not expired, so requests denied for lack of a capability do not each
+// cause a login.
+type TokenRenewer struct {
+	client *api.Client
+	login  func() (string, error)
+
+	mu   sync.Mutex
+	gen  uint64
+	last time.Time // time of the last successful login
+}
+
+// minRenewInterval is the minimum time between two logins of a TokenRenewer.
+const minRenewInterval = 5 * time.Second
+
+var errRenewedRecently = errors.New("vault: token renewed recently")
+
+// NewTokenRenewer returns a TokenRenewer that sets the token returned by login
+// on client.
+func NewTokenRenewer(client *api.Client, login func() (string, error)) *TokenRenewer {
+	return &TokenRenewer{client: client, login: login}
+}
+
+// generation returns the number of tokens set so far.
+func (r *TokenRenewer) generation() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.gen
+}
+
+// renew logs in and sets the new token, unless a new token was already set
+// since the generation gen. It returns errRenewedRecently without logging in
+// if the last login was less than minRenewInterval ago.
+func (r *TokenRenewer) renew(gen uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.gen != gen {
+		return nil
+	}
+	if !r.last.IsZero() && time.Since(r.last) < minRenewInterval {
+		return errRenewedRecently
+	}
+	token, err := r.login()
+	if err != nil {
+		return err
+	}
+	r.client.SetToken(token)
+	r.gen++
+	r.last = time.Now()
+	return nil
+}
+
+// TokenRenewingClient returns a LogicalClient that retries the requests made
+// with client once after renewing the token with r, if they fail with a
+// permission denied error, as reported by IsPermissionDenied. Vault reports
+// an expired or invalid token like a token that lacks a capability, so a
+// request that is denied again with the new token fails with the permission
+// denied error, without another login. Requests denied within 5 seconds of a
+// login are not retried and fail without a login either, so a token that
+// lacks a capability causes at most one login every 5 seconds. If the login
+// fails, the original error is returned. If r is nil, client is returned
+// unchanged.
+func TokenRenewingClient(client LogicalClient, r *TokenRenewer) LogicalClient {
+	if r == nil {
+		return client
+	}
+	return &tokenRenewingClient{client: client, renewer: r}
+}
+
+type tokenRenewingClient struct {
+	client  LogicalClient
+	renewer *TokenRenewer
+}
+
+func (c *tokenRenewingClient) Read(path string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.Read(path)
+	})
+}
+
+func (c *tokenRenewingClient) ReadWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.ReadWithContext(ctx, path)
+	})
+}
+
+func (c *tokenRenewingClient) ReadWithData(path string, data map[string][]string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.ReadWithData(path, data)
+	})
+}
+
+func (c *tokenRenewingClient) ReadWithDataWithContext(ctx context.Context, path string, data map[string][]string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.ReadWithDataWithContext(ctx, path, data)
+	})
+}
+
+func (c *tokenRenewingClient) List(path string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.List(path)
+	})
+}
+
+func (c *tokenRenewingClient) ListWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.ListWithContext(ctx, path)
+	})
+}
+
+func (c *tokenRenewingClient) Write(path string, data map[string]interface{}) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.Write(path, data)
+	})
+}
+
+func (c *tokenRenewingClient) WriteWithContext(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.WriteWithContext(ctx, path, data)
+	})
+}
+
+func (c *tokenRenewingClient) JSONMergePatch(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.JSONMergePatch(ctx, path, data)
+	})
+}
+
+func (c *tokenRenewingClient) Delete(path string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.Delete(path)
+	})
+}
+
+func (c *tokenRenewingClient) DeleteWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.DeleteWithContext(ctx, path)
+	})
+}
+
+func (c *tokenRenewingClient) DeleteWithData(path string, data map[string][]string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.DeleteWithData(path, data)
+	})
+}
+
+func (c *tokenRenewingClient) DeleteWithDataWithContext(ctx context.Context, path string, data map[string][]string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.DeleteWithDataWithContext(ctx, path, data)
+	})
+}
+
+func (c *tokenRenewingClient) Unwrap(wrappingToken string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.Unwrap(wrappingToken)
+	})
+}
+
+func (c *tokenRenewingClient) UnwrapWithContext(ctx context.Context, wrappingToken string) (*api.Secret, error) {
+	return c.do(func() (*api.Secret, error) {
+		return c.client.UnwrapWithContext(ctx, wrappingToken)
+	})
+}
+
+// do runs fn, running it again once with a new token if it is denied.
+func (c *tokenRenewingClient) do(fn func() (*api.Secret, error)) (*api.Secret, error) {
+	gen := c.renewer.generation()
+	secret, err := fn()
+	if !IsPermissionDenied(err) {
+		return secret, err
+	}
+	if c.renewer.renew(gen) != nil {
+		return secret, err
+	}
+	return fn()
+}
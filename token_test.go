@@ -0,0 +1,119 @@
+package vault_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/vault/api"
+	"github.com/mwalto7/vault"
+	"github.com/mwalto7/vault/vaultmock"
+)
+
+func TestTokenRenewingClient(t *testing.T) {
+	denied := &api.ResponseError{StatusCode: http.StatusForbidden, Errors: []string{"permission denied"}}
+	want := &api.Secret{Data: map[string]interface{}{"foo": "bar"}}
+
+	newAPIClient := func(t *testing.T) *api.Client {
+		client, err := api.NewClient(api.DefaultConfig())
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.SetToken("old")
+		return client
+	}
+
+	t.Run("RetriesWithNewToken", func(t *testing.T) {
+		apiClient := newAPIClient(t)
+		m := vaultmock.NewLogicalClient(gomock.NewController(t))
+		gomock.InOrder(
+			m.EXPECT().Read("secret/test").Return(nil, denied),
+			m.EXPECT().Read("secret/test").DoAndReturn(func(string) (*api.Secret, error) {
+				if got := apiClient.Token(); got != "new" {
+					t.Errorf("retry token: got %q, want %q", got, "new")
+				}
+				return want, nil
+			}),
+		)
+		logins := 0
+		r := vault.NewTokenRenewer(apiClient, func() (string, error) {
+			logins++
+			return "new", nil
+		})
+
+		secret, err := vault.TokenRenewingClient(m, r).Read("secret/test")
+		if err != nil || secret != want {
+			t.Fatalf("got %v, %v, want %v, nil", secret, err, want)
+		}
+		if logins != 1 {
+			t.Fatalf("logins: got %d, want 1", logins)
+		}
+	})
+
+	t.Run("GenuinePermissionError", func(t *testing.T) {
+		m := vaultmock.NewLogicalClient(gomock.NewController(t))
+		m.EXPECT().Read("secret/test").Return(nil, denied).Times(2)
+		logins := 0
+		r := vault.NewTokenRenewer(newAPIClient(t), func() (string, error) {
+			logins++
+			return "new", nil
+		})
+
+		if _, err := vault.TokenRenewingClient(m, r).Read("secret/test"); !vault.IsPermissionDenied(err) {
+			t.Fatalf("err: got %v, want permission denied", err)
+		}
+		if logins != 1 {
+			t.Fatalf("logins: got %d, want 1", logins)
+		}
+	})
+
+	t.Run("RepeatedPermissionErrors", func(t *testing.T) {
+		m := vaultmock.NewLogicalClient(gomock.NewController(t))
+		m.EXPECT().Read("secret/test").Return(nil, denied).Times(3)
+		logins := 0
+		r := vault.NewTokenRenewer(newAPIClient(t), func() (string, error) {
+			logins++
+			return "new", nil
+		})
+
+		client := vault.TokenRenewingClient(m, r)
+		for i := 0; i < 2; i++ {
+			if _, err := client.Read("secret/test"); !vault.IsPermissionDenied(err) {
+				t.Fatalf("%d: err: got %v, want permission denied", i, err)
+			}
+		}
+		if logins != 1 {
+			t.Fatalf("logins: got %d, want 1", logins)
+		}
+	})
+
+	t.Run("LoginFails", func(t *testing.T) {
+		apiClient := newAPIClient(t)
+		m := vaultmock.NewLogicalClient(gomock.NewController(t))
+		m.EXPECT().Read("secret/test").Return(nil, denied)
+		r := vault.NewTokenRenewer(apiClient, func() (string, error) {
+			return "", errors.New("login failed")
+		})
+
+		if _, err := vault.TokenRenewingClient(m, r).Read("secret/test"); !errors.Is(err, denied) {
+			t.Fatalf("err: got %v, want %v", err, denied)
+		}
+		if got := apiClient.Token(); got != "old" {
+			t.Fatalf("token: got %q, want %q", got, "old")
+		}
+	})
+
+	t.Run("OtherErrors", func(t *testing.T) {
+		m := vaultmock.NewLogicalClient(gomock.NewController(t))
+		m.EXPECT().Read("secret/test").Return(nil, &api.ResponseError{StatusCode: http.StatusNotFound})
+		r := vault.NewTokenRenewer(newAPIClient(t), func() (string, error) {
+			t.Error("login called for a not found error")
+			return "", nil
+		})
+
+		if _, err := vault.TokenRenewingClient(m, r).Read("secret/test"); !vault.IsNotFound(err) {
+			t.Fatalf("err: got %v, want not found", err)
+		}
+	})
+}
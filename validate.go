@@ -0,0 +1,23 @@
+package vault
+
+import "fmt"
+
+// WriteValidator validates the data of a secret before it is written to the
+// specified path, which is relative to the mount. A non-nil error aborts the
+// write.
+type WriteValidator func(path string, data map[string]interface{}) error
+
+// RequireKeys returns a WriteValidator that rejects secret data missing any
+// of the top-level keys or setting one to nil:
+//
+//    c := kv.NewClient("/secret", kv.WithWriteValidator(vault.RequireKeys("username", "password")))
+func RequireKeys(keys ...string) WriteValidator {
+	return func(path string, data map[string]interface{}) error {
+		for _, k := range keys {
+			if data[k] == nil {
+				return fmt.Errorf("vault: secret %s is missing the required key %q", path, k)
+			}
+		}
+		return nil
+	}
+}
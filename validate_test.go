@@ -0,0 +1,29 @@
+package vault_test
+
+import (
+	"testing"
+
+	"github.com/mwalto7/vault"
+)
+
+func TestRequireKeys(t *testing.T) {
+	tt := []struct {
+		name    string
+		data    map[string]interface{}
+		wantErr bool
+	}{
+		{name: "AllKeys", data: map[string]interface{}{"username": "admin", "password": "", "port": 5432}},
+		{name: "MissingKey", data: map[string]interface{}{"username": "admin"}, wantErr: true},
+		{name: "NilValue", data: map[string]interface{}{"username": "admin", "password": nil}, wantErr: true},
+		{name: "NilData", wantErr: true},
+	}
+
+	validate := vault.RequireKeys("username", "password")
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := validate("app/db", tc.data); (err != nil) != tc.wantErr {
+				t.Fatalf("err: got %v, want error %t", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@
 package vaultmock
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	api "github.com/hashicorp/vault/api"
 	reflect "reflect"
@@ -48,6 +49,21 @@ func (mr *LogicalClientMockRecorder) Delete(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*LogicalClient)(nil).Delete), arg0)
 }
 
+// DeleteWithContext mocks base method
+func (m *LogicalClient) DeleteWithContext(arg0 context.Context, arg1 string) (*api.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWithContext", arg0, arg1)
+	ret0, _ := ret[0].(*api.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWithContext indicates an expected call of DeleteWithContext
+func (mr *LogicalClientMockRecorder) DeleteWithContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWithContext", reflect.TypeOf((*LogicalClient)(nil).DeleteWithContext), arg0, arg1)
+}
+
 // DeleteWithData mocks base method
 func (m *LogicalClient) DeleteWithData(arg0 string, arg1 map[string][]string) (*api.Secret, error) {
 	m.ctrl.T.Helper()
@@ -63,6 +79,36 @@ func (mr *LogicalClientMockRecorder) DeleteWithData(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWithData", reflect.TypeOf((*LogicalClient)(nil).DeleteWithData), arg0, arg1)
 }
 
+// DeleteWithDataWithContext mocks base method
+func (m *LogicalClient) DeleteWithDataWithContext(arg0 context.Context, arg1 string, arg2 map[string][]string) (*api.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWithDataWithContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWithDataWithContext indicates an expected call of DeleteWithDataWithContext
+func (mr *LogicalClientMockRecorder) DeleteWithDataWithContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWithDataWithContext", reflect.TypeOf((*LogicalClient)(nil).DeleteWithDataWithContext), arg0, arg1, arg2)
+}
+
+// JSONMergePatch mocks base method
+func (m *LogicalClient) JSONMergePatch(arg0 context.Context, arg1 string, arg2 map[string]interface{}) (*api.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JSONMergePatch", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// JSONMergePatch indicates an expected call of JSONMergePatch
+func (mr *LogicalClientMockRecorder) JSONMergePatch(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JSONMergePatch", reflect.TypeOf((*LogicalClient)(nil).JSONMergePatch), arg0, arg1, arg2)
+}
+
 // List mocks base method
 func (m *LogicalClient) List(arg0 string) (*api.Secret, error) {
 	m.ctrl.T.Helper()
@@ -78,6 +124,21 @@ func (mr *LogicalClientMockRecorder) List(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*LogicalClient)(nil).List), arg0)
 }
 
+// ListWithContext mocks base method
+func (m *LogicalClient) ListWithContext(arg0 context.Context, arg1 string) (*api.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWithContext", arg0, arg1)
+	ret0, _ := ret[0].(*api.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWithContext indicates an expected call of ListWithContext
+func (mr *LogicalClientMockRecorder) ListWithContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWithContext", reflect.TypeOf((*LogicalClient)(nil).ListWithContext), arg0, arg1)
+}
+
 // Read mocks base method
 func (m *LogicalClient) Read(arg0 string) (*api.Secret, error) {
 	m.ctrl.T.Helper()
@@ -93,6 +154,21 @@ func (mr *LogicalClientMockRecorder) Read(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*LogicalClient)(nil).Read), arg0)
 }
 
+// ReadWithContext mocks base method
+func (m *LogicalClient) ReadWithContext(arg0 context.Context, arg1 string) (*api.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadWithContext", arg0, arg1)
+	ret0, _ := ret[0].(*api.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadWithContext indicates an expected call of ReadWithContext
+func (mr *LogicalClientMockRecorder) ReadWithContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadWithContext", reflect.TypeOf((*LogicalClient)(nil).ReadWithContext), arg0, arg1)
+}
+
 // ReadWithData mocks base method
 func (m *LogicalClient) ReadWithData(arg0 string, arg1 map[string][]string) (*api.Secret, error) {
 	m.ctrl.T.Helper()
@@ -108,6 +184,21 @@ func (mr *LogicalClientMockRecorder) ReadWithData(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadWithData", reflect.TypeOf((*LogicalClient)(nil).ReadWithData), arg0, arg1)
 }
 
+// ReadWithDataWithContext mocks base method
+func (m *LogicalClient) ReadWithDataWithContext(arg0 context.Context, arg1 string, arg2 map[string][]string) (*api.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadWithDataWithContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadWithDataWithContext indicates an expected call of ReadWithDataWithContext
+func (mr *LogicalClientMockRecorder) ReadWithDataWithContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadWithDataWithContext", reflect.TypeOf((*LogicalClient)(nil).ReadWithDataWithContext), arg0, arg1, arg2)
+}
+
 // Unwrap mocks base method
 func (m *LogicalClient) Unwrap(arg0 string) (*api.Secret, error) {
 	m.ctrl.T.Helper()
@@ -123,6 +214,21 @@ func (mr *LogicalClientMockRecorder) Unwrap(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unwrap", reflect.TypeOf((*LogicalClient)(nil).Unwrap), arg0)
 }
 
+// UnwrapWithContext mocks base method
+func (m *LogicalClient) UnwrapWithContext(arg0 context.Context, arg1 string) (*api.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnwrapWithContext", arg0, arg1)
+	ret0, _ := ret[0].(*api.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnwrapWithContext indicates an expected call of UnwrapWithContext
+func (mr *LogicalClientMockRecorder) UnwrapWithContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnwrapWithContext", reflect.TypeOf((*LogicalClient)(nil).UnwrapWithContext), arg0, arg1)
+}
+
 // Write mocks base method
 func (m *LogicalClient) Write(arg0 string, arg1 map[string]interface{}) (*api.Secret, error) {
 	m.ctrl.T.Helper()
@@ -137,3 +243,18 @@ func (mr *LogicalClientMockRecorder) Write(arg0, arg1 interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*LogicalClient)(nil).Write), arg0, arg1)
 }
+
+// WriteWithContext mocks base method
+func (m *LogicalClient) WriteWithContext(arg0 context.Context, arg1 string, arg2 map[string]interface{}) (*api.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteWithContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteWithContext indicates an expected call of WriteWithContext
+func (mr *LogicalClientMockRecorder) WriteWithContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteWithContext", reflect.TypeOf((*LogicalClient)(nil).WriteWithContext), arg0, arg1, arg2)
+}
@@ -0,0 +1,199 @@
+// Package vaultotel provides OpenTelemetry tracing for the requests made by the
+// secrets engine clients.
+//
+// Tracing is opt-in: the KV clients trace their requests with the
+// WithTracerProvider option of their package, which keeps their other options,
+// such as WithNamespace, in effect:
+//
+//    c := kv.NewClient("/secret", kv.WithTracerProvider(tp))
+//
+// Other Vault clients, such as the one of a cubbyhole client, can be wrapped
+// with NewClient to create a span for each request:
+//
+//    logical := vaultotel.NewClient(client.Logical(), vaultotel.WithTracerProvider(tp))
+//    c := cubbyhole.NewClient("", logical)
+//
+// Each span is named after the logical operation of the request, such as
+// "vault.Read", and carries the request path, the mount path and, for
+// versioned KVv2 reads, the secret version as attributes. The KV clients
+// record their mount path; set it with WithMount for other clients. The secret
+// data is never recorded. Spans are created per request rather than per
+// secrets engine client method, so a method that makes several requests, such
+// as the KVv2 UpdateSecret, creates a span for each of them.
+//
+// Only requests made with a context can join the trace of their caller. The KV
+// clients make their requests without one, so their spans start new traces;
+// use the *WithContext methods of the cubbyhole client to trace its requests
+// as part of the caller's trace.
+package vaultotel
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/mwalto7/vault"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/mwalto7/vault/vaultotel"
+
+// Attribute keys of the spans created by the Client.
+const (
+	OperationKey = attribute.Key("vault.operation")
+	MountKey     = attribute.Key("vault.mount")
+	PathKey      = attribute.Key("vault.path")
+	VersionKey   = attribute.Key("vault.version")
+)
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithTracerProvider sets the TracerProvider used to create spans. Defaults to
+// the global TracerProvider, which is also used if tp is nil.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *Client) {
+		if tp == nil {
+			c.tracer = nil
+			return
+		}
+		c.tracer = tp.Tracer(instrumentationName)
+	}
+}
+
+// WithMount sets the mount path of the secrets engine the requests are made
+// to, which is recorded as the MountKey attribute of each span. By default, no
+// mount path is recorded, since it cannot be told from the request path.
+func WithMount(mount string) Option {
+	return func(c *Client) {
+		c.mount = strings.Trim(mount, "/")
+	}
+}
+
+// Client is a vault.LogicalClient that creates a span for each request made
+// with the wrapped client. Requests made with a context start their span as a
+// child of the span in the context, and pass the new span to the wrapped
+// client in the request context.
+type Client struct {
+	client vault.LogicalClient
+	tracer trace.Tracer
+	mount  string
+}
+
+var _ vault.LogicalClient = (*Client)(nil)
+
+// NewClient creates a Client that traces the requests made with client,
+// configured with the given options.
+func NewClient(client vault.LogicalClient, opts ...Option) *Client {
+	c := &Client{client: client}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.tracer == nil {
+		c.tracer = otel.GetTracerProvider().Tracer(instrumentationName)
+	}
+	return c
+}
+
+func (c *Client) Read(path string) (*api.Secret, error) {
+	return c.ReadWithContext(context.Background(), path)
+}
+
+func (c *Client) ReadWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.do(ctx, "Read", path, nil, func(ctx context.Context) (*api.Secret, error) {
+		return c.client.ReadWithContext(ctx, path)
+	})
+}
+
+func (c *Client) ReadWithData(path string, data map[string][]string) (*api.Secret, error) {
+	return c.ReadWithDataWithContext(context.Background(), path, data)
+}
+
+func (c *Client) ReadWithDataWithContext(ctx context.Context, path string, data map[string][]string) (*api.Secret, error) {
+	return c.do(ctx, "Read", path, data, func(ctx context.Context) (*api.Secret, error) {
+		return c.client.ReadWithDataWithContext(ctx, path, data)
+	})
+}
+
+func (c *Client) List(path string) (*api.Secret, error) {
+	return c.ListWithContext(context.Background(), path)
+}
+
+func (c *Client) ListWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.do(ctx, "List", path, nil, func(ctx context.Context) (*api.Secret, error) {
+		return c.client.ListWithContext(ctx, path)
+	})
+}
+
+func (c *Client) Write(path string, data map[string]interface{}) (*api.Secret, error) {
+	return c.WriteWithContext(context.Background(), path, data)
+}
+
+func (c *Client) WriteWithContext(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error) {
+	return c.do(ctx, "Write", path, nil, func(ctx context.Context) (*api.Secret, error) {
+		return c.client.WriteWithContext(ctx, path, data)
+	})
+}
+
+func (c *Client) JSONMergePatch(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error) {
+	return c.do(ctx, "JSONMergePatch", path, nil, func(ctx context.Context) (*api.Secret, error) {
+		return c.client.JSONMergePatch(ctx, path, data)
+	})
+}
+
+func (c *Client) Delete(path string) (*api.Secret, error) {
+	return c.DeleteWithContext(context.Background(), path)
+}
+
+func (c *Client) DeleteWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	return c.do(ctx, "Delete", path, nil, func(ctx context.Context) (*api.Secret, error) {
+		return c.client.DeleteWithContext(ctx, path)
+	})
+}
+
+func (c *Client) DeleteWithData(path string, data map[string][]string) (*api.Secret, error) {
+	return c.DeleteWithDataWithContext(context.Background(), path, data)
+}
+
+func (c *Client) DeleteWithDataWithContext(ctx context.Context, path string, data map[string][]string) (*api.Secret, error) {
+	return c.do(ctx, "Delete", path, nil, func(ctx context.Context) (*api.Secret, error) {
+		return c.client.DeleteWithDataWithContext(ctx, path, data)
+	})
+}
+
+func (c *Client) Unwrap(wrappingToken string) (*api.Secret, error) {
+	return c.UnwrapWithContext(context.Background(), wrappingToken)
+}
+
+func (c *Client) UnwrapWithContext(ctx context.Context, wrappingToken string) (*api.Secret, error) {
+	return c.do(ctx, "Unwrap", "sys/wrapping/unwrap", nil, func(ctx context.Context) (*api.Secret, error) {
+		return c.client.UnwrapWithContext(ctx, wrappingToken)
+	})
+}
+
+// do runs fn in a new span for the operation. Only the version of the request
+// data is recorded, never its values.
+func (c *Client) do(ctx context.Context, op, path string, data map[string][]string, fn func(context.Context) (*api.Secret, error)) (*api.Secret, error) {
+	attrs := []attribute.KeyValue{
+		OperationKey.String(op),
+		PathKey.String(path),
+	}
+	if c.mount != "" {
+		attrs = append(attrs, MountKey.String(c.mount))
+	}
+	if v := data["version"]; len(v) > 0 {
+		attrs = append(attrs, VersionKey.String(v[0]))
+	}
+	ctx, span := c.tracer.Start(ctx, "vault."+op, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	defer span.End()
+
+	secret, err := fn(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return secret, err
+}
@@ -0,0 +1,65 @@
+package vaultotel_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/vault/api"
+	"github.com/mwalto7/vault/vaultmock"
+	"github.com/mwalto7/vault/vaultotel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestClient(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	params := map[string][]string{"version": {"2"}}
+	m.EXPECT().ReadWithDataWithContext(gomock.Any(), "secret/data/test", params).Return(&api.Secret{
+		Data: map[string]interface{}{"data": map[string]interface{}{"password": "hunter2"}},
+	}, nil)
+
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	c := vaultotel.NewClient(m, vaultotel.WithTracerProvider(tp), vaultotel.WithMount("/secret/"))
+	if _, err := c.ReadWithData("secret/data/test", params); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("spans: got %d, want 1", len(spans))
+	}
+	if name := spans[0].Name(); name != "vault.Read" {
+		t.Fatalf("span name: got %q, want %q", name, "vault.Read")
+	}
+	want := map[attribute.Key]string{
+		vaultotel.OperationKey: "Read",
+		vaultotel.MountKey:     "secret",
+		vaultotel.PathKey:      "secret/data/test",
+		vaultotel.VersionKey:   "2",
+	}
+	got := make(map[attribute.Key]string)
+	for _, kv := range spans[0].Attributes() {
+		got[kv.Key] = kv.Value.Emit()
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("attribute %s: got %q, want %q", k, got[k], v)
+		}
+	}
+	for k, v := range got {
+		if v == "hunter2" {
+			t.Errorf("attribute %s: secret data recorded", k)
+		}
+	}
+}
+
+func TestWithTracerProvider_Nil(t *testing.T) {
+	m := vaultmock.NewLogicalClient(gomock.NewController(t))
+	m.EXPECT().ReadWithContext(gomock.Any(), "secret/data/test").Return(nil, nil)
+
+	if _, err := vaultotel.NewClient(m, vaultotel.WithTracerProvider(nil)).Read("secret/data/test"); err != nil {
+		t.Fatalf("err: got %v, want nil", err)
+	}
+}
@@ -0,0 +1,817 @@
+// Package vaulttest provides test helpers for code that uses the secrets
+// engine clients, without a running Vault server.
+//
+// InMemoryLogical is a vault.LogicalClient that stores secrets in memory and
+// implements the request semantics of the KV and cubbyhole secrets engines,
+// so higher-level code can be tested against realistic behavior:
+//
+//    logical := vaulttest.NewInMemoryLogical()
+//    c := kv.NewClient("/secret", kv.WithLogicalClient(logical))
+//
+// For tests that expect exact requests, use the vaultmock package instead.
+package vaulttest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/mwalto7/vault"
+)
+
+// defaultMaxVersions is the number of versions kept per secret by a KVv2
+// secrets engine when no maximum is configured.
+const defaultMaxVersions = 10
+
+// Option configures an InMemoryLogical.
+type Option func(*InMemoryLogical)
+
+// WithKVv1Mount mounts a KVv1 secrets engine at the specified path.
+func WithKVv1Mount(path string) Option {
+	return func(l *InMemoryLogical) {
+		l.mount(path, engineKVv1)
+	}
+}
+
+// WithKVv2Mount mounts a KVv2 secrets engine at the specified path.
+func WithKVv2Mount(path string) Option {
+	return func(l *InMemoryLogical) {
+		l.mount(path, engineKVv2)
+	}
+}
+
+// WithClock sets the function returning the current time, which is used for
+// the creation and deletion times of KVv2 secret versions. Defaults to
+// time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(l *InMemoryLogical) {
+		l.now = now
+	}
+}
+
+// InMemoryLogical is a vault.LogicalClient backed by an in-memory tree of
+// secrets engines. It is safe for concurrent use.
+//
+// KVv2 mounts support the data, metadata, delete, undelete, destroy, subkeys
+// and config endpoints, including check-and-set writes, JSON merge patches,
+// the maximum number of versions and the deletion of versions after a
+// duration. KVv1 and cubbyhole mounts store plain key-value secrets. The
+// "sys/internal/ui/mounts" endpoint reports the mounted engines.
+//
+// Requests to paths outside of the mounts, and unsupported requests, fail
+// with an *api.ResponseError like the one returned by Vault. Response
+// wrapping is not supported.
+type InMemoryLogical struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	mounts map[string]*mount
+}
+
+var _ vault.LogicalClient = (*InMemoryLogical)(nil)
+
+// NewInMemoryLogical creates an InMemoryLogical configured with the given
+// options. Like a Vault dev server, it has a KVv2 secrets engine mounted at
+// "secret" and a cubbyhole secrets engine mounted at "cubbyhole".
+func NewInMemoryLogical(opts ...Option) *InMemoryLogical {
+	l := &InMemoryLogical{
+		now:    time.Now,
+		mounts: make(map[string]*mount),
+	}
+	l.mount("cubbyhole", engineCubbyhole)
+	l.mount("secret", engineKVv2)
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+type engine int
+
+const (
+	engineCubbyhole engine = iota
+	engineKVv1
+	engineKVv2
+)
+
+type mount struct {
+	engine engine
+
+	// The secrets of a cubbyhole or KVv1 mount.
+	kv map[string]map[string]interface{}
+
+	// The secrets and configuration of a KVv2 mount.
+	secrets map[string]*secret
+	config  secretConfig
+}
+
+type secretConfig struct {
+	maxVersions        int
+	casRequired        bool
+	deleteVersionAfter time.Duration
+}
+
+type secret struct {
+	config         secretConfig
+	customMetadata map[string]interface{}
+	createdTime    time.Time
+	updatedTime    time.Time
+	currentVersion int
+	oldestVersion  int
+	versions       map[int]*version
+}
+
+type version struct {
+	data         map[string]interface{}
+	createdTime  time.Time
+	deletionTime time.Time
+	destroyed    bool
+}
+
+// live reports whether the data of the version can be read at now.
+func (v *version) live(now time.Time) bool {
+	return !v.destroyed && (v.deletionTime.IsZero() || v.deletionTime.After(now))
+}
+
+func (l *InMemoryLogical) mount(path string, e engine) {
+	l.mounts[strings.Trim(path, "/")] = &mount{
+		engine:  e,
+		kv:      make(map[string]map[string]interface{}),
+		secrets: make(map[string]*secret),
+	}
+}
+
+// request is a request made to a mount.
+type request struct {
+	method string
+	path   string // The request path, relative to the mount.
+	params map[string][]string
+	data   map[string]interface{}
+}
+
+func (l *InMemoryLogical) Read(path string) (*api.Secret, error) {
+	return l.do(http.MethodGet, path, nil, nil)
+}
+
+func (l *InMemoryLogical) ReadWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return l.Read(path)
+}
+
+func (l *InMemoryLogical) ReadWithData(path string, data map[string][]string) (*api.Secret, error) {
+	return l.do(http.MethodGet, path, data, nil)
+}
+
+func (l *InMemoryLogical) ReadWithDataWithContext(ctx context.Context, path string, data map[string][]string) (*api.Secret, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return l.ReadWithData(path, data)
+}
+
+func (l *InMemoryLogical) List(path string) (*api.Secret, error) {
+	return l.do("LIST", path, nil, nil)
+}
+
+func (l *InMemoryLogical) ListWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return l.List(path)
+}
+
+func (l *InMemoryLogical) Write(path string, data map[string]interface{}) (*api.Secret, error) {
+	return l.do(http.MethodPut, path, nil, data)
+}
+
+func (l *InMemoryLogical) WriteWithContext(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return l.Write(path, data)
+}
+
+func (l *InMemoryLogical) JSONMergePatch(ctx context.Context, path string, data map[string]interface{}) (*api.Secret, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return l.do(http.MethodPatch, path, nil, data)
+}
+
+func (l *InMemoryLogical) Delete(path string) (*api.Secret, error) {
+	return l.do(http.MethodDelete, path, nil, nil)
+}
+
+func (l *InMemoryLogical) DeleteWithContext(ctx context.Context, path string) (*api.Secret, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return l.Delete(path)
+}
+
+func (l *InMemoryLogical) DeleteWithData(path string, data map[string][]string) (*api.Secret, error) {
+	return l.do(http.MethodDelete, path, data, nil)
+}
+
+func (l *InMemoryLogical) DeleteWithDataWithContext(ctx context.Context, path string, data map[string][]string) (*api.Secret, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return l.DeleteWithData(path, data)
+}
+
+// Unwrap always fails as if the wrapping token was invalid, since responses
+// are never wrapped.
+func (l *InMemoryLogical) Unwrap(wrappingToken string) (*api.Secret, error) {
+	return nil, responseError(http.MethodPut, "sys/wrapping/unwrap", http.StatusBadRequest, "wrapping token is not valid or does not exist")
+}
+
+func (l *InMemoryLogical) UnwrapWithContext(ctx context.Context, wrappingToken string) (*api.Secret, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return l.Unwrap(wrappingToken)
+}
+
+// do handles the request and returns the response as Vault API client would,
+// after a round trip of the request and response data through JSON.
+func (l *InMemoryLogical) do(method, path string, params map[string][]string, data map[string]interface{}) (*api.Secret, error) {
+	path = strings.Trim(path, "/")
+	var req map[string]interface{}
+	if data != nil {
+		if err := roundTrip(data, &req); err != nil {
+			return nil, responseError(method, path, http.StatusBadRequest, err.Error())
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var (
+		resp map[string]interface{}
+		err  error
+	)
+	if rest, ok := cutPrefix(path, "sys/internal/ui/mounts"); ok && method == http.MethodGet {
+		resp = l.mountInfo(rest)
+	} else if m, rest, ok := l.lookup(path); ok {
+		r := request{method: method, path: rest, params: params, data: req}
+		if m.engine == engineKVv2 {
+			resp, err = l.handleKVv2(m, r)
+		} else {
+			resp, err = handleKV(m, r)
+		}
+	} else {
+		err = fmt.Errorf("no handler for route %q", path)
+	}
+	if err != nil {
+		if respErr, ok := err.(*api.ResponseError); ok {
+			respErr.HTTPMethod = method
+			respErr.URL = "/v1/" + path
+			return nil, respErr
+		}
+		return nil, responseError(method, path, http.StatusNotFound, err.Error())
+	}
+	if resp == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(map[string]interface{}{"data": resp})
+	if err != nil {
+		return nil, err
+	}
+	return api.ParseSecret(bytes.NewReader(b))
+}
+
+// lookup returns the mount of the request path and the path relative to it.
+func (l *InMemoryLogical) lookup(path string) (*mount, string, bool) {
+	var (
+		m     *mount
+		mPath string
+	)
+	for p, mm := range l.mounts {
+		if _, ok := cutPrefix(path, p); ok && len(p) >= len(mPath) {
+			m, mPath = mm, p
+		}
+	}
+	if m == nil {
+		return nil, "", false
+	}
+	rest, _ := cutPrefix(path, mPath)
+	return m, rest, true
+}
+
+func (l *InMemoryLogical) mountInfo(path string) map[string]interface{} {
+	m, ok := l.mounts[path]
+	if !ok {
+		return nil
+	}
+	info := map[string]interface{}{"path": path + "/"}
+	switch m.engine {
+	case engineCubbyhole:
+		info["type"] = "cubbyhole"
+	case engineKVv1:
+		info["type"] = "kv"
+		info["options"] = map[string]interface{}{"version": "1"}
+	case engineKVv2:
+		info["type"] = "kv"
+		info["options"] = map[string]interface{}{"version": "2"}
+	}
+	return info
+}
+
+// handleKV handles a request to a cubbyhole or KVv1 mount.
+func handleKV(m *mount, r request) (map[string]interface{}, error) {
+	switch r.method {
+	case http.MethodGet:
+		return m.kv[r.path], nil
+	case "LIST":
+		paths := make([]string, 0, len(m.kv))
+		for p := range m.kv {
+			paths = append(paths, p)
+		}
+		return listKeys(paths, r.path), nil
+	case http.MethodPut:
+		if r.path == "" {
+			return nil, badRequest("missing path")
+		}
+		if r.data == nil {
+			r.data = make(map[string]interface{})
+		}
+		m.kv[r.path] = r.data
+		return nil, nil
+	case http.MethodDelete:
+		delete(m.kv, r.path)
+		return nil, nil
+	default:
+		return nil, unsupportedOperation()
+	}
+}
+
+// handleKVv2 handles a request to a KVv2 mount.
+func (l *InMemoryLogical) handleKVv2(m *mount, r request) (map[string]interface{}, error) {
+	if r.path == "config" {
+		return handleConfig(&m.config, r)
+	}
+	endpoint, path := r.path, ""
+	if i := strings.Index(r.path, "/"); i >= 0 {
+		endpoint, path = r.path[:i], r.path[i+1:]
+	}
+	if path == "" && !(endpoint == "metadata" && r.method == "LIST") {
+		return nil, unsupportedPath()
+	}
+	now := l.now()
+	s := m.secrets[path]
+	switch endpoint + " " + r.method {
+	case "data GET":
+		n, err := intParam(r.params, "version")
+		if err != nil {
+			return nil, err
+		}
+		return readVersion(s, n, now, func(v *version) (string, interface{}) {
+			return "data", v.data
+		}), nil
+	case "subkeys GET":
+		n, err := intParam(r.params, "version")
+		if err != nil {
+			return nil, err
+		}
+		depth, err := intParam(r.params, "depth")
+		if err != nil {
+			return nil, err
+		}
+		return readVersion(s, n, now, func(v *version) (string, interface{}) {
+			return "subkeys", subkeys(v.data, depth)
+		}), nil
+	case "data PUT":
+		data, ok := r.data["data"].(map[string]interface{})
+		if !ok {
+			return nil, badRequest("no data provided")
+		}
+		return m.writeVersion(path, data, r.data["options"], now)
+	case "data PATCH":
+		if s == nil || s.versions[s.currentVersion] == nil || !s.versions[s.currentVersion].live(now) {
+			return nil, &api.ResponseError{StatusCode: http.StatusNotFound}
+		}
+		patch, ok := r.data["data"].(map[string]interface{})
+		if !ok {
+			return nil, badRequest("no data provided")
+		}
+		var data map[string]interface{}
+		if err := roundTrip(s.versions[s.currentVersion].data, &data); err != nil {
+			return nil, err
+		}
+		return m.writeVersion(path, mergePatch(data, patch).(map[string]interface{}), r.data["options"], now)
+	case "data DELETE":
+		if s != nil {
+			if v := s.versions[s.currentVersion]; v != nil && v.live(now) {
+				v.deletionTime = now
+			}
+		}
+		return nil, nil
+	case "delete PUT", "undelete PUT", "destroy PUT":
+		versions, err := intList(r.data["versions"])
+		if err != nil {
+			return nil, err
+		}
+		if len(versions) == 0 {
+			return nil, badRequest("no version number provided")
+		}
+		if s == nil {
+			return nil, nil
+		}
+		for _, n := range versions {
+			v := s.versions[n]
+			if v == nil || v.destroyed {
+				continue
+			}
+			switch endpoint {
+			case "delete":
+				if v.live(now) {
+					v.deletionTime = now
+				}
+			case "undelete":
+				v.deletionTime = time.Time{}
+			case "destroy":
+				v.destroyed = true
+				v.data = nil
+			}
+		}
+		return nil, nil
+	case "metadata GET":
+		if s == nil {
+			return nil, nil
+		}
+		return s.metadata(), nil
+	case "metadata LIST":
+		paths := make([]string, 0, len(m.secrets))
+		for p := range m.secrets {
+			paths = append(paths, p)
+		}
+		return listKeys(paths, path), nil
+	case "metadata PUT":
+		if s == nil {
+			s = newSecret(now)
+			m.secrets[path] = s
+		}
+		if _, err := handleConfig(&s.config, r); err != nil {
+			return nil, err
+		}
+		if md, ok := r.data["custom_metadata"]; ok {
+			cm, ok := md.(map[string]interface{})
+			if md != nil && !ok {
+				return nil, badRequest("custom_metadata must be a map")
+			}
+			s.customMetadata = cm
+		}
+		s.updatedTime = now
+		return nil, nil
+	case "metadata DELETE":
+		delete(m.secrets, path)
+		return nil, nil
+	default:
+		return nil, unsupportedOperation()
+	}
+}
+
+// handleConfig reads or updates the configuration of a KVv2 mount or secret.
+func handleConfig(cfg *secretConfig, r request) (map[string]interface{}, error) {
+	switch r.method {
+	case http.MethodGet:
+		return map[string]interface{}{
+			"max_versions":         cfg.maxVersions,
+			"cas_required":         cfg.casRequired,
+			"delete_version_after": cfg.deleteVersionAfter.String(),
+		}, nil
+	case http.MethodPut:
+		if v, ok := r.data["max_versions"]; ok {
+			n, err := toInt(v)
+			if err != nil {
+				return nil, badRequest("max_versions: " + err.Error())
+			}
+			cfg.maxVersions = n
+		}
+		if v, ok := r.data["cas_required"]; ok {
+			b, ok := v.(bool)
+			if !ok {
+				return nil, badRequest("cas_required must be a bool")
+			}
+			cfg.casRequired = b
+		}
+		if v, ok := r.data["delete_version_after"]; ok {
+			d, err := toDuration(v)
+			if err != nil {
+				return nil, badRequest("delete_version_after: " + err.Error())
+			}
+			cfg.deleteVersionAfter = d
+		}
+		return nil, nil
+	default:
+		return nil, unsupportedOperation()
+	}
+}
+
+func newSecret(now time.Time) *secret {
+	return &secret{
+		createdTime: now,
+		updatedTime: now,
+		versions:    make(map[int]*version),
+	}
+}
+
+// writeVersion writes the data as a new version of the secret at path,
+// checking the check-and-set version in the write options.
+func (m *mount) writeVersion(path string, data map[string]interface{}, options interface{}, now time.Time) (map[string]interface{}, error) {
+	s := m.secrets[path]
+	current := 0
+	if s != nil {
+		current = s.currentVersion
+	}
+	opts, _ := options.(map[string]interface{})
+	if v, ok := opts["cas"]; ok {
+		cas, err := toInt(v)
+		if err != nil {
+			return nil, badRequest("cas: " + err.Error())
+		}
+		if cas != current {
+			return nil, badRequest("check-and-set parameter did not match the current version")
+		}
+	} else if m.config.casRequired || (s != nil && s.config.casRequired) {
+		return nil, badRequest("check-and-set parameter required for this call")
+	}
+	if s == nil {
+		s = newSecret(now)
+		m.secrets[path] = s
+	}
+
+	n := s.currentVersion + 1
+	v := &version{data: data, createdTime: now}
+	if d := m.effective(s).deleteVersionAfter; d > 0 {
+		v.deletionTime = now.Add(d)
+	}
+	s.versions[n] = v
+	s.currentVersion = n
+	s.updatedTime = now
+	if s.oldestVersion == 0 {
+		s.oldestVersion = n
+	}
+	for limit := m.effective(s).maxVersions; len(s.versions) > limit; s.oldestVersion++ {
+		delete(s.versions, s.oldestVersion)
+	}
+	for s.versions[s.oldestVersion] == nil {
+		s.oldestVersion++
+	}
+	return s.versionMetadata(n), nil
+}
+
+// effective returns the configuration of the secret, with the unset settings
+// taken from the mount configuration.
+func (m *mount) effective(s *secret) secretConfig {
+	cfg := s.config
+	if cfg.maxVersions == 0 {
+		cfg.maxVersions = m.config.maxVersions
+	}
+	if cfg.maxVersions == 0 {
+		cfg.maxVersions = defaultMaxVersions
+	}
+	if cfg.deleteVersionAfter == 0 {
+		cfg.deleteVersionAfter = m.config.deleteVersionAfter
+	}
+	return cfg
+}
+
+// readVersion returns the response of reading the version n of the secret, or
+// the current version if n is zero. The field of a live version is returned by
+// fn; a deleted or destroyed version has a nil field and only its metadata is
+// returned, like Vault does alongside a 404 status.
+func readVersion(s *secret, n int, now time.Time, fn func(v *version) (string, interface{})) map[string]interface{} {
+	if s == nil {
+		return nil
+	}
+	if n == 0 {
+		n = s.currentVersion
+	}
+	v := s.versions[n]
+	if v == nil {
+		return nil
+	}
+	key, value := fn(v)
+	if !v.live(now) {
+		value = nil
+	}
+	return map[string]interface{}{key: value, "metadata": s.versionMetadata(n)}
+}
+
+func (s *secret) versionMetadata(n int) map[string]interface{} {
+	v := s.versions[n]
+	return map[string]interface{}{
+		"created_time":    formatTime(v.createdTime),
+		"deletion_time":   formatTime(v.deletionTime),
+		"destroyed":       v.destroyed,
+		"version":         n,
+		"custom_metadata": s.customMetadata,
+	}
+}
+
+func (s *secret) metadata() map[string]interface{} {
+	versions := make(map[string]interface{}, len(s.versions))
+	for n, v := range s.versions {
+		versions[strconv.Itoa(n)] = map[string]interface{}{
+			"created_time":  formatTime(v.createdTime),
+			"deletion_time": formatTime(v.deletionTime),
+			"destroyed":     v.destroyed,
+		}
+	}
+	return map[string]interface{}{
+		"created_time":         formatTime(s.createdTime),
+		"updated_time":         formatTime(s.updatedTime),
+		"current_version":      s.currentVersion,
+		"oldest_version":       s.oldestVersion,
+		"max_versions":         s.config.maxVersions,
+		"cas_required":         s.config.casRequired,
+		"delete_version_after": s.config.deleteVersionAfter.String(),
+		"custom_metadata":      s.customMetadata,
+		"versions":             versions,
+	}
+}
+
+// subkeys returns the structure of the data with the values that are not
+// maps replaced by nil, up to the depth if it is positive.
+func subkeys(data map[string]interface{}, depth int) map[string]interface{} {
+	keys := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if m, ok := v.(map[string]interface{}); ok && depth != 1 {
+			keys[k] = subkeys(m, depth-1)
+		} else {
+			keys[k] = nil
+		}
+	}
+	return keys
+}
+
+// mergePatch applies the JSON merge patch to the target.
+//
+// See https://datatracker.ietf.org/doc/html/rfc7386.
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{})
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+	return t
+}
+
+// listKeys returns the response of listing the keys under the prefix: the
+// paths directly under it, and the directories under it with a trailing
+// slash.
+func listKeys(paths []string, prefix string) map[string]interface{} {
+	if prefix != "" {
+		prefix = strings.TrimSuffix(prefix, "/") + "/"
+	}
+	seen := make(map[string]bool)
+	var keys []interface{}
+	for _, p := range paths {
+		if !strings.HasPrefix(p, prefix) {
+			continue
+		}
+		key := p[len(prefix):]
+		if i := strings.Index(key, "/"); i >= 0 {
+			key = key[:i+1]
+		}
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].(string) < keys[j].(string)
+	})
+	return map[string]interface{}{"keys": keys}
+}
+
+// cutPrefix returns the rest of the path after the prefix, if the path is the
+// prefix or under it.
+func cutPrefix(path, prefix string) (string, bool) {
+	if path == prefix {
+		return "", true
+	}
+	if strings.HasPrefix(path, prefix+"/") {
+		return path[len(prefix)+1:], true
+	}
+	return "", false
+}
+
+func intParam(params map[string][]string, key string) (int, error) {
+	v, ok := params[key]
+	if !ok || len(v) == 0 {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v[0])
+	if err != nil {
+		return 0, badRequest(fmt.Sprintf("invalid %s %q", key, v[0]))
+	}
+	return n, nil
+}
+
+func intList(v interface{}) ([]int, error) {
+	list, ok := v.([]interface{})
+	if v != nil && !ok {
+		return nil, badRequest("versions must be a list")
+	}
+	ints := make([]int, 0, len(list))
+	for _, e := range list {
+		n, err := toInt(e)
+		if err != nil {
+			return nil, badRequest("versions: " + err.Error())
+		}
+		ints = append(ints, n)
+	}
+	return ints, nil
+}
+
+func toInt(v interface{}) (int, error) {
+	switch v := v.(type) {
+	case json.Number:
+		n, err := v.Int64()
+		return int(n), err
+	case string:
+		return strconv.Atoi(v)
+	default:
+		return 0, fmt.Errorf("invalid integer %v", v)
+	}
+}
+
+// toDuration parses a duration string or a number of seconds.
+func toDuration(v interface{}) (time.Duration, error) {
+	switch v := v.(type) {
+	case json.Number:
+		n, err := v.Int64()
+		return time.Duration(n) * time.Second, err
+	case string:
+		if n, err := strconv.Atoi(v); err == nil {
+			return time.Duration(n) * time.Second, nil
+		}
+		return time.ParseDuration(v)
+	default:
+		return 0, fmt.Errorf("invalid duration %v", v)
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// roundTrip copies in to out through JSON, decoding numbers as json.Number
+// like Vault and the Vault API client do.
+func roundTrip(in, out interface{}) error {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return dec.Decode(out)
+}
+
+func responseError(method, path string, status int, msg string) *api.ResponseError {
+	return &api.ResponseError{
+		HTTPMethod: method,
+		URL:        "/v1/" + path,
+		StatusCode: status,
+		Errors:     []string{msg},
+	}
+}
+
+func badRequest(msg string) *api.ResponseError {
+	return &api.ResponseError{StatusCode: http.StatusBadRequest, Errors: []string{msg}}
+}
+
+func unsupportedPath() *api.ResponseError {
+	return &api.ResponseError{StatusCode: http.StatusNotFound, Errors: []string{"unsupported path"}}
+}
+
+func unsupportedOperation() *api.ResponseError {
+	return &api.ResponseError{StatusCode: http.StatusMethodNotAllowed, Errors: []string{"unsupported operation"}}
+}
@@ -0,0 +1,202 @@
+package vaulttest_test
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/mwalto7/vault/secrets/cubbyhole"
+	"github.com/mwalto7/vault/secrets/kv"
+	kv1 "github.com/mwalto7/vault/secrets/kv/v1"
+	kv2 "github.com/mwalto7/vault/secrets/kv/v2"
+	"github.com/mwalto7/vault/vaulttest"
+)
+
+func TestInMemoryLogical_KVv2(t *testing.T) {
+	now := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	logical := vaulttest.NewInMemoryLogical(vaulttest.WithClock(func() time.Time { return now }))
+	c := kv2.NewClient("/secret", kv2.WithLogicalClient(logical))
+
+	for i, pw := range []string{"a", "b", "c"} {
+		v, err := c.WriteSecretLatest("app/db", map[string]interface{}{"password": pw, "port": 5432})
+		if err != nil {
+			t.Fatalf("WriteSecretLatest: got %v, want nil", err)
+		}
+		if v.Version != i+1 || !v.CreatedTime.Equal(now) {
+			t.Fatalf("WriteSecretLatest: got %+v, want version %d created at %v", v, i+1, now)
+		}
+	}
+	if _, err := c.WriteSecretVersion("app/db", 2, map[string]interface{}{"password": "d"}); err == nil {
+		t.Fatal("WriteSecretVersion with stale version: got nil, want error")
+	}
+	s, err := c.ReadSecretVersion("app/db", 2)
+	if err != nil {
+		t.Fatalf("ReadSecretVersion: got %v, want nil", err)
+	}
+	if s.Data["password"] != "b" || s.Metadata.Version != 2 {
+		t.Fatalf("ReadSecretVersion: got %+v, want password b at version 2", s)
+	}
+
+	if _, err := c.PatchSecret("app/db", map[string]interface{}{"port": nil, "user": "admin"}); err != nil {
+		t.Fatalf("PatchSecret: got %v, want nil", err)
+	}
+	s, err = c.ReadSecretLatest("app/db")
+	if err != nil {
+		t.Fatalf("ReadSecretLatest: got %v, want nil", err)
+	}
+	if want := map[string]interface{}{"password": "c", "user": "admin"}; !reflect.DeepEqual(s.Data, want) {
+		t.Fatalf("patched data: got %v, want %v", s.Data, want)
+	}
+
+	if err := c.DeleteSecretVersion("app/db", 1); err != nil {
+		t.Fatalf("DeleteSecretVersion: got %v, want nil", err)
+	}
+	if _, err := c.ReadSecretVersion("app/db", 1); !errors.Is(err, kv2.ErrSecretNotFound) {
+		t.Fatalf("read deleted version: got %v, want %v", err, kv2.ErrSecretNotFound)
+	}
+	if err := c.UndeleteSecretVersion("app/db", 1); err != nil {
+		t.Fatalf("UndeleteSecretVersion: got %v, want nil", err)
+	}
+	if _, err := c.ReadSecretVersion("app/db", 1); err != nil {
+		t.Fatalf("read undeleted version: got %v, want nil", err)
+	}
+	if err := c.DestroySecretVersion("app/db", 2); err != nil {
+		t.Fatalf("DestroySecretVersion: got %v, want nil", err)
+	}
+	if _, err := c.ReadSecretVersion("app/db", 2); !errors.Is(err, kv2.ErrVersionDestroyed) {
+		t.Fatalf("read destroyed version: got %v, want %v", err, kv2.ErrVersionDestroyed)
+	}
+
+	md, err := c.ReadSecretMetadata("app/db")
+	if err != nil {
+		t.Fatalf("ReadSecretMetadata: got %v, want nil", err)
+	}
+	if md.CurrentVersion != 4 || md.OldestVersion != 1 || len(md.Versions) != 4 || !md.Versions["2"].Destroyed {
+		t.Fatalf("ReadSecretMetadata: got %+v, want 4 versions with version 2 destroyed", md)
+	}
+
+	if _, err := c.WriteSecretLatest("app/cache", map[string]interface{}{"ttl": "1h"}); err != nil {
+		t.Fatalf("WriteSecretLatest: got %v, want nil", err)
+	}
+	if _, err := c.WriteSecretLatest("web", map[string]interface{}{"port": 80}); err != nil {
+		t.Fatalf("WriteSecretLatest: got %v, want nil", err)
+	}
+	var paths []string
+	if err := c.Walk("", func(path string) error {
+		paths = append(paths, path)
+		return nil
+	}); err != nil {
+		t.Fatalf("Walk: got %v, want nil", err)
+	}
+	sort.Strings(paths)
+	if want := []string{"app/cache", "app/db", "web"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("Walk: got %v, want %v", paths, want)
+	}
+
+	if err := c.DeleteSecretMetadata("app/db"); err != nil {
+		t.Fatalf("DeleteSecretMetadata: got %v, want nil", err)
+	}
+	if exists, err := c.ExistsSecret("app/db"); err != nil || exists {
+		t.Fatalf("ExistsSecret: got %t, %v, want false, nil", exists, err)
+	}
+}
+
+func TestInMemoryLogical_KVv2Config(t *testing.T) {
+	now := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	logical := vaulttest.NewInMemoryLogical(vaulttest.WithClock(func() time.Time { return now }))
+	c := kv2.NewClient("/secret", kv2.WithLogicalClient(logical))
+
+	if err := c.UpdateSecretMetadata("test", kv2.MetadataUpdate{
+		MaxVersions:        kv2.Int(2),
+		CASRequired:        kv2.Bool(true),
+		DeleteVersionAfter: kv2.Duration(time.Hour),
+	}); err != nil {
+		t.Fatalf("UpdateSecretMetadata: got %v, want nil", err)
+	}
+	if _, err := c.WriteSecretLatest("test", map[string]interface{}{"n": 1}); err == nil {
+		t.Fatal("write without CAS: got nil, want error")
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := c.WriteSecretVersion("test", i, map[string]interface{}{"n": i}); err != nil {
+			t.Fatalf("WriteSecretVersion: got %v, want nil", err)
+		}
+	}
+
+	md, err := c.ReadSecretMetadata("test")
+	if err != nil {
+		t.Fatalf("ReadSecretMetadata: got %v, want nil", err)
+	}
+	if md.OldestVersion != 2 || len(md.Versions) != 2 || !md.CASRequired || md.DeleteVersionAfter != time.Hour {
+		t.Fatalf("ReadSecretMetadata: got %+v, want versions 2 and 3 with the updated config", md)
+	}
+	if _, err := c.ReadSecretLatest("test"); err != nil {
+		t.Fatalf("read before deletion: got %v, want nil", err)
+	}
+	now = now.Add(time.Hour)
+	if _, err := c.ReadSecretLatest("test"); !errors.Is(err, kv2.ErrSecretNotFound) {
+		t.Fatalf("read after deletion: got %v, want %v", err, kv2.ErrSecretNotFound)
+	}
+}
+
+func TestInMemoryLogical_KVv1(t *testing.T) {
+	logical := vaulttest.NewInMemoryLogical(vaulttest.WithKVv1Mount("kv"))
+	c := kv1.NewClient("/kv", kv1.WithLogicalClient(logical))
+
+	if err := c.WriteSecret("app/db", map[string]interface{}{"password": "hunter2"}); err != nil {
+		t.Fatalf("WriteSecret: got %v, want nil", err)
+	}
+	data, err := c.ReadSecret("app/db")
+	if err != nil {
+		t.Fatalf("ReadSecret: got %v, want nil", err)
+	}
+	if want := map[string]interface{}{"password": "hunter2"}; !reflect.DeepEqual(data, want) {
+		t.Fatalf("ReadSecret: got %v, want %v", data, want)
+	}
+	if err := c.WriteSecret("app/web/tls", map[string]interface{}{"cert": "..."}); err != nil {
+		t.Fatalf("WriteSecret: got %v, want nil", err)
+	}
+	keys, err := c.ListSecrets("app")
+	if err != nil {
+		t.Fatalf("ListSecrets: got %v, want nil", err)
+	}
+	if want := []string{"db", "web/"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("ListSecrets: got %v, want %v", keys, want)
+	}
+	if err := c.DeleteSecret("app/db"); err != nil {
+		t.Fatalf("DeleteSecret: got %v, want nil", err)
+	}
+	if _, err := c.ReadSecret("app/db"); !errors.Is(err, kv1.ErrSecretNotFound) {
+		t.Fatalf("read deleted secret: got %v, want %v", err, kv1.ErrSecretNotFound)
+	}
+
+	if v, err := kv.DetectVersion("kv", logical); err != nil || v != 1 {
+		t.Fatalf("DetectVersion(kv): got %d, %v, want 1, nil", v, err)
+	}
+	if v, err := kv.DetectVersion("secret", logical); err != nil || v != 2 {
+		t.Fatalf("DetectVersion(secret): got %d, %v, want 2, nil", v, err)
+	}
+}
+
+func TestInMemoryLogical_Cubbyhole(t *testing.T) {
+	c := cubbyhole.NewClient("/cubbyhole", vaulttest.NewInMemoryLogical())
+
+	if err := c.WriteSecret("token", map[string]interface{}{"value": "s.abc"}); err != nil {
+		t.Fatalf("WriteSecret: got %v, want nil", err)
+	}
+	data, err := c.ReadSecret("token")
+	if err != nil {
+		t.Fatalf("ReadSecret: got %v, want nil", err)
+	}
+	if want := map[string]interface{}{"value": "s.abc"}; !reflect.DeepEqual(data, want) {
+		t.Fatalf("ReadSecret: got %v, want %v", data, want)
+	}
+}
+
+func TestInMemoryLogical_NoMount(t *testing.T) {
+	c := kv1.NewClient("/missing", kv1.WithLogicalClient(vaulttest.NewInMemoryLogical()))
+	if _, err := c.ReadSecret("test"); !kv1.IsNotFound(err) {
+		t.Fatalf("err: got %v, want not found", err)
+	}
+}
@@ -0,0 +1,83 @@
+package vault
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+const unwrapPath = "sys/wrapping/unwrap"
+
+// Unwrap returns the response-wrapped secret of the wrapping token. If the
+// wrapping token is empty, invalid or was already used, or the wrapped
+// response has no data, ErrSecretNotFound is returned.
+func Unwrap(client LogicalClient, wrappingToken string) (*api.Secret, error) {
+	if strings.TrimSpace(wrappingToken) == "" {
+		return nil, &os.PathError{Op: "Unwrap", Path: unwrapPath, Err: ErrSecretNotFound}
+	}
+	secret, err := client.Unwrap(wrappingToken)
+	if err != nil {
+		if isInvalidWrappingToken(err) {
+			err = ErrSecretNotFound
+		}
+		return nil, &os.PathError{Op: "Unwrap", Path: unwrapPath, Err: err}
+	}
+	if secret == nil || len(secret.Data) == 0 {
+		return nil, &os.PathError{Op: "Unwrap", Path: unwrapPath, Err: ErrSecretNotFound}
+	}
+	return secret, nil
+}
+
+// WrapInfo is the response-wrapping information of a wrapped Vault response.
+//
+// See https://www.vaultproject.io/docs/concepts/response-wrapping.
+type WrapInfo struct {
+	// Token is the single-use wrapping token to unwrap the response with.
+	Token string
+
+	// Accessor is the accessor of the wrapping token, used to look up or
+	// revoke it without using it.
+	Accessor string
+
+	// TTL is the time the wrapping token is valid for from its creation.
+	TTL time.Duration
+
+	// CreationTime is when the wrapping token was created.
+	CreationTime time.Time
+
+	// CreationPath is the API path of the request whose response was wrapped,
+	// which the recipient should check before trusting the unwrapped secret.
+	CreationPath string
+}
+
+// WrapInfoOf returns the response-wrapping information of the Vault response.
+// If secret is nil or was not wrapped, the zero WrapInfo is returned.
+func WrapInfoOf(secret *api.Secret) WrapInfo {
+	if secret == nil || secret.WrapInfo == nil {
+		return WrapInfo{}
+	}
+	return WrapInfo{
+		Token:        secret.WrapInfo.Token,
+		Accessor:     secret.WrapInfo.Accessor,
+		TTL:          time.Duration(secret.WrapInfo.TTL) * time.Second,
+		CreationTime: secret.WrapInfo.CreationTime,
+		CreationPath: secret.WrapInfo.CreationPath,
+	}
+}
+
+func isInvalidWrappingToken(err error) bool {
+	var respErr *api.ResponseError
+	if !errors.As(err, &respErr) || respErr.StatusCode != http.StatusBadRequest {
+		return false
+	}
+	for _, msg := range respErr.Errors {
+		if strings.Contains(msg, "wrapping token is not valid or does not exist") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package vault_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/mwalto7/vault"
+)
+
+func TestWrapInfoOf(t *testing.T) {
+	created := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	tt := []struct {
+		name   string
+		secret *api.Secret
+		want   vault.WrapInfo
+	}{
+		{name: "nil secret"},
+		{name: "not wrapped", secret: &api.Secret{Data: map[string]interface{}{"foo": "bar"}}},
+		{
+			name: "wrapped",
+			secret: &api.Secret{WrapInfo: &api.SecretWrapInfo{
+				Token:        "s.wrapped",
+				Accessor:     "acc",
+				TTL:          60,
+				CreationTime: created,
+				CreationPath: "secret/data/test",
+			}},
+			want: vault.WrapInfo{
+				Token:        "s.wrapped",
+				Accessor:     "acc",
+				TTL:          time.Minute,
+				CreationTime: created,
+				CreationPath: "secret/data/test",
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := vault.WrapInfoOf(tc.secret); got != tc.want {
+				t.Fatalf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}